@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -16,12 +16,16 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/devfile/registry-support/index/generator/library"
+	"github.com/devfile/registry-support/index/generator/schema"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
@@ -33,6 +37,32 @@ const (
 
 var cfgFile string
 var force bool
+var reportHTML string
+var reportJSON string
+var baseIndex string
+var indexFileYAML bool
+var incrementalCache string
+var skipSchemaValidate bool
+var skipMetadataValidate bool
+var skipDeepValidate bool
+var timingReport bool
+var telemetryEndpoint string
+var snapshotSamples bool
+var generateChangelogs bool
+var pushRegistry string
+var pushDryRun bool
+var skipSamples bool
+var samplesOnly bool
+var embedDevfiles bool
+var embedDevfilesMaxBytes int64
+var scaffoldVersion string
+var scaffoldDisplayName string
+var scaffoldDescription string
+var scaffoldProvider string
+var scaffoldSupportUrl string
+var scaffoldOwners []string
+var sampleTimeout time.Duration
+var totalSampleTimeout time.Duration
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -44,20 +74,224 @@ var rootCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		registryDirPath := args[0]
 		indexFilePath := args[1]
+		defer library.CleanupWorkspace()
+
+		var report *library.ValidationReport
+		if reportJSON != "" {
+			report = &library.ValidationReport{}
+		}
+
+		validationOpts := library.ValidationOptions{
+			SkipSchemaValidate:    force || skipSchemaValidate,
+			SkipMetadataValidate:  force || skipMetadataValidate,
+			SkipDeepValidate:      force || skipDeepValidate,
+			SkipSamples:           skipSamples,
+			SamplesOnly:           samplesOnly,
+			EmbedDevfiles:         embedDevfiles,
+			EmbedDevfilesMaxBytes: embedDevfilesMaxBytes,
+			Report:                report,
+		}
+
+		buildStart := time.Now()
+		var index []schema.Schema
+		var timings []library.StageTiming
+		var err error
+		if incrementalCache != "" {
+			index, timings, err = library.GenerateIndexStructIncremental(registryDirPath, indexFilePath, incrementalCache, validationOpts)
+		} else {
+			index, timings, err = library.GenerateIndexStructWithOptions(registryDirPath, validationOpts)
+		}
+
+		if report != nil {
+			if writeErr := report.WriteJSON(reportJSON); writeErr != nil {
+				fmt.Printf("Warning: failed to write validation report: %v\n", writeErr)
+			}
+		}
+
+		failureCount := 0
+		if err != nil {
+			failureCount = 1
+		}
+		if telemetryErr := library.ReportBuildEvent(library.TelemetryOptions{Endpoint: telemetryEndpoint}, index, time.Since(buildStart), failureCount); telemetryErr != nil {
+			fmt.Printf("Warning: failed to report build telemetry: %v\n", telemetryErr)
+		}
 
-		index, err := library.GenerateIndexStruct(registryDirPath, force)
 		if err != nil {
 			return fmt.Errorf("failed to generate index struct: %v", err)
 		}
 
-		err = library.CreateIndexFile(index, indexFilePath)
+		if timingReport {
+			for _, timing := range timings {
+				fmt.Printf("stage %s took %s\n", timing.Stage, timing.Duration)
+			}
+		}
+
+		if snapshotSamples {
+			summary, err := library.SnapshotSamplesWithOptions(registryDirPath, index, library.SnapshotOptions{
+				PerSampleTimeout: sampleTimeout,
+				TotalTimeout:     totalSampleTimeout,
+			})
+			if len(summary.TimedOut) > 0 {
+				fmt.Printf("Warning: %d sample(s) timed out and were skipped: %v\n", len(summary.TimedOut), summary.TimedOut)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to snapshot samples: %v", err)
+			}
+		}
+
+		if generateChangelogs {
+			if err := library.GenerateChangelogs(registryDirPath, index); err != nil {
+				return fmt.Errorf("failed to generate changelogs: %v", err)
+			}
+		}
+
+		if baseIndex != "" {
+			index, err = library.MergeWithBaseIndex(baseIndex, index)
+			if err != nil {
+				return fmt.Errorf("failed to merge with base index: %v", err)
+			}
+		}
+
+		if indexFileYAML {
+			err = library.CreateIndexFileYAML(index, indexFilePath)
+		} else {
+			err = library.CreateIndexFile(index, indexFilePath)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to create index file: %v", err)
 		}
+
+		if reportHTML != "" {
+			err = library.CreateHTMLCatalogReport(index, reportHTML)
+			if err != nil {
+				return fmt.Errorf("failed to create HTML catalog report: %v", err)
+			}
+		}
+
+		if pushRegistry != "" {
+			if err := library.PushIndexedArtifacts(registryDirPath, index, indexFilePath, library.PushOptions{
+				Registry: pushRegistry,
+				DryRun:   pushDryRun,
+			}); err != nil {
+				return fmt.Errorf("failed to push indexed artifacts: %v", err)
+			}
+		}
+		return nil
+	},
+}
+
+// newStackCmd scaffolds a new stack directory so a new contributor starts from something that
+// passes validation on the first try, instead of learning the required fields from a failed run.
+var newStackCmd = &cobra.Command{
+	Use:   "new-stack <registry directory path> <stack name>",
+	Short: "Scaffold a new stack directory with a starter devfile",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registryDirPath := args[0]
+		name := args[1]
+
+		if err := library.ScaffoldStack(registryDirPath, name, library.ScaffoldOptions{
+			Version:     scaffoldVersion,
+			DisplayName: scaffoldDisplayName,
+			Description: scaffoldDescription,
+			Provider:    scaffoldProvider,
+			SupportUrl:  scaffoldSupportUrl,
+			Owners:      scaffoldOwners,
+		}); err != nil {
+			return fmt.Errorf("failed to scaffold stack %s: %v", name, err)
+		}
+		return nil
+	},
+}
+
+// migrateCmd converts a registry still using the legacy flat layout (a single devfile.yaml per
+// stack, no stack.yaml) to the versioned stack.yaml layout, so downstream forks stuck on the old
+// layout don't have to hand-migrate every stack before this generator will index them.
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <registry directory path>",
+	Short: "Convert a legacy flat-layout registry to the versioned stack.yaml layout",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registryDirPath := args[0]
+
+		summary, err := library.MigrateLegacyRegistry(registryDirPath)
+		if err != nil {
+			return fmt.Errorf("failed to migrate registry: %v", err)
+		}
+
+		fmt.Printf("Migrated %d stack(s): %v\n", len(summary.Migrated), summary.Migrated)
+		if len(summary.Skipped) > 0 {
+			fmt.Printf("Skipped %d stack(s) already in the versioned layout (or missing a devfile.yaml): %v\n", len(summary.Skipped), summary.Skipped)
+		}
+		return nil
+	},
+}
+
+// diffJSON selects JSON output for diffCmd instead of the default human-readable summary.
+var diffJSON bool
+
+// diffCmd compares two generated index.json files, so a release pipeline can turn the result
+// into release notes or gate a rollout instead of diffing the files by hand.
+var diffCmd = &cobra.Command{
+	Use:   "diff <old index.json> <new index.json>",
+	Short: "Diff two index.json files' added/removed/changed stacks and versions",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldIndex, err := readIndexFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", args[0], err)
+		}
+		newIndex, err := readIndexFile(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", args[1], err)
+		}
+
+		diff := library.DiffIndex(oldIndex, newIndex)
+
+		if diffJSON {
+			out, err := json.MarshalIndent(diff, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal diff: %v", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(diff.Added) > 0 {
+			fmt.Printf("Added: %v\n", diff.Added)
+		}
+		if len(diff.Removed) > 0 {
+			fmt.Printf("Removed: %v\n", diff.Removed)
+		}
+		for _, stackDiff := range diff.Changed {
+			fmt.Printf("Changed %s:", stackDiff.Name)
+			if len(stackDiff.AddedVersions) > 0 {
+				fmt.Printf(" added versions %v", stackDiff.AddedVersions)
+			}
+			if len(stackDiff.RemovedVersions) > 0 {
+				fmt.Printf(" removed versions %v", stackDiff.RemovedVersions)
+			}
+			if stackDiff.ChangedDefaultVersion != "" {
+				fmt.Printf(" default version is now %s", stackDiff.ChangedDefaultVersion)
+			}
+			fmt.Println()
+		}
 		return nil
 	},
 }
 
+func readIndexFile(indexFilePath string) ([]schema.Schema, error) {
+	data, err := ioutil.ReadFile(indexFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var index []schema.Schema
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -75,10 +309,43 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.generator.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&force, "force", "f", false, "force to generate index file, ignore validation errors")
+	rootCmd.PersistentFlags().StringVar(&reportHTML, "report-html", "", "if set, render a static, self-contained HTML catalog report of the generated index to this path")
+	rootCmd.PersistentFlags().StringVar(&reportJSON, "report-json", "", "if set, write a machine-readable JSON validation report (per stack/version issue codes, severities, and file paths) to this path")
+	rootCmd.PersistentFlags().StringVar(&baseIndex, "base-index", "", "if set, overlay the generated index onto this existing index.json, overriding base entries by name, instead of generating a standalone index")
+	rootCmd.PersistentFlags().BoolVar(&indexFileYAML, "yaml", false, "write the index file as YAML instead of JSON, for downstream tooling that consumes YAML catalogs directly")
+	rootCmd.PersistentFlags().StringVar(&incrementalCache, "incremental-cache", "", "if set, enable incremental generation: a stack unchanged since the last run (by content hash, tracked in this file) reuses its entry from the existing index file instead of being reparsed and revalidated")
+	rootCmd.PersistentFlags().BoolVar(&skipSchemaValidate, "skip-schema-validate", false, "skip devfile schema validation, for faster local iteration")
+	rootCmd.PersistentFlags().BoolVar(&skipMetadataValidate, "skip-metadata-validate", false, "skip devfile metadata validation, for faster local iteration")
+	rootCmd.PersistentFlags().BoolVar(&skipDeepValidate, "skip-deep-validate", false, "skip registry-specific index validation (links, resources, default version), for faster local iteration")
+	rootCmd.PersistentFlags().BoolVar(&timingReport, "timing-report", false, "print how long each validation stage took")
+	rootCmd.PersistentFlags().StringVar(&telemetryEndpoint, "telemetry-endpoint", "", "if set, POST a build summary (duration, stack/sample counts, failures) to this endpoint; disabled by default")
+	rootCmd.PersistentFlags().BoolVar(&snapshotSamples, "snapshot-samples", false, "clone each sample at its declared git revision and package it into a digest-recorded snapshot archive, instead of leaving samples as live GitHub dependencies")
+	rootCmd.PersistentFlags().DurationVar(&sampleTimeout, "sample-timeout", 0, "with --snapshot-samples, abandon a single sample's git clone and checkout after this long and report it as timed out; 0 means no limit")
+	rootCmd.PersistentFlags().DurationVar(&totalSampleTimeout, "total-sample-timeout", 0, "with --snapshot-samples, abandon any samples not yet started once this much total time has been spent; 0 means no limit")
+	rootCmd.PersistentFlags().BoolVar(&generateChangelogs, "generate-changelogs", false, "when registryDirPath is a git checkout, write a per-stack version CHANGELOG.md of the commits touching it since the last tag, and record it on the index entry")
+	rootCmd.PersistentFlags().StringVar(&pushRegistry, "push-registry", "", "if set, push each stack's packaged artifacts and the generated index to this OCI registry after generation")
+	rootCmd.PersistentFlags().BoolVar(&pushDryRun, "dry-run", false, "with --push-registry, print the refs and digests that would be pushed instead of actually pushing them")
+	rootCmd.PersistentFlags().BoolVar(&skipSamples, "skip-samples", false, "exclude sample devfile entries from the generated index; mutually exclusive with --samples-only")
+	rootCmd.PersistentFlags().BoolVar(&samplesOnly, "samples-only", false, "exclude stack devfile entries from the generated index, keeping only samples; mutually exclusive with --skip-samples")
+	rootCmd.PersistentFlags().BoolVar(&embedDevfiles, "embed-devfiles", false, "include each version's devfile.yaml content inline in the index, for clients that want a single-request catalog")
+	rootCmd.PersistentFlags().Int64Var(&embedDevfilesMaxBytes, "embed-devfiles-max-bytes", 0, "with --embed-devfiles, skip embedding a devfile larger than this size in bytes (default 32KiB)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	newStackCmd.Flags().StringVar(&scaffoldVersion, "version", "", "version of the scaffolded stack (default \"1.0.0\")")
+	newStackCmd.Flags().StringVar(&scaffoldDisplayName, "display-name", "", "display name of the scaffolded stack (default: the stack name)")
+	newStackCmd.Flags().StringVar(&scaffoldDescription, "description", "", "description of the scaffolded stack")
+	newStackCmd.Flags().StringVar(&scaffoldProvider, "provider", "", "provider field of the scaffolded stack")
+	newStackCmd.Flags().StringVar(&scaffoldSupportUrl, "support-url", "", "supportUrl field of the scaffolded stack")
+	newStackCmd.Flags().StringArrayVar(&scaffoldOwners, "owner", []string{}, "GitHub username to add as an approver in OWNERS; repeatable")
+
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "print the diff as JSON instead of a human-readable summary")
+
+	rootCmd.AddCommand(newStackCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(diffCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,121 @@
+package gitutil
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGitAuthEnvWithTokenSetsAskpassVars(t *testing.T) {
+	env, cleanup, err := gitAuthEnv(GitAuth{Token: "abc123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if !envContains(env, "GIT_ASKPASS_USERNAME=x-access-token") {
+		t.Errorf("expected default x-access-token username, got %v", env)
+	}
+	if !envContains(env, "GIT_ASKPASS_PASSWORD=abc123") {
+		t.Errorf("expected token to be passed as the askpass password, got %v", env)
+	}
+	askpass := envValue(env, "GIT_ASKPASS")
+	if askpass == "" {
+		t.Fatal("expected GIT_ASKPASS to be set")
+	}
+	if _, err := os.Stat(askpass); err != nil {
+		t.Errorf("expected the askpass script to exist at %q: %v", askpass, err)
+	}
+	for _, v := range env {
+		if strings.HasPrefix(v, "GIT_ASKPASS_PASSWORD=") || strings.HasPrefix(v, "GIT_ASKPASS_USERNAME=") {
+			continue
+		}
+		if strings.Contains(v, "abc123") {
+			t.Errorf("token leaked into an unexpected environment variable: %q", v)
+		}
+	}
+
+	cleanup()
+	if _, err := os.Stat(askpass); !os.IsNotExist(err) {
+		t.Errorf("expected the askpass script to be removed after cleanup, stat err: %v", err)
+	}
+}
+
+func TestGitAuthEnvWithUsernameAndPassword(t *testing.T) {
+	env, cleanup, err := gitAuthEnv(GitAuth{Username: "bot", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if !envContains(env, "GIT_ASKPASS_USERNAME=bot") {
+		t.Errorf("expected explicit username to be kept, got %v", env)
+	}
+	if !envContains(env, "GIT_ASKPASS_PASSWORD=hunter2") {
+		t.Errorf("expected password to be passed as the askpass password, got %v", env)
+	}
+}
+
+func TestGitAuthEnvNoCredentialsSkipsAskpass(t *testing.T) {
+	env, cleanup, err := gitAuthEnv(GitAuth{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if envValue(env, "GIT_ASKPASS") != "" {
+		t.Errorf("expected no GIT_ASKPASS to be set without credentials, got %v", env)
+	}
+}
+
+func TestGitAuthEnvWithSSHKeySetsSSHCommand(t *testing.T) {
+	env, cleanup, err := gitAuthEnv(GitAuth{SSHKeyPath: "/home/user/.ssh/id_ed25519"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if sshCmd := envValue(env, "GIT_SSH_COMMAND"); !strings.Contains(sshCmd, "/home/user/.ssh/id_ed25519") {
+		t.Errorf("expected GIT_SSH_COMMAND to reference the key path, got %q", sshCmd)
+	}
+}
+
+func envValue(env []string, key string) string {
+	for _, v := range env {
+		if strings.HasPrefix(v, key+"=") {
+			return strings.TrimPrefix(v, key+"=")
+		}
+	}
+	return ""
+}
+
+func envContains(env []string, keyValue string) bool {
+	for _, v := range env {
+		if v == keyValue {
+			return true
+		}
+	}
+	return false
+}
+
+func TestResolveAuthPrefersHostSpecificEnvVar(t *testing.T) {
+	os.Setenv("GIT_TOKEN", "global-token")
+	os.Setenv("GIT_TOKEN_GITHUB_COM", "host-token")
+	defer os.Unsetenv("GIT_TOKEN")
+	defer os.Unsetenv("GIT_TOKEN_GITHUB_COM")
+
+	auth := ResolveAuth("https://github.com/example/stacks.git")
+	if auth.Token != "host-token" {
+		t.Errorf("expected host-specific token to win, got %q", auth.Token)
+	}
+}
+
+func TestResolveAuthFallsBackToGlobalEnvVar(t *testing.T) {
+	os.Setenv("GIT_TOKEN", "global-token")
+	defer os.Unsetenv("GIT_TOKEN")
+
+	auth := ResolveAuth("https://gitlab.com/example/stacks.git")
+	if auth.Token != "global-token" {
+		t.Errorf("expected global token, got %q", auth.Token)
+	}
+}
@@ -0,0 +1,146 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// initGitRepoWithDevfile turns dir, which must already exist, into a git repo with a single
+// commit containing a devfile.yaml, mirroring library.initGitRepoWithDevfile but local to this
+// package (and returning its error instead of taking a *testing.T) so it's safe to call from the
+// background goroutine TestCloneRevisionWithRetrySucceedsAfterTransientFailure uses to simulate
+// the remote recovering.
+func initGitRepoWithDevfile(dir string) error {
+	runGit := func(args ...string) error {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %v failed: %v: %s", args, err, output)
+		}
+		return nil
+	}
+	if err := runGit("init", "--quiet"); err != nil {
+		return err
+	}
+	if err := runGit("config", "user.email", "test@example.com"); err != nil {
+		return err
+	}
+	if err := runGit("config", "user.name", "test"); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644); err != nil {
+		return err
+	}
+	if err := runGit("add", "."); err != nil {
+		return err
+	}
+	return runGit("commit", "--quiet", "-m", "initial")
+}
+
+func TestCloneRevisionWithRetryFailsWithExhaustedRetriesError(t *testing.T) {
+	destDir, err := ioutil.TempDir("", "retry-succeed-dest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+
+	// A bogus remote never succeeds; this only exercises that retrying happens and that the
+	// final error is wrapped once attempts are exhausted, without needing a real git server.
+	err = CloneRevisionWithRetry(context.Background(), "https://invalid.example.invalid/repo.git", "", destDir, GitAuth{}, RetryOptions{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	})
+
+	var retriesExhausted *RetriesExhaustedError
+	if !errors.As(err, &retriesExhausted) {
+		t.Fatalf("expected *RetriesExhaustedError, got %v (%T)", err, err)
+	}
+	if retriesExhausted.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", retriesExhausted.Attempts)
+	}
+}
+
+func TestCloneRevisionWithRetrySucceedsAfterTransientFailure(t *testing.T) {
+	remoteDir, err := ioutil.TempDir("", "retry-succeed-remote")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(remoteDir)
+
+	destDir, err := ioutil.TempDir("", "retry-succeed-dest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+	// The first clone attempt needs destDir gone, same as a real caller handing
+	// CloneRevisionWithRetry a not-yet-created destination.
+	if err := os.RemoveAll(destDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// remoteDir starts out as an empty, non-git directory, so the first attempt fails exactly
+	// like a remote that's transiently unreachable. It becomes a real, clonable git repo shortly
+	// after, well within the second attempt's backoff delay, so the retry actually recovers.
+	initErrCh := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		initErrCh <- initGitRepoWithDevfile(remoteDir)
+	}()
+
+	err = CloneRevisionWithRetry(context.Background(), remoteDir, "", destDir, GitAuth{}, RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("expected the retry to recover once the remote became clonable, got %v", err)
+	}
+	if err := <-initErrCh; err != nil {
+		t.Fatalf("unexpected error setting up the remote: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "devfile.yaml")); err != nil {
+		t.Errorf("expected devfile.yaml to be cloned into destDir: %v", err)
+	}
+}
+
+func TestCloneRevisionWithRetryRespectsContextCancellation(t *testing.T) {
+	destDir, err := ioutil.TempDir("", "retry-cancel-dest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), -1*time.Second)
+	defer cancel()
+
+	err = CloneRevisionWithRetry(ctx, "https://invalid.example.invalid/repo.git", "", destDir, GitAuth{}, RetryOptions{MaxAttempts: 5})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestCloneRevisionWithRetryZeroValueBehavesLikeSingleAttempt(t *testing.T) {
+	destDir, err := ioutil.TempDir("", "retry-zero-dest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+
+	err = CloneRevisionWithRetry(context.Background(), "https://invalid.example.invalid/repo.git", "", destDir, GitAuth{}, RetryOptions{})
+
+	var retriesExhausted *RetriesExhaustedError
+	if !errors.As(err, &retriesExhausted) {
+		t.Fatalf("expected *RetriesExhaustedError, got %v (%T)", err, err)
+	}
+	if retriesExhausted.Attempts != 1 {
+		t.Errorf("expected 1 attempt for zero-valued RetryOptions, got %d", retriesExhausted.Attempts)
+	}
+}
@@ -0,0 +1,99 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// initTestGitRepo creates a throwaway git repository under dir, configuring a commit identity so
+// commits succeed in a CI environment with no global git config.
+func initTestGitRepo(t *testing.T, dir string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		assert.NoError(t, cmd.Run())
+	}
+}
+
+func commitTestGitRepo(t *testing.T, dir string, message string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"commit", "--quiet", "-m", message},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		assert.NoError(t, cmd.Run())
+	}
+}
+
+func TestGenerateChangelogs(t *testing.T) {
+	registryDir, err := ioutil.TempDir("", "changelog-registry")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDir)
+
+	initTestGitRepo(t, registryDir)
+
+	versionDir := filepath.Join(registryDir, "stacks", "nodejs", "1.0.0")
+	assert.NoError(t, os.MkdirAll(versionDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(versionDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+	commitTestGitRepo(t, registryDir, "add nodejs stack")
+
+	index := []schema.Schema{
+		{
+			Name: "nodejs",
+			Type: schema.StackDevfileType,
+			Versions: []schema.Version{
+				{
+					Version: "1.0.0",
+					Links:   map[string]string{"self": "devfile-catalog/nodejs:1.0.0"},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, GenerateChangelogs(registryDir, index))
+
+	version := index[0].Versions[0]
+	assert.Contains(t, version.Resources, changelogFileName)
+	assert.Equal(t, "devfile-catalog/nodejs:1.0.0#CHANGELOG.md", version.ChangelogUrl)
+
+	changelogBytes, err := ioutil.ReadFile(filepath.Join(versionDir, changelogFileName))
+	assert.NoError(t, err)
+	assert.Contains(t, string(changelogBytes), "add nodejs stack")
+}
+
+func TestGenerateChangelogsNotAGitCheckout(t *testing.T) {
+	registryDir, err := ioutil.TempDir("", "changelog-registry-no-git")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDir)
+
+	versionDir := filepath.Join(registryDir, "stacks", "nodejs", "1.0.0")
+	assert.NoError(t, os.MkdirAll(versionDir, 0755))
+
+	index := []schema.Schema{
+		{
+			Name: "nodejs",
+			Type: schema.StackDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.0.0"},
+			},
+		},
+	}
+
+	assert.NoError(t, GenerateChangelogs(registryDir, index))
+	assert.Empty(t, index[0].Versions[0].Resources)
+	assert.Empty(t, index[0].Versions[0].ChangelogUrl)
+}
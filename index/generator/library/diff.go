@@ -0,0 +1,103 @@
+package library
+
+import (
+	"sort"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// IndexDiff summarizes how a new index differs from an old one, stack by stack, so a build
+// pipeline can turn it into release notes or gate a rollout instead of diffing two index.json
+// files by hand.
+type IndexDiff struct {
+	// Added lists the names of stacks/samples present in the new index but not the old one.
+	Added []string `json:"added,omitempty"`
+	// Removed lists the names of stacks/samples present in the old index but not the new one.
+	Removed []string `json:"removed,omitempty"`
+	// Changed lists, for every name present in both indexes, the versions that were added or
+	// removed and whether the default version changed.
+	Changed []StackDiff `json:"changed,omitempty"`
+}
+
+// StackDiff summarizes how a single stack's or sample's versions changed between two indexes.
+type StackDiff struct {
+	Name            string   `json:"name"`
+	AddedVersions   []string `json:"addedVersions,omitempty"`
+	RemovedVersions []string `json:"removedVersions,omitempty"`
+	// ChangedDefaultVersion is the new default version, set only if it differs from the old one.
+	ChangedDefaultVersion string `json:"changedDefaultVersion,omitempty"`
+}
+
+// DiffIndex compares oldIndex against newIndex and reports every added/removed name and, for
+// names present in both, every added/removed version and default-version change.
+func DiffIndex(oldIndex []schema.Schema, newIndex []schema.Schema) IndexDiff {
+	oldByName := indexByName(oldIndex)
+	newByName := indexByName(newIndex)
+
+	var diff IndexDiff
+	for name := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	for name := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	for name, newEntry := range newByName {
+		oldEntry, ok := oldByName[name]
+		if !ok {
+			continue
+		}
+		if stackDiff, changed := diffStack(oldEntry, newEntry); changed {
+			diff.Changed = append(diff.Changed, stackDiff)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
+
+func indexByName(index []schema.Schema) map[string]schema.Schema {
+	byName := make(map[string]schema.Schema, len(index))
+	for _, entry := range index {
+		byName[entry.Name] = entry
+	}
+	return byName
+}
+
+func diffStack(old schema.Schema, new schema.Schema) (StackDiff, bool) {
+	oldVersions := versionSet(old.Versions)
+	newVersions := versionSet(new.Versions)
+
+	stackDiff := StackDiff{Name: new.Name}
+	for version := range newVersions {
+		if !oldVersions[version] {
+			stackDiff.AddedVersions = append(stackDiff.AddedVersions, version)
+		}
+	}
+	for version := range oldVersions {
+		if !newVersions[version] {
+			stackDiff.RemovedVersions = append(stackDiff.RemovedVersions, version)
+		}
+	}
+	if old.DefaultVersion != new.DefaultVersion {
+		stackDiff.ChangedDefaultVersion = new.DefaultVersion
+	}
+	sort.Strings(stackDiff.AddedVersions)
+	sort.Strings(stackDiff.RemovedVersions)
+
+	changed := len(stackDiff.AddedVersions) > 0 || len(stackDiff.RemovedVersions) > 0 || stackDiff.ChangedDefaultVersion != ""
+	return stackDiff, changed
+}
+
+func versionSet(versions []schema.Version) map[string]bool {
+	set := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		set[version.Version] = true
+	}
+	return set
+}
@@ -0,0 +1,54 @@
+package library
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestDiffIndex(t *testing.T) {
+	oldIndex := []schema.Schema{
+		{Name: "go", DefaultVersion: "1.1.0", Versions: []schema.Version{{Version: "1.1.0"}}},
+		{Name: "python", DefaultVersion: "1.0.0", Versions: []schema.Version{{Version: "1.0.0"}}},
+	}
+	newIndex := []schema.Schema{
+		{Name: "go", DefaultVersion: "1.2.0", Versions: []schema.Version{{Version: "1.1.0"}, {Version: "1.2.0"}}},
+		{Name: "nodejs", DefaultVersion: "1.0.0", Versions: []schema.Version{{Version: "1.0.0"}}},
+	}
+
+	diff := DiffIndex(oldIndex, newIndex)
+
+	if !reflect.DeepEqual(diff.Added, []string{"nodejs"}) {
+		t.Errorf("expected Added [nodejs], got %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"python"}) {
+		t.Errorf("expected Removed [python], got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("expected 1 changed entry, got %v", diff.Changed)
+	}
+	goDiff := diff.Changed[0]
+	if goDiff.Name != "go" {
+		t.Errorf("expected changed entry for go, got %s", goDiff.Name)
+	}
+	if !reflect.DeepEqual(goDiff.AddedVersions, []string{"1.2.0"}) {
+		t.Errorf("expected AddedVersions [1.2.0], got %v", goDiff.AddedVersions)
+	}
+	if len(goDiff.RemovedVersions) != 0 {
+		t.Errorf("expected no RemovedVersions, got %v", goDiff.RemovedVersions)
+	}
+	if goDiff.ChangedDefaultVersion != "1.2.0" {
+		t.Errorf("expected ChangedDefaultVersion 1.2.0, got %q", goDiff.ChangedDefaultVersion)
+	}
+}
+
+func TestDiffIndexNoChanges(t *testing.T) {
+	index := []schema.Schema{
+		{Name: "go", DefaultVersion: "1.1.0", Versions: []schema.Version{{Version: "1.1.0"}}},
+	}
+	diff := DiffIndex(index, index)
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Changed) != 0 {
+		t.Errorf("expected an empty diff for identical indexes, got %+v", diff)
+	}
+}
@@ -0,0 +1,25 @@
+package library
+
+import "github.com/devfile/registry-support/index/generator/schema"
+
+// Enricher lets an external Go plugin or registered callback add computed fields to an index
+// entry before it's written, e.g. an internal cost center or the result of a security scan that
+// only that caller's environment can produce. Enrich is invoked once per stack, after its
+// stack.yaml/devfile.yaml have parsed and validated successfully, with the stack's on-disk
+// directory so an enricher can read files this package doesn't itself track (SBOMs, scan reports,
+// ...) alongside the entry it should mutate. An enricher has no field of its own to write into on
+// schema.Schema; it's expected to record its output under entry.Attributes.
+type Enricher interface {
+	Enrich(stackDirPath string, entry *schema.Schema) error
+}
+
+// runEnrichers runs every enricher in order against entry, stopping at the first error so a
+// broken enricher can't leave the index partially, silently enriched.
+func runEnrichers(enrichers []Enricher, stackDirPath string, entry *schema.Schema) error {
+	for _, enricher := range enrichers {
+		if err := enricher.Enrich(stackDirPath, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
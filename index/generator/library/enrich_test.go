@@ -0,0 +1,60 @@
+package library
+
+import (
+	"errors"
+	"testing"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+type stubEnricher struct {
+	attrKey   string
+	attrValue string
+	err       error
+}
+
+func (e stubEnricher) Enrich(stackDirPath string, entry *schema.Schema) error {
+	if e.err != nil {
+		return e.err
+	}
+	if entry.Attributes == nil {
+		entry.Attributes = map[string]apiext.JSON{}
+	}
+	entry.Attributes[e.attrKey] = apiext.JSON{Raw: []byte(`"` + e.attrValue + `"`)}
+	return nil
+}
+
+func TestRunEnrichersAppliesEachInOrder(t *testing.T) {
+	entry := &schema.Schema{Name: "go"}
+	enrichers := []Enricher{
+		stubEnricher{attrKey: "costCenter", attrValue: "1234"},
+		stubEnricher{attrKey: "scanStatus", attrValue: "clean"},
+	}
+
+	if err := runEnrichers(enrichers, "/stacks/go", entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(entry.Attributes["costCenter"].Raw) != `"1234"` {
+		t.Errorf("expected costCenter attribute to be set, got %+v", entry.Attributes)
+	}
+	if string(entry.Attributes["scanStatus"].Raw) != `"clean"` {
+		t.Errorf("expected scanStatus attribute to be set, got %+v", entry.Attributes)
+	}
+}
+
+func TestRunEnrichersStopsOnError(t *testing.T) {
+	entry := &schema.Schema{Name: "go"}
+	enrichers := []Enricher{
+		stubEnricher{err: errors.New("scan service unavailable")},
+		stubEnricher{attrKey: "scanStatus", attrValue: "clean"},
+	}
+
+	if err := runEnrichers(enrichers, "/stacks/go", entry); err == nil {
+		t.Fatal("expected an error from the failing enricher")
+	}
+	if _, ok := entry.Attributes["scanStatus"]; ok {
+		t.Error("expected the enricher after the failing one not to have run")
+	}
+}
@@ -0,0 +1,75 @@
+package library
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestHashStackFolderChangesWithContent(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "devfile.yaml"), []byte("a: 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	first, err := HashStackFolder(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := HashStackFolder(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected a stable hash for unchanged content, got %s and %s", first, second)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "devfile.yaml"), []byte("a: 2\n"), 0644); err != nil {
+		t.Fatalf("failed to update fixture: %v", err)
+	}
+	third, err := HashStackFolder(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if third == first {
+		t.Error("expected the hash to change after the folder's content changed")
+	}
+}
+
+func TestIncrementalCacheSaveAndLoad(t *testing.T) {
+	cacheFilePath := filepath.Join(t.TempDir(), "cache.json")
+
+	loaded, err := LoadIncrementalCache(cacheFilePath)
+	if err != nil {
+		t.Fatalf("unexpected error loading a missing cache: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("expected an empty cache, got %v", loaded)
+	}
+
+	cache := IncrementalCache{"go": "deadbeef"}
+	if err := cache.Save(cacheFilePath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := LoadIncrementalCache(cacheFilePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloaded["go"] != "deadbeef" {
+		t.Errorf("expected reloaded cache to contain go=deadbeef, got %v", reloaded)
+	}
+}
+
+func TestIncrementalOptionsPreviousStack(t *testing.T) {
+	opts := &IncrementalOptions{PreviousIndex: []schema.Schema{{Name: "go"}, {Name: "python"}}}
+
+	if found := opts.previousStack("python"); found == nil || found.Name != "python" {
+		t.Errorf("expected to find python, got %v", found)
+	}
+	if found := opts.previousStack("nodejs"); found != nil {
+		t.Errorf("expected no match for nodejs, got %v", found)
+	}
+}
@@ -0,0 +1,105 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// MergeWithBaseIndex overlays index onto the index already recorded in baseIndexFilePath: an
+// entry in index replaces any base entry of the same Name, and every base entry with no
+// same-named override is kept as-is. This lets an extension registry generate only the stacks
+// and samples it adds or overrides, then combine them with an upstream community index in one
+// step instead of hand-merging two index.json files.
+func MergeWithBaseIndex(baseIndexFilePath string, index []schema.Schema) ([]schema.Schema, error) {
+	baseBytes, err := ioutil.ReadFile(baseIndexFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base index %s: %v", baseIndexFilePath, err)
+	}
+
+	var baseIndex []schema.Schema
+	if err := json.Unmarshal(baseBytes, &baseIndex); err != nil {
+		return nil, fmt.Errorf("failed to parse base index %s: %v", baseIndexFilePath, err)
+	}
+
+	overrides := make(map[string]bool, len(index))
+	for _, entry := range index {
+		overrides[entry.Name] = true
+	}
+
+	merged := make([]schema.Schema, 0, len(baseIndex)+len(index))
+	for _, entry := range baseIndex {
+		if !overrides[entry.Name] {
+			merged = append(merged, entry)
+		}
+	}
+	merged = append(merged, index...)
+
+	return merged, nil
+}
+
+// ConflictResolution selects how MergeIndexes settles two source indexes both defining a stack or
+// sample of the same name.
+type ConflictResolution string
+
+const (
+	// ConflictResolutionError fails MergeIndexes outright on the first name collision, for a
+	// federated catalog that wants every source to own a disjoint namespace.
+	ConflictResolutionError ConflictResolution = "error"
+	// ConflictResolutionPreferFirst keeps whichever source index listed the name first, so the
+	// order indexes are passed in doubles as a priority order.
+	ConflictResolutionPreferFirst ConflictResolution = "prefer-first"
+	// ConflictResolutionPreferNewestVersion keeps whichever source's entry has the newer
+	// LatestVersion, comparing with the same numeric-version rules as the rest of this package.
+	ConflictResolutionPreferNewestVersion ConflictResolution = "prefer-newest-version"
+)
+
+// MergeOptions configures MergeIndexes.
+type MergeOptions struct {
+	// OnConflict picks how a name defined by more than one source index is resolved. Defaults to
+	// ConflictResolutionError when left empty.
+	OnConflict ConflictResolution
+}
+
+// MergeIndexes combines indexes from multiple source registries into a single federated index,
+// resolving any name collision between sources according to opts.OnConflict.
+func MergeIndexes(indexes [][]schema.Schema, opts MergeOptions) ([]schema.Schema, error) {
+	onConflict := opts.OnConflict
+	if onConflict == "" {
+		onConflict = ConflictResolutionError
+	}
+
+	byName := make(map[string]schema.Schema)
+	order := make([]string, 0)
+	for _, index := range indexes {
+		for _, entry := range index {
+			existing, ok := byName[entry.Name]
+			if !ok {
+				byName[entry.Name] = entry
+				order = append(order, entry.Name)
+				continue
+			}
+
+			switch onConflict {
+			case ConflictResolutionError:
+				return nil, fmt.Errorf("name %q is defined by more than one source index", entry.Name)
+			case ConflictResolutionPreferFirst:
+				// existing was seen first; keep it.
+			case ConflictResolutionPreferNewestVersion:
+				if isVersionNewer(entry.LatestVersion, existing.LatestVersion) {
+					byName[entry.Name] = entry
+				}
+			default:
+				return nil, fmt.Errorf("unknown conflict resolution %q", onConflict)
+			}
+		}
+	}
+
+	merged := make([]schema.Schema, 0, len(order))
+	for _, name := range order {
+		merged = append(merged, byName[name])
+	}
+	return merged, nil
+}
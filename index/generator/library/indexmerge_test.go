@@ -0,0 +1,106 @@
+package library
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestMergeWithBaseIndex(t *testing.T) {
+	base := []schema.Schema{
+		{Name: "go", DisplayName: "Go"},
+		{Name: "python", DisplayName: "Python"},
+	}
+	baseBytes, err := json.Marshal(base)
+	if err != nil {
+		t.Fatalf("failed to marshal base index: %v", err)
+	}
+	baseIndexFilePath := filepath.Join(t.TempDir(), "index.json")
+	if err := ioutil.WriteFile(baseIndexFilePath, baseBytes, 0644); err != nil {
+		t.Fatalf("failed to write base index: %v", err)
+	}
+
+	generated := []schema.Schema{
+		{Name: "go", DisplayName: "Go (extended)"},
+		{Name: "nodejs", DisplayName: "NodeJS"},
+	}
+
+	merged, err := MergeWithBaseIndex(baseIndexFilePath, generated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byName := map[string]schema.Schema{}
+	for _, entry := range merged {
+		byName[entry.Name] = entry
+	}
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %v", len(merged), merged)
+	}
+	if byName["go"].DisplayName != "Go (extended)" {
+		t.Errorf("expected generated entry to override base entry for go, got %+v", byName["go"])
+	}
+	if byName["python"].DisplayName != "Python" {
+		t.Errorf("expected base-only entry python to be preserved, got %+v", byName["python"])
+	}
+	if byName["nodejs"].DisplayName != "NodeJS" {
+		t.Errorf("expected generated-only entry nodejs to be included, got %+v", byName["nodejs"])
+	}
+}
+
+func TestMergeWithBaseIndexMissingFile(t *testing.T) {
+	if _, err := MergeWithBaseIndex(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("expected an error for a missing base index file")
+	}
+}
+
+func TestMergeIndexesNoConflicts(t *testing.T) {
+	first := []schema.Schema{{Name: "go"}}
+	second := []schema.Schema{{Name: "python"}}
+
+	merged, err := MergeIndexes([][]schema.Schema{first, second}, MergeOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %v", len(merged), merged)
+	}
+}
+
+func TestMergeIndexesConflictError(t *testing.T) {
+	first := []schema.Schema{{Name: "go", LatestVersion: "1.0.0"}}
+	second := []schema.Schema{{Name: "go", LatestVersion: "2.0.0"}}
+
+	if _, err := MergeIndexes([][]schema.Schema{first, second}, MergeOptions{OnConflict: ConflictResolutionError}); err == nil {
+		t.Error("expected an error for a name defined by more than one source index")
+	}
+}
+
+func TestMergeIndexesConflictPreferFirst(t *testing.T) {
+	first := []schema.Schema{{Name: "go", DisplayName: "first"}}
+	second := []schema.Schema{{Name: "go", DisplayName: "second"}}
+
+	merged, err := MergeIndexes([][]schema.Schema{first, second}, MergeOptions{OnConflict: ConflictResolutionPreferFirst})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(merged) != 1 || merged[0].DisplayName != "first" {
+		t.Errorf("expected the first source's entry to win, got %+v", merged)
+	}
+}
+
+func TestMergeIndexesConflictPreferNewestVersion(t *testing.T) {
+	first := []schema.Schema{{Name: "go", LatestVersion: "1.0.0"}}
+	second := []schema.Schema{{Name: "go", LatestVersion: "2.0.0"}}
+
+	merged, err := MergeIndexes([][]schema.Schema{first, second}, MergeOptions{OnConflict: ConflictResolutionPreferNewestVersion})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(merged) != 1 || merged[0].LatestVersion != "2.0.0" {
+		t.Errorf("expected the newer-versioned entry to win, got %+v", merged)
+	}
+}
@@ -1,18 +1,22 @@
 package library
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	devfilepkg "github.com/devfile/api/v2/pkg/devfile"
+	devfileParser "github.com/devfile/library/pkg/devfile"
 	"github.com/devfile/library/pkg/devfile/parser"
 	v2 "github.com/devfile/library/pkg/devfile/parser/data/v2"
 	"github.com/devfile/registry-support/index/generator/schema"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 )
 
 func TestValidateIndexComponent(t *testing.T) {
@@ -30,6 +34,7 @@ func TestValidateIndexComponent(t *testing.T) {
 	noVersionErr := ".*no version specified.*"
 	schemaVersionEmptyErr := ".*schema version is empty.*"
 	multipleVersionErr := ".*has multiple default versions.*"
+	invalidArchErr := ".*has an invalid architecture.*"
 
 	tests := []struct {
 		name           string
@@ -56,7 +61,7 @@ func TestValidateIndexComponent(t *testing.T) {
 				Name: "nodejs",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
 						Resources: []string{
 							"devfile.yaml",
@@ -73,7 +78,7 @@ func TestValidateIndexComponent(t *testing.T) {
 				Name: "nodejs",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
 						Links: map[string]string{
 							"self": "devfile-catalog/java-maven:latest",
@@ -103,18 +108,18 @@ func TestValidateIndexComponent(t *testing.T) {
 				SupportUrl: "http://testurl/support.md",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
-						Default: true,
+						Default:       true,
 						Links: map[string]string{
-						"self": "devfile-catalog/java-maven:1.0.0",
+							"self": "devfile-catalog/java-maven:1.0.0",
 						},
 						Resources: []string{
 							"devfile.yaml",
 						},
 					},
 					{
-						Version: "1.1.0",
+						Version:       "1.1.0",
 						SchemaVersion: "2.1.0",
 						Links: map[string]string{
 							"self": "devfile-catalog/java-maven:2.1.0",
@@ -181,9 +186,9 @@ func TestValidateIndexComponent(t *testing.T) {
 				Name: "nodejs",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
-						Default: true,
+						Default:       true,
 						Links: map[string]string{
 							"self": "devfile-catalog/java-maven:latest",
 						},
@@ -206,9 +211,9 @@ func TestValidateIndexComponent(t *testing.T) {
 				Name: "nodejs",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
-						Default: true,
+						Default:       true,
 						Links: map[string]string{
 							"self": "devfile-catalog/java-maven:latest",
 						},
@@ -228,7 +233,7 @@ func TestValidateIndexComponent(t *testing.T) {
 		{
 			"Case 11: empty version list",
 			schema.Schema{
-				Name: "nodejs",
+				Name:     "nodejs",
 				Versions: []schema.Version{},
 			},
 			schema.StackDevfileType,
@@ -245,7 +250,7 @@ func TestValidateIndexComponent(t *testing.T) {
 				SupportUrl: "http://testurl/support.md",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
 						Links: map[string]string{
 							"self": "devfile-catalog/java-maven:latest",
@@ -308,9 +313,9 @@ func TestValidateIndexComponent(t *testing.T) {
 				SupportUrl: "http://testurl/support.md",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
-						Default: true,
+						Default:       true,
 						Links: map[string]string{
 							"self": "devfile-catalog/java-maven:1.0.0",
 						},
@@ -319,9 +324,9 @@ func TestValidateIndexComponent(t *testing.T) {
 						},
 					},
 					{
-						Version: "1.1.0",
+						Version:       "1.1.0",
 						SchemaVersion: "2.1.0",
-						Default: true,
+						Default:       true,
 						Links: map[string]string{
 							"self": "devfile-catalog/java-maven:1.1.0",
 						},
@@ -340,9 +345,9 @@ func TestValidateIndexComponent(t *testing.T) {
 				Name: "nodejs",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
-						Default: true,
+						Default:       true,
 						Git: &schema.Git{
 							Remotes: map[string]string{
 								"origin": "https://github.com/redhat-developer/devfile-sample/nodejs",
@@ -350,7 +355,7 @@ func TestValidateIndexComponent(t *testing.T) {
 						},
 					},
 					{
-						Version: "1.1.0",
+						Version:       "1.1.0",
 						SchemaVersion: "2.1.0",
 						Git: &schema.Git{
 							Remotes: map[string]string{
@@ -379,7 +384,7 @@ func TestValidateIndexComponent(t *testing.T) {
 				SupportUrl: "http://testurl/support.md",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
 						Git: &schema.Git{
 							Remotes: map[string]string{
@@ -439,9 +444,9 @@ func TestValidateIndexComponent(t *testing.T) {
 				SupportUrl: "http://testurl/support.md",
 				Versions: []schema.Version{
 					{
-						Version: "1.0.0",
+						Version:       "1.0.0",
 						SchemaVersion: "2.0.0",
-						Default: true,
+						Default:       true,
 						Git: &schema.Git{
 							Remotes: map[string]string{
 								"origin": "https://github.com/redhat-developer/devfile-sample/nodejs",
@@ -449,9 +454,9 @@ func TestValidateIndexComponent(t *testing.T) {
 						},
 					},
 					{
-						Version: "1.1.0",
+						Version:       "1.1.0",
 						SchemaVersion: "2.1.0",
-						Default: true,
+						Default:       true,
 						Git: &schema.Git{
 							Remotes: map[string]string{
 								"origin": "https://github.com/redhat-developer/devfile-sample/nodejs-2.1.0",
@@ -463,6 +468,66 @@ func TestValidateIndexComponent(t *testing.T) {
 			schema.SampleDevfileType,
 			&multipleVersionErr,
 		},
+		{
+			"Case 21: test sample component version with empty git",
+			schema.Schema{
+				Name: "nodejs",
+				Versions: []schema.Version{
+					{
+						Version:       "1.0.0",
+						SchemaVersion: "2.0.0",
+						Default:       true,
+					},
+				},
+			},
+			schema.SampleDevfileType,
+			&gitEmptyErr,
+		},
+		{
+			"Case 22: test sample component version git has multiple remotes",
+			schema.Schema{
+				Name: "nodejs",
+				Architectures: []string{
+					"amd64",
+				},
+				Provider:   "Red Hat",
+				SupportUrl: "http://testurl/support.md",
+				Versions: []schema.Version{
+					{
+						Version:       "1.0.0",
+						SchemaVersion: "2.0.0",
+						Default:       true,
+						Git: &schema.Git{
+							Remotes: map[string]string{
+								"origin": "https://github.com/redhat-developer/devfile-sample/nodejs",
+								"test":   "https://github.com/redhat-developer/test",
+							},
+						},
+					},
+				},
+			},
+			schema.SampleDevfileType,
+			&multipleRemotesErr,
+		},
+		{
+			"Case 23: check for invalid architecture",
+			schema.Schema{
+				Name: "nodejs",
+				Architectures: []string{
+					"amd64",
+					"x86",
+				},
+				Provider:   "Red Hat",
+				SupportUrl: "http://testurl/support.md",
+				Git: &schema.Git{
+					Remotes: map[string]string{
+						"origin": "https://github.com/redhat-developer/devfile-sample",
+					},
+				},
+			},
+			schema.SampleDevfileType,
+			&invalidArchErr,
+		},
 	}
 
 	for _, tt := range tests {
@@ -491,7 +556,7 @@ func TestParseDevfileRegistry(t *testing.T) {
 	}
 
 	t.Run("Test parse devfile registry", func(t *testing.T) {
-		gotIndex, err := parseDevfileRegistry(registryDirPath, false)
+		gotIndex, err := parseDevfileRegistry(context.Background(), registryDirPath, newStagePipeline(ValidationOptions{}))
 		if err != nil {
 			t.Errorf("Failed to call function parseDevfileRegistry: %v", err)
 		}
@@ -515,7 +580,7 @@ func TestParseExtraDevfileEntries(t *testing.T) {
 	}
 
 	t.Run("Test parse extra devfile entries", func(t *testing.T) {
-		gotIndex, err := parseExtraDevfileEntries(registryDirPath, false)
+		gotIndex, err := parseExtraDevfileEntries(registryDirPath, newStagePipeline(ValidationOptions{}))
 		if err != nil {
 			t.Errorf("Failed to call function parseExtraDevfileEntries: %v", err)
 		}
@@ -550,10 +615,10 @@ func TestGenerateIndexStruct(t *testing.T) {
 }
 
 func TestCheckForRequiredMetadata(t *testing.T) {
-	noNameError := fmt.Errorf("metadata.name is not set")
-	noDisplayNameError := fmt.Errorf("metadata.displayName is not set")
-	noLanguageError := fmt.Errorf("metadata.language is not set")
-	noProjectTypeError := fmt.Errorf("metadata.projectType is not set")
+	noNameError := &MetadataValidationError{field: "name", code: CodeMetadataNameNotSet}
+	noDisplayNameError := &MetadataValidationError{field: "displayName", code: CodeMetadataDisplayNameNotSet}
+	noLanguageError := &MetadataValidationError{field: "language", code: CodeMetadataLanguageNotSet}
+	noProjectTypeError := &MetadataValidationError{field: "projectType", code: CodeMetadataProjectTypeNotSet}
 
 	tests := []struct {
 		name       string
@@ -633,3 +698,476 @@ func TestCheckForRequiredMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestDanglingVersionDirs(t *testing.T) {
+	stackfolderDir, err := ioutil.TempDir("", "dangling-version-dirs")
+	assert.NoError(t, err)
+	defer os.RemoveAll(stackfolderDir)
+
+	for _, dir := range []string{"1.0.0", "2.0.0", "orphaned"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(stackfolderDir, dir), 0755))
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(stackfolderDir, "stack.yaml"), []byte(""), 0644))
+
+	stackInfo := schema.Schema{
+		Name: "test-stack",
+		Versions: []schema.Version{
+			{Version: "1.0.0"},
+			{Version: "2.0.0"},
+		},
+	}
+
+	dangling := danglingVersionDirs(stackInfo, stackfolderDir)
+	assert.Equal(t, []string{"orphaned"}, dangling)
+}
+
+func TestNormalizeYAMLInput(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{name: "plain LF input is unchanged", in: []byte("name: foo\n"), want: []byte("name: foo\n")},
+		{name: "UTF-8 BOM is stripped", in: append(append([]byte{}, utf8BOM...), []byte("name: foo\n")...), want: []byte("name: foo\n")},
+		{name: "CRLF is normalized to LF", in: []byte("name: foo\r\nversion: 1.0.0\r\n"), want: []byte("name: foo\nversion: 1.0.0\n")},
+		{name: "BOM and CRLF are both handled", in: append(append([]byte{}, utf8BOM...), []byte("name: foo\r\n")...), want: []byte("name: foo\n")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, normalizeYAMLInput(test.in, "stack.yaml"))
+		})
+	}
+}
+
+func TestParseStackInfoWithCRLFAndBOM(t *testing.T) {
+	stackfolderDir, err := ioutil.TempDir("", "stack-info-crlf")
+	assert.NoError(t, err)
+	defer os.RemoveAll(stackfolderDir)
+
+	stackYamlPath := filepath.Join(stackfolderDir, "stack.yaml")
+	content := append(append([]byte{}, utf8BOM...), []byte("name: test-stack\r\ndisplayName: Test Stack\r\n")...)
+	assert.NoError(t, ioutil.WriteFile(stackYamlPath, content, 0644))
+
+	index, err := parseStackInfo(stackYamlPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-stack", index.Name)
+	assert.Equal(t, "Test Stack", index.DisplayName)
+}
+
+func TestValidateStackInfoMaturity(t *testing.T) {
+	stackfolderDir, err := ioutil.TempDir("", "stack-info-maturity")
+	assert.NoError(t, err)
+	defer os.RemoveAll(stackfolderDir)
+	assert.NoError(t, os.Mkdir(filepath.Join(stackfolderDir, "1.0.0"), 0755))
+
+	baseStack := schema.Schema{
+		Name:        "test-stack",
+		DisplayName: "Test Stack",
+		Icon:        "icon.png",
+		Versions: []schema.Version{
+			{Version: "1.0.0", Default: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		maturity schema.Maturity
+		wantErr  bool
+	}{
+		{name: "unset maturity is valid", maturity: ""},
+		{name: "incubating is valid", maturity: schema.IncubatingMaturity},
+		{name: "stable is valid", maturity: schema.StableMaturity},
+		{name: "deprecated is valid", maturity: schema.DeprecatedMaturity},
+		{name: "unknown maturity is rejected", maturity: "experimental", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stackInfo := baseStack
+			stackInfo.Maturity = test.maturity
+			errs := validateStackInfo(stackInfo, stackfolderDir)
+			if test.wantErr {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestValidationErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  Coder
+		code ValidationCode
+	}{
+		{"MissingProviderError", &MissingProviderError{devfile: "nodejs"}, CodeMissingProvider},
+		{"MissingSupportUrlError", &MissingSupportUrlError{devfile: "nodejs"}, CodeMissingSupportUrl},
+		{"MissingArchError", &MissingArchError{devfile: "nodejs"}, CodeMissingArch},
+		{"InvalidArchError", &InvalidArchError{devfile: "nodejs"}, CodeInvalidArch},
+		{"MetadataValidationError", &MetadataValidationError{field: "name", code: CodeMetadataNameNotSet}, CodeMetadataNameNotSet},
+		{"DuplicateDisplayNameError", &DuplicateDisplayNameError{displayName: "Node.js", devfiles: []string{"nodejs", "nodejs-copy"}}, CodeDuplicateDisplayName},
+		{"DuplicateIconError", &DuplicateIconError{icon: "nodejs.svg", devfiles: []string{"nodejs", "nodejs-copy"}}, CodeDuplicateIcon},
+		{"CaseInsensitiveCollisionError", &CaseInsensitiveCollisionError{devfile: "nodejs", paths: []string{"Devfile.yaml", "devfile.yaml"}}, CodeCaseInsensitiveCollision},
+		{"WindowsInvalidPathError", &WindowsInvalidPathError{devfile: "nodejs", path: "con.yaml"}, CodeWindowsInvalidPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, tt.err.Code())
+			assert.NotEmpty(t, ValidationHint(tt.code))
+		})
+	}
+}
+
+func TestFilterByDevfileType(t *testing.T) {
+	index := []schema.Schema{
+		{Name: "nodejs", Type: schema.StackDevfileType},
+		{Name: "go-basic", Type: schema.SampleDevfileType},
+		{Name: "java-maven", Type: schema.StackDevfileType},
+	}
+
+	tests := []struct {
+		name string
+		opts ValidationOptions
+		want []string
+	}{
+		{
+			name: "No filtering",
+			opts: ValidationOptions{},
+			want: []string{"nodejs", "go-basic", "java-maven"},
+		},
+		{
+			name: "SkipSamples excludes samples",
+			opts: ValidationOptions{SkipSamples: true},
+			want: []string{"nodejs", "java-maven"},
+		},
+		{
+			name: "SamplesOnly excludes stacks",
+			opts: ValidationOptions{SamplesOnly: true},
+			want: []string{"go-basic"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterByDevfileType(index, tt.opts)
+			var gotNames []string
+			for _, indexComponent := range filtered {
+				gotNames = append(gotNames, indexComponent.Name)
+			}
+			assert.Equal(t, tt.want, gotNames)
+		})
+	}
+}
+
+func TestGenerateIndexStructWithOptionsRejectsConflictingSampleFilters(t *testing.T) {
+	_, _, err := GenerateIndexStructWithOptions("../tests/registry", ValidationOptions{SkipSamples: true, SamplesOnly: true})
+	assert.Error(t, err)
+}
+
+func TestCheckForDuplicateDisplayNamesAndIcons(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   []schema.Schema
+		wantErr int
+	}{
+		{
+			name: "No duplicates",
+			index: []schema.Schema{
+				{Name: "nodejs", DisplayName: "Node.js", Icon: "nodejs.svg"},
+				{Name: "go-basic", DisplayName: "Go", Icon: "go.svg"},
+			},
+		},
+		{
+			name: "Duplicate displayName",
+			index: []schema.Schema{
+				{Name: "nodejs", DisplayName: "Node.js", Icon: "nodejs.svg"},
+				{Name: "nodejs-copy", DisplayName: "Node.js", Icon: "nodejs-copy.svg"},
+			},
+			wantErr: 1,
+		},
+		{
+			name: "Duplicate icon",
+			index: []schema.Schema{
+				{Name: "nodejs", DisplayName: "Node.js", Icon: "nodejs.svg"},
+				{Name: "nodejs-copy", DisplayName: "Node.js Copy", Icon: "nodejs.svg"},
+			},
+			wantErr: 1,
+		},
+		{
+			name: "Duplicate displayName and icon",
+			index: []schema.Schema{
+				{Name: "nodejs", DisplayName: "Node.js", Icon: "nodejs.svg"},
+				{Name: "nodejs-copy", DisplayName: "Node.js", Icon: "nodejs.svg"},
+			},
+			wantErr: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkForDuplicateDisplayNamesAndIcons(tt.index)
+			assert.Len(t, errs, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckForCrossPlatformPathIssues(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []string
+		wantErr   int
+	}{
+		{
+			name:      "No issues",
+			resources: []string{"devfile.yaml", "archive.tar", "logo.svg"},
+		},
+		{
+			name:      "Case-insensitive collision",
+			resources: []string{"Devfile.yaml", "devfile.yaml"},
+			wantErr:   1,
+		},
+		{
+			name:      "Invalid character",
+			resources: []string{"weird?name.yaml"},
+			wantErr:   1,
+		},
+		{
+			name:      "Reserved device name",
+			resources: []string{"CON.yaml"},
+			wantErr:   1,
+		},
+		{
+			name:      "Trailing dot",
+			resources: []string{"devfile.yaml."},
+			wantErr:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkForCrossPlatformPathIssues("nodejs", tt.resources)
+			assert.Len(t, errs, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckForUnsupportedMediaTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []string
+		wantErr   int
+	}{
+		{
+			name:      "No issues",
+			resources: []string{"devfile.yaml", "archive.tar", "logo.svg", "logo.png", "extension.vsx"},
+		},
+		{
+			name:      "meta.yaml is skipped",
+			resources: []string{"devfile.yaml", "meta.yaml"},
+		},
+		{
+			name:      "Unsupported extension",
+			resources: []string{"devfile.yaml", "readme.md"},
+			wantErr:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkForUnsupportedMediaTypes("nodejs", tt.resources)
+			assert.Len(t, errs, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckForInvalidToolVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions *schema.ToolVersions
+		wantErr  int
+	}{
+		{
+			name:     "Nil minimumToolVersions",
+			versions: nil,
+		},
+		{
+			name:     "Valid versions",
+			versions: &schema.ToolVersions{Odo: "3.2.0", Che: "7.80", DevfileCLI: "2.3.1"},
+		},
+		{
+			name:     "Empty fields are skipped",
+			versions: &schema.ToolVersions{},
+		},
+		{
+			name:     "Invalid odo version",
+			versions: &schema.ToolVersions{Odo: "v3.2.0"},
+			wantErr:  1,
+		},
+		{
+			name:     "Multiple invalid versions",
+			versions: &schema.ToolVersions{Odo: "latest", Che: "7.x"},
+			wantErr:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkForInvalidToolVersions("nodejs", tt.versions)
+			assert.Len(t, errs, tt.wantErr)
+		})
+	}
+}
+
+func TestParseStackDevfileEmbedDevfiles(t *testing.T) {
+	devfileDir, err := ioutil.TempDir("", "embed-devfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(devfileDir)
+
+	content := "schemaVersion: 2.2.0\nmetadata:\n  name: test-stack\n  version: 1.0.0\n  displayName: Test Stack\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(devfileDir, devfile), []byte(content), 0644))
+
+	tests := []struct {
+		name          string
+		opts          ValidationOptions
+		wantInline    string
+		wantTruncated bool
+	}{
+		{
+			name: "Embedding disabled",
+			opts: ValidationOptions{SkipSchemaValidate: true, SkipMetadataValidate: true},
+		},
+		{
+			name:       "Embedding enabled, under the default limit",
+			opts:       ValidationOptions{SkipSchemaValidate: true, SkipMetadataValidate: true, EmbedDevfiles: true},
+			wantInline: content,
+		},
+		{
+			name:          "Embedding enabled, over an explicit limit",
+			opts:          ValidationOptions{SkipSchemaValidate: true, SkipMetadataValidate: true, EmbedDevfiles: true, EmbedDevfilesMaxBytes: 4},
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := newStagePipeline(tt.opts)
+			versionComponent := schema.Version{}
+			indexComponent := schema.Schema{}
+			assert.NoError(t, parseStackDevfile(devfileDir, "test-stack", pipeline, &versionComponent, &indexComponent))
+			assert.Equal(t, tt.wantInline, versionComponent.InlineDevfile)
+			assert.Equal(t, tt.wantTruncated, versionComponent.InlineDevfileTruncated)
+		})
+	}
+}
+
+func TestResolveDevfilePath(t *testing.T) {
+	t.Run("neither exists defaults to devfile.yaml", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "resolve-devfile-path")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path, err := resolveDevfilePath(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, devfile), path)
+	})
+
+	t.Run("only hidden variant exists", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "resolve-devfile-path")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, devfileHidden), []byte("schemaVersion: 2.0.0"), 0644))
+
+		path, err := resolveDevfilePath(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, devfileHidden), path)
+	})
+
+	t.Run("both exist is an error", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "resolve-devfile-path")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, devfile), []byte("schemaVersion: 2.0.0"), 0644))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, devfileHidden), []byte("schemaVersion: 2.0.0"), 0644))
+
+		_, err = resolveDevfilePath(dir)
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckForMissingOuterloopResources(t *testing.T) {
+	const devfileContent = `
+schemaVersion: 2.2.0
+metadata:
+  name: test-stack
+  version: 1.0.0
+  displayName: Test Stack
+components:
+  - name: outerloop-build
+    image:
+      imageName: test-image
+      dockerfile:
+        uri: Dockerfile
+        buildContext: ${PROJECTS_ROOT}
+  - name: outerloop-deploy
+    kubernetes:
+      uri: kubernetes/deploy.yaml
+  - name: outerloop-remote
+    kubernetes:
+      uri: https://example.com/manifest.yaml
+`
+	dir, err := ioutil.TempDir("", "outerloop-resources")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	devfilePath := filepath.Join(dir, devfile)
+	assert.NoError(t, ioutil.WriteFile(devfilePath, []byte(devfileContent), 0644))
+
+	devfileObj, _, err := devfileParser.ParseDevfileAndValidate(parser.ParserArgs{Path: devfilePath})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		resources []string
+		wantErr   int
+	}{
+		{
+			name:      "both local resources present",
+			resources: []string{"devfile.yaml", "Dockerfile", "kubernetes/deploy.yaml"},
+		},
+		{
+			name:      "Dockerfile missing",
+			resources: []string{"devfile.yaml", "kubernetes/deploy.yaml"},
+			wantErr:   1,
+		},
+		{
+			name:      "both local resources missing",
+			resources: []string{"devfile.yaml"},
+			wantErr:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkForMissingOuterloopResources("test-stack", devfileObj, tt.resources)
+			assert.Len(t, errs, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateIndexFileYAML(t *testing.T) {
+	index := []schema.Schema{
+		{Name: "go", DisplayName: "Go Runtime", Versions: []schema.Version{{Version: "1.1.0", Default: true}}},
+	}
+
+	indexFilePath := filepath.Join(t.TempDir(), "index.yaml")
+	assert.NoError(t, CreateIndexFileYAML(index, indexFilePath))
+
+	bytes, err := ioutil.ReadFile(indexFilePath)
+	assert.NoError(t, err)
+
+	var got []schema.Schema
+	assert.NoError(t, yaml.Unmarshal(bytes, &got))
+	assert.Equal(t, index, got)
+}
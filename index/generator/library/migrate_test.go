@@ -0,0 +1,76 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestMigrateLegacyRegistry(t *testing.T) {
+	registryDir, err := ioutil.TempDir("", "migrate-registry-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDir)
+
+	legacyStackDir := filepath.Join(registryDir, "stacks", "legacy-stack")
+	assert.NoError(t, os.MkdirAll(legacyStackDir, 0755))
+	devfileYaml := `schemaVersion: 2.2.0
+metadata:
+  name: legacy-stack
+  version: 2.0.0
+  displayName: Legacy Stack
+  description: a stack that predates the versioned layout
+  icon: https://example.com/icon.png
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(legacyStackDir, "devfile.yaml"), []byte(devfileYaml), 0644))
+
+	alreadyMigratedDir := filepath.Join(registryDir, "stacks", "already-migrated")
+	assert.NoError(t, os.MkdirAll(alreadyMigratedDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(alreadyMigratedDir, "stack.yaml"), []byte("name: already-migrated\n"), 0644))
+
+	emptyStackDir := filepath.Join(registryDir, "stacks", "empty-stack")
+	assert.NoError(t, os.MkdirAll(emptyStackDir, 0755))
+
+	summary, err := MigrateLegacyRegistry(registryDir)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"legacy-stack"}, summary.Migrated)
+	assert.ElementsMatch(t, []string{"already-migrated", "empty-stack"}, summary.Skipped)
+
+	assert.NoFileExists(t, filepath.Join(legacyStackDir, "devfile.yaml"))
+	assert.FileExists(t, filepath.Join(legacyStackDir, "2.0.0", "devfile.yaml"))
+
+	stackYamlBytes, err := ioutil.ReadFile(filepath.Join(legacyStackDir, "stack.yaml"))
+	assert.NoError(t, err)
+	var stackInfo indexSchema.StackInfo
+	assert.NoError(t, yaml.Unmarshal(stackYamlBytes, &stackInfo))
+	assert.Equal(t, "legacy-stack", stackInfo.Name)
+	assert.Equal(t, "Legacy Stack", stackInfo.DisplayName)
+	assert.Equal(t, "a stack that predates the versioned layout", stackInfo.Description)
+	assert.Len(t, stackInfo.Versions, 1)
+	assert.Equal(t, "2.0.0", stackInfo.Versions[0].Version)
+	assert.True(t, stackInfo.Versions[0].Default)
+}
+
+func TestMigrateLegacyRegistryDefaultsVersion(t *testing.T) {
+	registryDir, err := ioutil.TempDir("", "migrate-registry-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDir)
+
+	stackDir := filepath.Join(registryDir, "stacks", "no-version-stack")
+	assert.NoError(t, os.MkdirAll(stackDir, 0755))
+	devfileYaml := `schemaVersion: 2.2.0
+metadata:
+  name: no-version-stack
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(stackDir, "devfile.yaml"), []byte(devfileYaml), 0644))
+
+	summary, err := MigrateLegacyRegistry(registryDir)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"no-version-stack"}, summary.Migrated)
+	assert.FileExists(t, filepath.Join(stackDir, defaultScaffoldVersion, "devfile.yaml"))
+}
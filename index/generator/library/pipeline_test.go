@@ -0,0 +1,31 @@
+package library
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStagePipelineReport(t *testing.T) {
+	pipeline := newStagePipeline(ValidationOptions{SkipDeepValidate: true})
+
+	pipeline.time(StageParse, func() { time.Sleep(time.Millisecond) })
+	pipeline.time(StageParse, func() { time.Sleep(time.Millisecond) })
+	pipeline.time(StageSchemaValidate, func() {})
+
+	timings := pipeline.report()
+	if len(timings) != 4 {
+		t.Fatalf("expected 4 stages in report, got %d", len(timings))
+	}
+
+	seen := map[ValidationStage]time.Duration{}
+	for _, timing := range timings {
+		seen[timing.Stage] = timing.Duration
+	}
+
+	if seen[StageParse] < 2*time.Millisecond {
+		t.Errorf("expected StageParse duration to accumulate across calls, got %v", seen[StageParse])
+	}
+	if seen[StageDeepValidate] != 0 {
+		t.Errorf("expected StageDeepValidate to report zero duration when never timed, got %v", seen[StageDeepValidate])
+	}
+}
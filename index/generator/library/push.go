@@ -0,0 +1,177 @@
+package library
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// artifactManifestName is the file written alongside the index by PushIndexedArtifacts, mapping
+// each stack version to the exact OCI ref and digest it was (or would be) pushed to.
+const artifactManifestName = "artifacts.json"
+
+// ArtifactMapping records the exact OCI ref and content digest a stack version's artifacts were
+// (or, in dry-run mode, would be) pushed to. Written out as registryDirPath/artifacts.json by
+// PushIndexedArtifacts so the push step, GC, and mirroring tooling can consume a single generated
+// mapping instead of re-deriving the repo/tag naming convention themselves.
+type ArtifactMapping struct {
+	Stack   string `json:"stack"`
+	Version string `json:"version"`
+	Ref     string `json:"ref"`
+	Digest  string `json:"digest"`
+}
+
+// PushOptions configures PushIndexedArtifacts.
+type PushOptions struct {
+	// Registry is the target OCI registry host and repository namespace to push to,
+	// e.g. "quay.io/devfile/devfile-catalog"
+	Registry string
+	// DryRun prints the ref and digest that would be pushed for each artifact instead of
+	// actually contacting the registry
+	DryRun bool
+}
+
+// PushIndexedArtifacts pushes every stack version's packaged resources, plus the index file
+// itself, to opts.Registry, unifying registry publishing with the rest of the build pipeline
+// instead of leaving it to a separate shell script. It shells out to the oras CLI
+// (https://oras.land) since no OCI client is vendored in this module.
+func PushIndexedArtifacts(registryDirPath string, index []schema.Schema, indexFilePath string, opts PushOptions) error {
+	if opts.Registry == "" {
+		return fmt.Errorf("push registry must be set")
+	}
+
+	var mappings []ArtifactMapping
+	for _, entry := range index {
+		for _, version := range entry.Versions {
+			if len(version.Resources) == 0 {
+				continue
+			}
+
+			ref, err := pushRef(opts.Registry, version)
+			if err != nil {
+				return fmt.Errorf("%s version %s: %v", entry.Name, version.Version, err)
+			}
+
+			files, err := resourceFiles(registryDirPath, entry.Name, version)
+			if err != nil {
+				return fmt.Errorf("%s version %s: %v", entry.Name, version.Version, err)
+			}
+
+			digest, err := pushArtifact(ref, files, opts.DryRun)
+			if err != nil {
+				return fmt.Errorf("failed to push %s version %s: %v", entry.Name, version.Version, err)
+			}
+			mappings = append(mappings, ArtifactMapping{
+				Stack:   entry.Name,
+				Version: version.Version,
+				Ref:     ref,
+				Digest:  digest,
+			})
+		}
+	}
+
+	indexRef := path.Join(opts.Registry, "index") + ":latest"
+	if _, err := pushArtifact(indexRef, []string{indexFilePath}, opts.DryRun); err != nil {
+		return fmt.Errorf("failed to push index artifact: %v", err)
+	}
+
+	if err := writeArtifactManifest(registryDirPath, mappings); err != nil {
+		return fmt.Errorf("failed to write %s: %v", artifactManifestName, err)
+	}
+
+	return nil
+}
+
+// writeArtifactManifest writes mappings to registryDirPath/artifacts.json.
+func writeArtifactManifest(registryDirPath string, mappings []ArtifactMapping) error {
+	bytes, err := json.MarshalIndent(mappings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(registryDirPath, artifactManifestName), bytes, 0644)
+}
+
+// pushRef builds the OCI ref a version's artifacts should be pushed to, reusing the same
+// self link that the index server pushes and pulls by.
+func pushRef(registry string, version schema.Version) (string, error) {
+	self, ok := version.Links["self"]
+	if !ok {
+		return "", fmt.Errorf("version %s has no self link", version.Version)
+	}
+	return path.Join(registry, self), nil
+}
+
+// resourceFiles resolves a version's declared resources to file paths on disk, the same way
+// pushStackToRegistry does in the index server.
+func resourceFiles(registryDirPath, stackName string, version schema.Version) ([]string, error) {
+	var files []string
+	for _, resource := range version.Resources {
+		if resource == "meta.yaml" {
+			// Some registries may still have the meta.yaml in it, but we don't need it, so skip pushing it up
+			continue
+		}
+
+		resourcePath := filepath.Join(registryDirPath, "stacks", stackName, version.Version, resource)
+		if _, err := os.Stat(resourcePath); os.IsNotExist(err) {
+			resourcePath = filepath.Join(registryDirPath, "stacks", stackName, resource)
+		}
+		if _, err := os.Stat(resourcePath); err != nil {
+			return nil, fmt.Errorf("resource %s not found: %v", resource, err)
+		}
+		files = append(files, resourcePath)
+	}
+	return files, nil
+}
+
+// pushArtifact pushes files to ref via the oras CLI, or in dry-run mode logs the ref and the
+// digest that would be pushed without contacting the registry, and returns the content digest
+// either way so callers can record it in the artifact manifest.
+func pushArtifact(ref string, files []string, dryRun bool) (string, error) {
+	digest, err := digestFiles(files)
+	if err != nil {
+		return "", err
+	}
+
+	if dryRun {
+		log.Printf("[dry-run] would push %s (%d file(s), digest %s)\n", ref, len(files), digest)
+		return digest, nil
+	}
+
+	args := append([]string{"push", ref}, files...)
+	cmd := exec.Command("oras", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	log.Printf("Pushing %d file(s) to %s...\n", len(files), ref)
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// digestFiles computes the combined sha256 digest of a set of files, in order, so a dry run can
+// report the same content identity a real push would produce.
+func digestFiles(filePaths []string) (string, error) {
+	h := sha256.New()
+	for _, filePath := range filePaths {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,117 @@
+package library
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushRef(t *testing.T) {
+	ref, err := pushRef("quay.io/devfile/devfile-catalog", schema.Version{
+		Version: "1.0.0",
+		Links: map[string]string{
+			"self": "devfile-catalog/nodejs:1.0.0",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/devfile/devfile-catalog/devfile-catalog/nodejs:1.0.0", ref)
+}
+
+func TestPushRefMissingSelfLink(t *testing.T) {
+	_, err := pushRef("quay.io/devfile/devfile-catalog", schema.Version{Version: "1.0.0"})
+	assert.Error(t, err)
+}
+
+func TestResourceFiles(t *testing.T) {
+	registryDirPath, err := ioutil.TempDir("", "push-resources")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDirPath)
+
+	versionDir := filepath.Join(registryDirPath, "stacks", "nodejs", "1.0.0")
+	assert.NoError(t, os.MkdirAll(versionDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(versionDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(versionDir, "meta.yaml"), []byte("name: nodejs"), 0644))
+
+	files, err := resourceFiles(registryDirPath, "nodejs", schema.Version{
+		Version:   "1.0.0",
+		Resources: []string{"devfile.yaml", "meta.yaml"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(versionDir, "devfile.yaml")}, files)
+}
+
+func TestDigestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "digest-files")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "hello.txt")
+	assert.NoError(t, ioutil.WriteFile(filePath, []byte("hello world"), 0644))
+
+	digest, err := digestFiles([]string{filePath})
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", digest)
+}
+
+func TestPushArtifactDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push-artifact")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "devfile.yaml")
+	assert.NoError(t, ioutil.WriteFile(filePath, []byte("schemaVersion: 2.0.0"), 0644))
+
+	// Dry run must not attempt to invoke the oras CLI, so it should succeed even when the
+	// binary isn't installed.
+	digest, err := pushArtifact("example.com/devfile-catalog/nodejs:1.0.0", []string{filePath}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:607b99e6702600f1e5f2fe35a5408abef6f978e3e2c5f3ca977d696f54243f3d", digest)
+}
+
+func TestPushIndexedArtifactsWritesArtifactManifest(t *testing.T) {
+	registryDirPath, err := ioutil.TempDir("", "push-artifact-manifest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDirPath)
+
+	versionDir := filepath.Join(registryDirPath, "stacks", "nodejs", "1.0.0")
+	assert.NoError(t, os.MkdirAll(versionDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(versionDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+
+	indexFilePath := filepath.Join(registryDirPath, "index.json")
+	assert.NoError(t, ioutil.WriteFile(indexFilePath, []byte("[]"), 0644))
+
+	index := []schema.Schema{
+		{
+			Name: "nodejs",
+			Versions: []schema.Version{
+				{
+					Version:   "1.0.0",
+					Resources: []string{"devfile.yaml"},
+					Links:     map[string]string{"self": "devfile-catalog/nodejs:1.0.0"},
+				},
+			},
+		},
+	}
+
+	err = PushIndexedArtifacts(registryDirPath, index, indexFilePath, PushOptions{Registry: "example.com/devfile-catalog", DryRun: true})
+	assert.NoError(t, err)
+
+	manifestBytes, err := ioutil.ReadFile(filepath.Join(registryDirPath, artifactManifestName))
+	assert.NoError(t, err)
+
+	var mappings []ArtifactMapping
+	assert.NoError(t, json.Unmarshal(manifestBytes, &mappings))
+	assert.Equal(t, []ArtifactMapping{
+		{
+			Stack:   "nodejs",
+			Version: "1.0.0",
+			Ref:     "example.com/devfile-catalog/devfile-catalog/nodejs:1.0.0",
+			Digest:  "sha256:607b99e6702600f1e5f2fe35a5408abef6f978e3e2c5f3ca977d696f54243f3d",
+		},
+	}, mappings)
+}
@@ -0,0 +1,41 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateHTMLCatalogReport(t *testing.T) {
+	index := []schema.Schema{
+		{
+			Name:        "java-maven",
+			DisplayName: "Maven Java",
+			Description: "Upstream Maven and OpenJDK 11",
+			Type:        schema.StackDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.1.0", Default: true},
+			},
+		},
+	}
+
+	tmpDir, err := ioutil.TempDir("", "report-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	reportPath := filepath.Join(tmpDir, "report.html")
+	err = CreateHTMLCatalogReport(index, reportPath)
+	assert.NoError(t, err)
+
+	bytes, err := ioutil.ReadFile(reportPath)
+	assert.NoError(t, err)
+
+	content := string(bytes)
+	assert.True(t, strings.Contains(content, "Maven Java"))
+	assert.True(t, strings.Contains(content, "1.1.0"))
+}
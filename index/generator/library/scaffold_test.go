@@ -0,0 +1,40 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaffoldStack(t *testing.T) {
+	registryDir, err := ioutil.TempDir("", "scaffold-registry-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDir)
+
+	err = ScaffoldStack(registryDir, "my-stack", ScaffoldOptions{
+		Provider:   "Red Hat",
+		SupportUrl: "https://example.com/support",
+	})
+	assert.NoError(t, err)
+
+	stackDir := filepath.Join(registryDir, "stacks", "my-stack")
+	assert.FileExists(t, filepath.Join(stackDir, "stack.yaml"))
+	assert.FileExists(t, filepath.Join(stackDir, "OWNERS"))
+	assert.FileExists(t, filepath.Join(stackDir, defaultScaffoldVersion, "devfile.yaml"))
+
+	// Scaffolding into an existing stack directory should fail rather than silently overwrite it.
+	err = ScaffoldStack(registryDir, "my-stack", ScaffoldOptions{})
+	assert.Error(t, err)
+}
+
+func TestScaffoldStackRequiresName(t *testing.T) {
+	registryDir, err := ioutil.TempDir("", "scaffold-registry-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDir)
+
+	err = ScaffoldStack(registryDir, "", ScaffoldOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,302 @@
+package library
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devfile/registry-support/index/generator/gitutil"
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// samplesSnapshotName is the file name a sample's packaged source snapshot is written under,
+// alongside its devfile.yaml, mirroring how stacks package their resources into archive.tar.
+const samplesSnapshotName = "snapshot.tar.gz"
+
+// CompressionAlgorithm identifies how SnapshotSamplesWithOptions compresses a sample's packaged
+// source snapshot. It's recorded on the version's ArchiveContentEncoding field so a puller knows
+// how to decode the archive it downloaded.
+type CompressionAlgorithm string
+
+const (
+	// GzipCompression is the default: every platform's tar can already decode it, and it's what
+	// this codebase has always produced.
+	GzipCompression CompressionAlgorithm = "gzip"
+	// NoCompression stores the tar uncompressed, trading bandwidth for the CPU cost of gzip on
+	// registries that are disk- rather than bandwidth-constrained.
+	NoCompression CompressionAlgorithm = "identity"
+)
+
+// unsupportedCompressionAlgorithms names algorithms this build recognizes but can't produce yet:
+// zstd would cut archive size further, but doing so needs a compression library this module
+// doesn't currently vendor, so requesting it fails fast instead of silently falling back to gzip.
+var unsupportedCompressionAlgorithms = map[CompressionAlgorithm]bool{
+	"zstd": true,
+}
+
+// SnapshotOptions bounds how long SnapshotSamples spends cloning samples' git remotes, so a
+// single hung or slow-to-respond remote can't stall a nightly build indefinitely.
+type SnapshotOptions struct {
+	// PerSampleTimeout caps how long a single sample's git clone and checkout may take before
+	// it's abandoned and reported as timed out. Zero (the default) means no per-sample limit.
+	PerSampleTimeout time.Duration
+	// TotalTimeout caps the wall-clock time spent across all samples. Once it elapses, any
+	// sample not yet started is reported as timed out without being attempted. Zero (the
+	// default) means no overall limit.
+	TotalTimeout time.Duration
+	// Compression selects how each sample's snapshot archive is compressed. Zero-valued (empty
+	// string) defaults to GzipCompression, matching this function's historical behavior.
+	Compression CompressionAlgorithm
+	// Retry configures retrying a sample's clone on transient failure (e.g. a dropped
+	// connection), with exponential backoff and jitter between attempts. A zero-valued Retry
+	// disables retrying, matching this function's historical behavior.
+	Retry gitutil.RetryOptions
+}
+
+// SnapshotSummary reports the outcome of snapshotting every sample, distinguishing samples
+// abandoned for running past PerSampleTimeout or TotalTimeout from samples that failed outright
+// (e.g. an invalid revision), so a build summary can call out a hung remote instead of treating
+// it the same as a bad devfile.
+type SnapshotSummary struct {
+	Succeeded []string
+	TimedOut  []string
+	Failed    []string
+}
+
+// SnapshotSamples clones each sample's declared git revision, packages it into a snapshot
+// archive under registryDirPath/samples, and records the archive's digest on the corresponding
+// index entry, so samples become immutable, verifiable artifacts instead of live GitHub
+// dependencies fetched fresh on every pull. Samples with no git information are left untouched.
+// It never times out; use SnapshotSamplesWithOptions to bound how long a hung git remote can
+// block generation.
+func SnapshotSamples(registryDirPath string, index []schema.Schema) error {
+	_, err := SnapshotSamplesWithOptions(registryDirPath, index, SnapshotOptions{})
+	return err
+}
+
+// SnapshotSamplesWithOptions behaves like SnapshotSamples, but abandons a sample's clone once it
+// runs past opts.PerSampleTimeout, and abandons every remaining sample once opts.TotalTimeout
+// elapses, reporting both cases in the returned SnapshotSummary instead of blocking generation on
+// an unresponsive git remote. A zero-valued opts field disables its corresponding limit.
+func SnapshotSamplesWithOptions(registryDirPath string, index []schema.Schema, opts SnapshotOptions) (SnapshotSummary, error) {
+	compression := opts.Compression
+	if compression == "" {
+		compression = GzipCompression
+	}
+	if unsupportedCompressionAlgorithms[compression] {
+		return SnapshotSummary{}, fmt.Errorf("compression algorithm %q is not supported by this build", compression)
+	}
+	if compression != GzipCompression && compression != NoCompression {
+		return SnapshotSummary{}, fmt.Errorf("unknown compression algorithm %q", compression)
+	}
+
+	samplesDir := filepath.Join(registryDirPath, "samples")
+
+	ctx := context.Background()
+	if opts.TotalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.TotalTimeout)
+		defer cancel()
+	}
+
+	var summary SnapshotSummary
+	for i := range index {
+		if index[i].Type != schema.SampleDevfileType {
+			continue
+		}
+
+		for j := range index[i].Versions {
+			version := &index[i].Versions[j]
+			if version.Git == nil {
+				continue
+			}
+
+			label := fmt.Sprintf("%s@%s", index[i].Name, version.Version)
+
+			if ctx.Err() != nil {
+				summary.TimedOut = append(summary.TimedOut, label)
+				continue
+			}
+
+			sampleCtx := ctx
+			if opts.PerSampleTimeout > 0 {
+				var cancel context.CancelFunc
+				sampleCtx, cancel = context.WithTimeout(ctx, opts.PerSampleTimeout)
+				defer cancel()
+			}
+
+			versionDir := filepath.Join(samplesDir, index[i].Name, version.Version)
+			result, err := snapshotSample(sampleCtx, versionDir, version.Git, compression, opts.Retry)
+			if err != nil {
+				if errors.Is(sampleCtx.Err(), context.DeadlineExceeded) {
+					summary.TimedOut = append(summary.TimedOut, label)
+					continue
+				}
+				summary.Failed = append(summary.Failed, label)
+				return summary, fmt.Errorf("failed to snapshot sample %s version %s: %v", index[i].Name, version.Version, err)
+			}
+			version.Digest = result.digest
+			version.ArchiveContentEncoding = string(compression)
+			version.ArchiveSize = result.compressedSize
+			version.ArchiveUncompressedSize = result.uncompressedSize
+			summary.Succeeded = append(summary.Succeeded, label)
+		}
+	}
+
+	return summary, nil
+}
+
+// snapshotResult reports the outcome of packaging a single sample's snapshot archive: its
+// digest, and its compressed and uncompressed sizes, so the caller can record all three on the
+// corresponding index version.
+type snapshotResult struct {
+	digest           string
+	compressedSize   int64
+	uncompressedSize int64
+}
+
+// snapshotSample clones git.Url at git.Revision into a temp directory and packages it (excluding
+// .git) into destDir/snapshot.tar.gz, compressed with the given algorithm. ctx bounds how long
+// the clone and checkout may take, across every retry attempt combined.
+func snapshotSample(ctx context.Context, destDir string, git *schema.Git, compression CompressionAlgorithm, retry gitutil.RetryOptions) (snapshotResult, error) {
+	cloneDir, err := newTempDir("sample-snapshot")
+	if err != nil {
+		return snapshotResult{}, err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	if err := gitutil.CloneRevisionWithRetry(ctx, git.Url, git.Revision, cloneDir, gitutil.ResolveAuth(git.Url), retry); err != nil {
+		return snapshotResult{}, err
+	}
+
+	sourceDir := cloneDir
+	if git.SubDir != "" {
+		sourceDir = filepath.Join(cloneDir, git.SubDir)
+	}
+
+	// Guard against the cloned repo declaring both devfile.yaml and .devfile.yaml, matching the
+	// local stack and sample validation behavior instead of silently archiving both.
+	if _, err := resolveDevfilePath(sourceDir); err != nil {
+		return snapshotResult{}, err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return snapshotResult{}, err
+	}
+	archivePath := filepath.Join(destDir, samplesSnapshotName)
+	uncompressedSize, err := archiveDirectory(sourceDir, archivePath, compression)
+	if err != nil {
+		return snapshotResult{}, err
+	}
+
+	digest, compressedSize, err := digestAndSizeFile(archivePath)
+	if err != nil {
+		return snapshotResult{}, err
+	}
+	return snapshotResult{digest: digest, compressedSize: compressedSize, uncompressedSize: uncompressedSize}, nil
+}
+
+// archiveDirectory writes a tar of sourceDir (excluding .git) to archivePath, compressed with
+// compression, and returns the uncompressed tar's size in bytes.
+func archiveDirectory(sourceDir, archivePath string, compression CompressionAlgorithm) (int64, error) {
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		return 0, err
+	}
+	defer archiveFile.Close()
+
+	var out io.Writer = archiveFile
+	var gzipWriter *gzip.Writer
+	if compression == GzipCompression {
+		gzipWriter = gzip.NewWriter(archiveFile)
+		defer gzipWriter.Close()
+		out = gzipWriter
+	}
+
+	counter := &countingWriter{}
+	tarWriter := tar.NewWriter(io.MultiWriter(out, counter))
+	defer tarWriter.Close()
+
+	err = filepath.Walk(sourceDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(sourceDir, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+		if relPath == ".git" || strings.HasPrefix(relPath, ".git"+string(filepath.Separator)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = relPath
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return counter.n, nil
+}
+
+// countingWriter counts the total number of bytes written through it, discarding the bytes
+// themselves; used to measure the uncompressed size of a tar stream as it's written.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// digestAndSizeFile returns the sha256 digest of a file's contents, in the "sha256:<hex>" form
+// used by OCI manifest digests, along with the file's size in bytes.
+func digestAndSizeFile(filePath string) (string, int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), size, nil
+}
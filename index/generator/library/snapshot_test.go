@@ -0,0 +1,195 @@
+package library
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devfile/registry-support/index/generator/gitutil"
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestArchiveDirectoryExcludesGit(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "archive-source")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(sourceDir, ".git"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+
+	destDir, err := ioutil.TempDir("", "archive-dest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	archivePath := filepath.Join(destDir, samplesSnapshotName)
+	uncompressedSize, err := archiveDirectory(sourceDir, archivePath, GzipCompression)
+	assert.NoError(t, err)
+	assert.Greater(t, uncompressedSize, int64(0))
+
+	names := listTarEntries(t, archivePath, GzipCompression)
+	assert.Contains(t, names, "devfile.yaml")
+	for _, name := range names {
+		assert.NotContains(t, name, ".git")
+	}
+}
+
+func TestArchiveDirectoryNoCompression(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "archive-source")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+
+	destDir, err := ioutil.TempDir("", "archive-dest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	archivePath := filepath.Join(destDir, samplesSnapshotName)
+	_, err = archiveDirectory(sourceDir, archivePath, NoCompression)
+	assert.NoError(t, err)
+
+	names := listTarEntries(t, archivePath, NoCompression)
+	assert.Contains(t, names, "devfile.yaml")
+}
+
+func TestDigestAndSizeFile(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "digest-test")
+	assert.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString("hello world")
+	assert.NoError(t, err)
+	assert.NoError(t, tmpFile.Close())
+
+	digest, size, err := digestAndSizeFile(tmpFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", digest)
+	assert.Equal(t, int64(len("hello world")), size)
+}
+
+func TestSnapshotSamplesWithOptionsUnsupportedCompression(t *testing.T) {
+	registryDirPath, err := ioutil.TempDir("", "snapshot-bad-compression")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDirPath)
+
+	_, err = SnapshotSamplesWithOptions(registryDirPath, nil, SnapshotOptions{Compression: "zstd"})
+	assert.Error(t, err)
+}
+
+func TestSnapshotSamplesWithOptionsTotalTimeoutSkipsRemaining(t *testing.T) {
+	registryDirPath, err := ioutil.TempDir("", "snapshot-total-timeout")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDirPath)
+
+	index := []schema.Schema{
+		{
+			Name: "sample1",
+			Type: schema.SampleDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.0.0", Git: &schema.Git{Url: "https://example.com/sample1.git"}},
+			},
+		},
+	}
+
+	summary, err := SnapshotSamplesWithOptions(registryDirPath, index, SnapshotOptions{TotalTimeout: time.Nanosecond})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sample1@1.0.0"}, summary.TimedOut)
+	assert.Empty(t, summary.Succeeded)
+	assert.Empty(t, index[0].Versions[0].Digest)
+}
+
+func TestCloneAtRevisionRespectsContextTimeout(t *testing.T) {
+	destDir, err := ioutil.TempDir("", "clone-timeout")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), -1*time.Second)
+	defer cancel()
+
+	err = gitutil.CloneRevision(ctx, "https://example.com/sample1.git", "", destDir, gitutil.GitAuth{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSnapshotSampleRejectsConflictingDevfiles(t *testing.T) {
+	repoDir, err := ioutil.TempDir("", "snapshot-conflict-repo")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(output))
+	}
+	runGit("init", "--quiet")
+	runGit("config", "user.email", "test@example.com")
+	runGit("config", "user.name", "test")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, ".devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+	runGit("add", ".")
+	runGit("commit", "--quiet", "-m", "initial")
+
+	destDir, err := ioutil.TempDir("", "snapshot-conflict-dest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destDir)
+
+	_, err = snapshotSample(context.Background(), destDir, &schema.Git{Url: repoDir}, GzipCompression, gitutil.RetryOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "both")
+}
+
+func TestSnapshotSamplesWithOptionsReportsFailure(t *testing.T) {
+	registryDirPath, err := ioutil.TempDir("", "snapshot-failure")
+	assert.NoError(t, err)
+	defer os.RemoveAll(registryDirPath)
+
+	index := []schema.Schema{
+		{
+			Name: "sample1",
+			Type: schema.SampleDevfileType,
+			Versions: []schema.Version{
+				{Version: "1.0.0", Git: &schema.Git{Url: filepath.Join(registryDirPath, "does-not-exist.git")}},
+			},
+		},
+	}
+
+	summary, err := SnapshotSamplesWithOptions(registryDirPath, index, SnapshotOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"sample1@1.0.0"}, summary.Failed)
+}
+
+func listTarEntries(t *testing.T, archivePath string, compression CompressionAlgorithm) []string {
+	t.Helper()
+	file, err := os.Open(archivePath)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var tarSource io.Reader = file
+	if compression == GzipCompression {
+		gzipReader, err := gzip.NewReader(file)
+		assert.NoError(t, err)
+		defer gzipReader.Close()
+		tarSource = gzipReader
+	}
+
+	var names []string
+	tarReader := tar.NewReader(tarSource)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, header.Name)
+	}
+	return names
+}
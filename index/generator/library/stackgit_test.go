@@ -0,0 +1,97 @@
+package library
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+func initGitRepoWithDevfile(t *testing.T, name string) string {
+	t.Helper()
+	repoDir, err := ioutil.TempDir("", name)
+	assert.NoError(t, err)
+
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(output))
+	}
+	runGit("init", "--quiet")
+	runGit("config", "user.email", "test@example.com")
+	runGit("config", "user.name", "test")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644))
+	runGit("add", ".")
+	runGit("commit", "--quiet", "-m", "initial")
+
+	return repoDir
+}
+
+func TestDownloadStackVersionsGitDownloadsEachJob(t *testing.T) {
+	repoDir := initGitRepoWithDevfile(t, "stackgit-repo")
+	defer os.RemoveAll(repoDir)
+
+	destRoot, err := ioutil.TempDir("", "stackgit-dest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destRoot)
+
+	jobs := []stackGitDownloadJob{
+		{label: "stack1@1.0.0", git: &schema.Git{Url: repoDir}, destDir: filepath.Join(destRoot, "1.0.0")},
+		{label: "stack1@2.0.0", git: &schema.Git{Url: repoDir}, destDir: filepath.Join(destRoot, "2.0.0")},
+	}
+
+	err = downloadStackVersionsGit(context.Background(), jobs, StackGitDownloadOptions{})
+	assert.NoError(t, err)
+
+	for _, job := range jobs {
+		assert.FileExists(t, filepath.Join(job.destDir, "devfile.yaml"))
+	}
+}
+
+func TestDownloadStackVersionsGitAggregatesFailures(t *testing.T) {
+	repoDir := initGitRepoWithDevfile(t, "stackgit-repo-ok")
+	defer os.RemoveAll(repoDir)
+
+	destRoot, err := ioutil.TempDir("", "stackgit-dest-mixed")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destRoot)
+
+	jobs := []stackGitDownloadJob{
+		{label: "good@1.0.0", git: &schema.Git{Url: repoDir}, destDir: filepath.Join(destRoot, "good")},
+		{label: "bad@1.0.0", git: &schema.Git{Url: filepath.Join(destRoot, "does-not-exist")}, destDir: filepath.Join(destRoot, "bad")},
+	}
+
+	err = downloadStackVersionsGit(context.Background(), jobs, StackGitDownloadOptions{Concurrency: 2})
+	assert.Error(t, err)
+
+	downloadErr, ok := err.(*StackGitDownloadError)
+	assert.True(t, ok)
+	assert.Len(t, downloadErr.Failures, 1)
+	assert.Contains(t, downloadErr.Failures, "bad@1.0.0")
+	assert.FileExists(t, filepath.Join(destRoot, "good", "devfile.yaml"))
+}
+
+func TestDownloadStackVersionsGitRespectsPerDownloadTimeout(t *testing.T) {
+	destRoot, err := ioutil.TempDir("", "stackgit-dest-timeout")
+	assert.NoError(t, err)
+	defer os.RemoveAll(destRoot)
+
+	jobs := []stackGitDownloadJob{
+		{label: "slow@1.0.0", git: &schema.Git{Url: "https://example.com/slow.git"}, destDir: filepath.Join(destRoot, "slow")},
+	}
+
+	err = downloadStackVersionsGit(context.Background(), jobs, StackGitDownloadOptions{PerDownloadTimeout: time.Nanosecond})
+	assert.Error(t, err)
+
+	downloadErr, ok := err.(*StackGitDownloadError)
+	assert.True(t, ok)
+	assert.ErrorIs(t, downloadErr.Failures["slow@1.0.0"], context.DeadlineExceeded)
+}
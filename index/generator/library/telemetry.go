@@ -0,0 +1,83 @@
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// BuildEvent describes a single registry index generation run. It is emitted to a
+// centrally-monitored endpoint so the devfile team can track community registry build
+// health; the source registry itself is intentionally not identified.
+type BuildEvent struct {
+	// Duration is how long index generation took, from parsing through validation.
+	Duration time.Duration `json:"duration"`
+	// StackCount is the number of stack devfiles in the generated index.
+	StackCount int `json:"stackCount"`
+	// SampleCount is the number of sample devfiles in the generated index.
+	SampleCount int `json:"sampleCount"`
+	// FailureCount is the number of validation stages that reported an error during the run.
+	FailureCount int `json:"failureCount"`
+}
+
+// TelemetryOptions configures where build events are reported. Reporting is fully opt-in:
+// a zero-value TelemetryOptions (empty Endpoint) never sends anything.
+type TelemetryOptions struct {
+	// Endpoint is the URL build events are POSTed to as JSON. Leave empty to disable reporting.
+	Endpoint string
+}
+
+// ReportBuildEvent summarizes a generation run and, if opts.Endpoint is set, POSTs it as a
+// BuildEvent. Reporting is opt-in and best-effort: any error is returned so the caller can
+// decide whether to surface it, but it never prevents index generation from completing.
+func ReportBuildEvent(opts TelemetryOptions, index []schema.Schema, duration time.Duration, failureCount int) error {
+	if opts.Endpoint == "" {
+		return nil
+	}
+	return reportBuildEvent(opts, newBuildEvent(index, duration, failureCount))
+}
+
+// newBuildEvent summarizes a generation run into a BuildEvent.
+func newBuildEvent(index []schema.Schema, duration time.Duration, failureCount int) BuildEvent {
+	event := BuildEvent{
+		Duration:     duration,
+		FailureCount: failureCount,
+	}
+	for _, entry := range index {
+		switch entry.Type {
+		case schema.StackDevfileType:
+			event.StackCount++
+		case schema.SampleDevfileType:
+			event.SampleCount++
+		}
+	}
+	return event
+}
+
+// reportBuildEvent POSTs the build event to opts.Endpoint as JSON. A reporting failure is
+// never fatal to index generation; it is returned so the caller can decide whether to log it.
+func reportBuildEvent(opts TelemetryOptions, event BuildEvent) error {
+	if opts.Endpoint == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal build event: %v", err)
+	}
+
+	resp, err := http.Post(opts.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send build event to %s: %v", opts.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("build event endpoint %s returned status %s", opts.Endpoint, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package library
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportBuildEventDisabledByDefault(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	err := ReportBuildEvent(TelemetryOptions{}, nil, time.Second, 0)
+	assert.NoError(t, err)
+	assert.False(t, called, "no event should be sent when the endpoint is not configured")
+}
+
+func TestReportBuildEvent(t *testing.T) {
+	var received BuildEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	index := []schema.Schema{
+		{Name: "java-maven", Type: schema.StackDevfileType},
+		{Name: "go", Type: schema.StackDevfileType},
+		{Name: "nodejs-basic", Type: schema.SampleDevfileType},
+	}
+
+	err := ReportBuildEvent(TelemetryOptions{Endpoint: server.URL}, index, 2*time.Second, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, received.StackCount)
+	assert.Equal(t, 1, received.SampleCount)
+	assert.Equal(t, 1, received.FailureCount)
+	assert.Equal(t, 2*time.Second, received.Duration)
+}
+
+func TestReportBuildEventEndpointError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := ReportBuildEvent(TelemetryOptions{Endpoint: server.URL}, nil, time.Second, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package library
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+type stubTester struct {
+	err error
+}
+
+func (t stubTester) Test(stackVersionDirPath string, entry schema.Schema, version schema.Version) error {
+	return t.err
+}
+
+func TestRunTestersRecordsResultForEachTester(t *testing.T) {
+	report := &ValidationReport{}
+	testers := []StackTester{
+		stubTester{},
+		stubTester{err: errors.New("odo build failed")},
+	}
+	entry := schema.Schema{Name: "go"}
+	version := schema.Version{Version: "1.0.0"}
+
+	if err := runTesters(testers, report, false, "/stacks/go/1.0.0", entry, version); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.TestResults) != 2 {
+		t.Fatalf("expected 2 test results, got %d", len(report.TestResults))
+	}
+	if !report.TestResults[0].Passed {
+		t.Errorf("expected first result to have passed, got %+v", report.TestResults[0])
+	}
+	if report.TestResults[1].Passed || report.TestResults[1].Message != "odo build failed" {
+		t.Errorf("expected second result to record the failure, got %+v", report.TestResults[1])
+	}
+	if !report.HasFailedTests() {
+		t.Error("expected HasFailedTests to report true")
+	}
+}
+
+func TestRunTestersStrictFailsOnError(t *testing.T) {
+	report := &ValidationReport{}
+	testers := []StackTester{stubTester{err: errors.New("odo build failed")}}
+	entry := schema.Schema{Name: "go"}
+	version := schema.Version{Version: "1.0.0"}
+
+	if err := runTesters(testers, report, true, "/stacks/go/1.0.0", entry, version); err == nil {
+		t.Fatal("expected an error when strict and a tester fails")
+	}
+}
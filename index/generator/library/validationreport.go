@@ -0,0 +1,140 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// IssueSeverity classifies how serious a ValidationIssue is: whether it already fails index
+// generation on its own, or is one of the findings that generation only prints to the console
+// and otherwise ignores.
+type IssueSeverity string
+
+const (
+	SeverityError   IssueSeverity = "error"
+	SeverityWarning IssueSeverity = "warning"
+)
+
+// ValidationIssue is a single machine-readable validation finding, so registry CI pipelines can
+// annotate a pull request at the right file and line instead of scraping console output.
+type ValidationIssue struct {
+	// Stack is the name of the stack or sample the issue was found in.
+	Stack string `json:"stack"`
+	// Version is the specific version the issue was found in, or "" if the issue applies to the
+	// stack as a whole (e.g. a missing provider, which is recorded once per component).
+	Version string `json:"version,omitempty"`
+	// Code is the ValidationCode of the underlying error, if it implements Coder.
+	Code ValidationCode `json:"code,omitempty"`
+	// Severity is SeverityError or SeverityWarning.
+	Severity IssueSeverity `json:"severity"`
+	// Message is the underlying error's human-readable text.
+	Message string `json:"message"`
+	// Path is the resource file path the issue references, if the underlying error implements
+	// Pather.
+	Path string `json:"path,omitempty"`
+}
+
+// TestResult records the outcome of one StackTester run against one stack version, so a
+// verification failure shows up in the same report as any other validation finding instead of
+// only in console output.
+type TestResult struct {
+	Stack   string `json:"stack"`
+	Version string `json:"version"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// ValidationReport collects every ValidationIssue found while validating or generating a
+// registry index, so the whole run's findings can be inspected or serialized as one unit instead
+// of only being printed to the console as generation proceeds.
+type ValidationReport struct {
+	Issues []ValidationIssue `json:"issues"`
+	// TestResults holds the outcome of every ValidationOptions.Testers run, in the order they ran.
+	TestResults []TestResult `json:"testResults,omitempty"`
+}
+
+// record appends an issue built from err to r. It is a no-op if r is nil (so ValidationOptions.
+// Report can be left unset by callers that don't want a report) or err is nil.
+func (r *ValidationReport) record(stack, version string, severity IssueSeverity, err error) {
+	if r == nil || err == nil {
+		return
+	}
+	issue := ValidationIssue{Stack: stack, Version: version, Severity: severity, Message: err.Error()}
+	if coder, ok := err.(Coder); ok {
+		issue.Code = coder.Code()
+	}
+	if pather, ok := err.(Pather); ok {
+		issue.Path = pather.Path()
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// recordTest appends a TestResult built from err to r. It is a no-op if r is nil, so
+// ValidationOptions.Report can be left unset by callers that don't want a report.
+func (r *ValidationReport) recordTest(stack, version string, err error) {
+	if r == nil {
+		return
+	}
+	result := TestResult{Stack: stack, Version: version, Passed: err == nil}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	r.TestResults = append(r.TestResults, result)
+}
+
+// HasFailedTests reports whether r contains at least one TestResult that didn't pass.
+func (r *ValidationReport) HasFailedTests() bool {
+	if r == nil {
+		return false
+	}
+	for _, result := range r.TestResults {
+		if !result.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+// HasErrors reports whether r contains at least one issue at SeverityError.
+func (r *ValidationReport) HasErrors() bool {
+	if r == nil {
+		return false
+	}
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes r as indented JSON to reportFilePath, matching CreateIndexFile's formatting.
+func (r *ValidationReport) WriteJSON(reportFilePath string) error {
+	if r == nil {
+		r = &ValidationReport{}
+	}
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation report: %v", err)
+	}
+	if err := ioutil.WriteFile(reportFilePath, bytes, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", reportFilePath, err)
+	}
+	return nil
+}
+
+// ValidateRegistry runs the same validation stages as GenerateIndexStructWithOptions over
+// registryDirPath, without writing an index file, and returns every finding as a single
+// ValidationReport instead of leaving the console as the only place non-fatal warnings show up.
+// A fatal error that would abort GenerateIndexStructWithOptions (e.g. an unparseable devfile) is
+// still returned as the error return value; the report only covers the non-fatal findings that
+// generation would otherwise only print.
+func ValidateRegistry(registryDirPath string, opts ValidationOptions) (*ValidationReport, error) {
+	report := &ValidationReport{}
+	opts.Report = report
+	if _, _, err := GenerateIndexStructWithOptions(registryDirPath, opts); err != nil {
+		return report, err
+	}
+	return report, nil
+}
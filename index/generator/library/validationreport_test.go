@@ -0,0 +1,70 @@
+package library
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidationReportRecord(t *testing.T) {
+	var report *ValidationReport
+
+	// Recording on a nil report must not panic, so callers can leave ValidationOptions.Report
+	// unset without special-casing every call site.
+	report.record("my-stack", "1.0.0", SeverityWarning, &MissingProviderError{devfile: "my-stack"})
+	if report != nil {
+		t.Fatalf("expected report to remain nil, got %v", report)
+	}
+
+	report = &ValidationReport{}
+	report.record("my-stack", "", SeverityWarning, nil)
+	if len(report.Issues) != 0 {
+		t.Fatalf("expected recording a nil error to be a no-op, got %v", report.Issues)
+	}
+
+	report.record("my-stack", "1.0.0", SeverityWarning, &WindowsInvalidPathError{devfile: "my-stack", path: "CON.yaml"})
+	if len(report.Issues) != 1 {
+		t.Fatalf("expected 1 issue, got %d", len(report.Issues))
+	}
+	issue := report.Issues[0]
+	if issue.Stack != "my-stack" || issue.Version != "1.0.0" {
+		t.Errorf("unexpected stack/version: %+v", issue)
+	}
+	if issue.Code != CodeWindowsInvalidPath {
+		t.Errorf("expected code %s, got %s", CodeWindowsInvalidPath, issue.Code)
+	}
+	if issue.Path != "CON.yaml" {
+		t.Errorf("expected path CON.yaml, got %q", issue.Path)
+	}
+	if report.HasErrors() {
+		t.Error("expected HasErrors to be false when every issue is a warning")
+	}
+
+	report.record("my-stack", "1.0.0", SeverityError, &MissingArchError{devfile: "my-stack"})
+	if !report.HasErrors() {
+		t.Error("expected HasErrors to be true once an error-severity issue is recorded")
+	}
+}
+
+func TestValidationReportWriteJSON(t *testing.T) {
+	report := &ValidationReport{}
+	report.record("my-stack", "1.0.0", SeverityWarning, &MissingProviderError{devfile: "my-stack"})
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	if err := report.WriteJSON(reportPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	var decoded ValidationReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if len(decoded.Issues) != 1 || decoded.Issues[0].Code != CodeMissingProvider {
+		t.Errorf("unexpected decoded report: %+v", decoded)
+	}
+}
@@ -0,0 +1,51 @@
+package library
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+type fakeIconValidator struct{}
+
+func (fakeIconValidator) Validate(indexComponent schema.Schema, componentType schema.DevfileType) []error {
+	if indexComponent.Icon == "" {
+		return []error{fmt.Errorf("%s: icon is mandatory", indexComponent.Name)}
+	}
+	return nil
+}
+
+func TestRegisterValidator(t *testing.T) {
+	validatorsMu.Lock()
+	saved := validators
+	validators = nil
+	validatorsMu.Unlock()
+	defer func() {
+		validatorsMu.Lock()
+		validators = saved
+		validatorsMu.Unlock()
+	}()
+
+	RegisterValidator(fakeIconValidator{})
+
+	validComponent := schema.Schema{
+		Name:          "with-icon",
+		Icon:          "icon.svg",
+		Provider:      "Red Hat",
+		SupportUrl:    "https://example.com",
+		Architectures: []string{"amd64"},
+		Versions: []schema.Version{
+			{Version: "1.0.0", SchemaVersion: "2.2.0", Default: true, Links: map[string]string{"self": "x"}, Resources: []string{"devfile.yaml"}},
+		},
+	}
+	if err := validateIndexComponent(validComponent, schema.StackDevfileType); err != nil {
+		t.Errorf("expected nil error for a component with an icon, got %v", err)
+	}
+
+	invalidComponent := validComponent
+	invalidComponent.Icon = ""
+	if err := validateIndexComponent(invalidComponent, schema.StackDevfileType); err == nil {
+		t.Error("expected an error for a component missing its icon, got nil")
+	}
+}
@@ -0,0 +1,47 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTempDirUnderWorkspace(t *testing.T) {
+	dir, err := newTempDir("workspace-test")
+	assert.NoError(t, err)
+	defer CleanupWorkspace()
+
+	_, err = os.Stat(dir)
+	assert.NoError(t, err)
+
+	parent := filepath.Dir(dir)
+	assert.True(t, strings.HasPrefix(filepath.Base(parent), workspaceDirPrefix))
+
+	CleanupWorkspace()
+	_, err = os.Stat(parent)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSweepOrphanWorkspacesRemovesOnlyStaleOnes(t *testing.T) {
+	staleDir, err := ioutil.TempDir("", workspaceDirPrefix)
+	assert.NoError(t, err)
+	staleTime := time.Now().Add(-2 * orphanWorkspaceMaxAge)
+	assert.NoError(t, os.Chtimes(staleDir, staleTime, staleTime))
+
+	freshDir, err := ioutil.TempDir("", workspaceDirPrefix)
+	assert.NoError(t, err)
+	defer os.RemoveAll(freshDir)
+
+	sweepOrphanWorkspaces()
+
+	_, err = os.Stat(staleDir)
+	assert.True(t, os.IsNotExist(err), "expected stale workspace directory to be swept")
+
+	_, err = os.Stat(freshDir)
+	assert.NoError(t, err, "expected fresh workspace directory to be left alone")
+}
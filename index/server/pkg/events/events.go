@@ -0,0 +1,99 @@
+// Package events defines the index server's telemetry event payloads as typed structs instead of
+// loosely typed property maps, so a payload's shape is checked at compile time and downstream
+// analytics consumers can rely on SchemaVersion to know how to decode a given event instead of
+// guessing at which fields a hand-built map happened to include.
+package events
+
+import "gopkg.in/segmentio/analytics-go.v3"
+
+// SchemaVersion identifies the shape of every event in this package. Bump it, and start a new
+// typed struct alongside the old one instead of changing an existing struct's fields, whenever a
+// change would otherwise break an analytics consumer relying on the previous shape.
+const SchemaVersion = "1"
+
+// StackPulled records a client pulling a stack's OCI content (a devfile, starter project, or
+// other packaged resource), corresponding to the index server's "download devfile" telemetry
+// event.
+type StackPulled struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Name          string `json:"name"`
+	Registry      string `json:"registry"`
+	Client        string `json:"client"`
+}
+
+// NewStackPulled builds a StackPulled event for the current SchemaVersion.
+func NewStackPulled(name, registry, client string) StackPulled {
+	return StackPulled{SchemaVersion: SchemaVersion, Name: name, Registry: registry, Client: client}
+}
+
+// Properties converts e to analytics.Properties for use as a segment Track event's payload.
+func (e StackPulled) Properties() analytics.Properties {
+	return analytics.NewProperties().
+		Set("schemaVersion", e.SchemaVersion).
+		Set("name", e.Name).
+		Set("registry", e.Registry).
+		Set("client", e.Client)
+}
+
+// IndexViewed records a client viewing the registry index, either the whole catalog (Name == "")
+// or a single stack's index entry (Name set), corresponding to the index server's "list devfile"
+// and "view devfile" telemetry events.
+type IndexViewed struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Name          string `json:"name,omitempty"`
+	Type          string `json:"type"`
+	Registry      string `json:"registry"`
+	Client        string `json:"client"`
+}
+
+// NewIndexViewed builds an IndexViewed event for the current SchemaVersion.
+func NewIndexViewed(name, indexType, registry, client string) IndexViewed {
+	return IndexViewed{SchemaVersion: SchemaVersion, Name: name, Type: indexType, Registry: registry, Client: client}
+}
+
+// Properties converts e to analytics.Properties for use as a segment Track event's payload.
+func (e IndexViewed) Properties() analytics.Properties {
+	props := analytics.NewProperties().
+		Set("schemaVersion", e.SchemaVersion).
+		Set("type", e.Type).
+		Set("registry", e.Registry).
+		Set("client", e.Client)
+	if e.Name != "" {
+		props.Set("name", e.Name)
+	}
+	return props
+}
+
+// SearchPerformed records a client searching the registry index via the "search" query
+// parameter, so search usage and yield can be tracked separately from a plain index view.
+type SearchPerformed struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Query         string `json:"query"`
+	Type          string `json:"type"`
+	Registry      string `json:"registry"`
+	Client        string `json:"client"`
+	ResultCount   int    `json:"resultCount"`
+}
+
+// NewSearchPerformed builds a SearchPerformed event for the current SchemaVersion.
+func NewSearchPerformed(query, indexType, registry, client string, resultCount int) SearchPerformed {
+	return SearchPerformed{
+		SchemaVersion: SchemaVersion,
+		Query:         query,
+		Type:          indexType,
+		Registry:      registry,
+		Client:        client,
+		ResultCount:   resultCount,
+	}
+}
+
+// Properties converts e to analytics.Properties for use as a segment Track event's payload.
+func (e SearchPerformed) Properties() analytics.Properties {
+	return analytics.NewProperties().
+		Set("schemaVersion", e.SchemaVersion).
+		Set("query", e.Query).
+		Set("type", e.Type).
+		Set("registry", e.Registry).
+		Set("client", e.Client).
+		Set("resultCount", e.ResultCount)
+}
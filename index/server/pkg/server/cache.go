@@ -0,0 +1,144 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pullResultCache caches the raw bytes pullResourceFromRegistry pulls from the backing OCI
+// registry, keyed by a version's "self" link (e.g. "devfile-catalog/go:1.1.0") and resource name
+// (e.g. "devfile.yaml", "logo.svg"), so a hot stack isn't re-pulled from the registry on every
+// request for it. Populated and consulted only when pullCacheEnabled is set.
+type pullResultCache struct {
+	mu      sync.RWMutex
+	entries map[string][]byte
+}
+
+func newPullResultCache() *pullResultCache {
+	return &pullResultCache{entries: make(map[string][]byte)}
+}
+
+func pullCacheKey(versionLink, resource string) string {
+	return versionLink + "|" + resource
+}
+
+func (c *pullResultCache) get(versionLink, resource string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	data, ok := c.entries[pullCacheKey(versionLink, resource)]
+	return data, ok
+}
+
+func (c *pullResultCache) put(versionLink, resource string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[pullCacheKey(versionLink, resource)] = data
+}
+
+// purgeVersion removes every cached resource belonging to versionLink (e.g. a stack's devfile,
+// icon, and signature, all cached under "devfile-catalog/go:1.1.0"), or the entire cache if
+// versionLink is "", and returns how many entries were removed.
+func (c *pullResultCache) purgeVersion(versionLink string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if versionLink == "" {
+		count := len(c.entries)
+		c.entries = make(map[string][]byte)
+		return count
+	}
+	prefix := versionLink + "|"
+	count := 0
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			count++
+		}
+	}
+	return count
+}
+
+// purgeResource removes a single cached resource and reports whether it was present.
+func (c *pullResultCache) purgeResource(versionLink, resource string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := pullCacheKey(versionLink, resource)
+	if _, ok := c.entries[key]; !ok {
+		return false
+	}
+	delete(c.entries, key)
+	return true
+}
+
+// pullCache is the process-wide instance consulted by pullResourceFromRegistry and invalidated by
+// servePurgeCache.
+var pullCache = newPullResultCache()
+
+// stackVersionLink returns the same "self" link format parseStackDevfile assigns a version (e.g.
+// "devfile-catalog/go:1.1.0"), so a purge request naming a stack and version addresses the same
+// cache entries pullResourceFromRegistry populated for it.
+func stackVersionLink(stack, version string) string {
+	return fmt.Sprintf("devfile-catalog/%s:%s", stack, version)
+}
+
+// adminAuthMiddleware requires a "Bearer <adminAPIToken>" Authorization header on the endpoints it
+// guards, when adminAPIToken is configured. It's applied only to the cache purge endpoints below,
+// which are destructive, rather than retrofitted onto the rest of /admin, which is either
+// read-only or, for /admin/reload, only re-reads content this server already trusts. It's a no-op
+// when adminAPIToken is unset, matching this server's convention of new controls being opt-in.
+func adminAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminAPIToken == "" {
+			c.Next()
+			return
+		}
+		if !constantTimeEquals(c.GetHeader("Authorization"), "Bearer "+adminAPIToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"status": "missing or invalid admin API token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// constantTimeEquals reports whether a and b are equal, taking time independent of where (or
+// whether) they first differ, so a client can't use response timing to guess the admin token one
+// byte at a time. subtle.ConstantTimeCompare itself isn't timing-safe for mismatched lengths, so
+// the length check is done up front.
+func constantTimeEquals(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// servePurgeCache serves `DELETE /admin/cache`, `DELETE /admin/cache/:stack/:version`, and
+// `DELETE /admin/cache/:stack/:version/:resource`, letting an operator invalidate one bad artifact,
+// or everything cached for one stack version, without the cold-cache stampede a full server
+// restart would cause. Purging an entry that was never cached, or purging with the cache disabled,
+// still returns 200: the caller's desired end state (that content no longer being cached) already
+// holds either way.
+func servePurgeCache(c *gin.Context) {
+	stack := c.Param("stack")
+	version := c.Param("version")
+	resource := c.Param("resource")
+
+	if stack == "" {
+		purged := pullCache.purgeVersion("")
+		c.JSON(http.StatusOK, gin.H{"status": "purged entire pull cache", "purged": purged})
+		return
+	}
+
+	versionLink := stackVersionLink(stack, version)
+	if resource == "" {
+		purged := pullCache.purgeVersion(versionLink)
+		c.JSON(http.StatusOK, gin.H{"status": fmt.Sprintf("purged cached resources for %s:%s", stack, version), "purged": purged})
+		return
+	}
+
+	found := pullCache.purgeResource(versionLink, resource)
+	c.JSON(http.StatusOK, gin.H{"status": fmt.Sprintf("purged cached resource %s for %s:%s", resource, stack, version), "found": found})
+}
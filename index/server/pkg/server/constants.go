@@ -1,7 +1,10 @@
 package server
 
 import (
+	"crypto/ed25519"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/devfile/registry-support/index/server/pkg/util"
 )
@@ -38,4 +41,177 @@ var (
 	stackBase64IndexPath  = os.Getenv("DEVFILE_STACK_BASE64_INDEX")
 	enableTelemetry       = util.GetOptionalEnv("ENABLE_TELEMETRY", false).(bool)
 	registry              = util.GetOptionalEnv("REGISTRY_NAME", "devfile-registry")
+	quotaMaxStacks        = util.GetOptionalEnv("REGISTRY_QUOTA_MAX_STACKS", 0).(int)
+	quotaMaxBytes         = util.GetOptionalEnv("REGISTRY_QUOTA_MAX_BYTES", 0).(int)
+	federatedUpstreams    = util.GetOptionalEnv("FEDERATED_UPSTREAMS", "").(string)
+
+	// popularStacksDecayHalfLife controls how quickly a stack's pull count for the /v2index/popular
+	// ranking decays, so recent pulls are weighted more heavily than pulls from long ago.
+	popularStacksDecayHalfLife = time.Duration(util.GetOptionalEnv("REGISTRY_POPULAR_DECAY_HALFLIFE_SECONDS", 7*24*3600).(int)) * time.Second
+
+	// Request shadowing/mirroring, for validating a new registry deployment against production
+	// traffic before cutover. shadowSamplePercent is the percentage (0-100) of GET/HEAD requests
+	// mirrored to shadowRegistryURL; mirroring is disabled unless shadowRegistryURL is set.
+	shadowRegistryURL   = os.Getenv("SHADOW_REGISTRY_URL")
+	shadowSamplePercent = util.GetOptionalEnv("SHADOW_SAMPLE_PERCENT", 0).(int)
+
+	// Canary index support, so an operator can stage a risky catalog change behind a second set
+	// of index files and watch it against a slice of real traffic before rolling it out to
+	// everyone. A request is routed to the canary index when its canaryHeader is present with any
+	// non-empty value, or otherwise with probability canaryPercent (0-100). Canary index paths
+	// default to unset, which disables the feature regardless of canaryPercent/canaryHeader.
+	canaryIndexPath       = os.Getenv("DEVFILE_CANARY_INDEX")
+	canarySampleIndexPath = os.Getenv("DEVFILE_CANARY_SAMPLE_INDEX")
+	canaryStackIndexPath  = os.Getenv("DEVFILE_CANARY_STACK_INDEX")
+	canaryPercent         = util.GetOptionalEnv("CANARY_PERCENT", 0).(int)
+	canaryHeader          = util.GetOptionalEnv("CANARY_HEADER", "X-Devfile-Registry-Canary").(string)
+
+	// Access log sampling, so a high-traffic public registry can sample down its noisiest
+	// endpoint class (OCI blob downloads) without losing full visibility into low-volume admin
+	// endpoints. Values are percentages (0-100); 100 (the default for all three) logs every
+	// request, matching gin.Default()'s prior unconditional logging behavior.
+	indexAccessLogSamplePercent = util.GetOptionalEnv("ACCESS_LOG_SAMPLE_PERCENT_INDEX", 100).(int)
+	blobAccessLogSamplePercent  = util.GetOptionalEnv("ACCESS_LOG_SAMPLE_PERCENT_BLOB", 100).(int)
+	adminAccessLogSamplePercent = util.GetOptionalEnv("ACCESS_LOG_SAMPLE_PERCENT_ADMIN", 100).(int)
+
+	// HTTP/2 and keep-alive tuning. Defaults are chosen to tolerate IDE clients that open many
+	// small parallel devfile/icon requests, which otherwise queue up behind net/http's defaults.
+	enableHTTP2               = util.GetOptionalEnv("SERVER_ENABLE_HTTP2", true).(bool)
+	http2MaxConcurrentStreams = util.GetOptionalEnv("SERVER_HTTP2_MAX_CONCURRENT_STREAMS", 250).(int)
+	readTimeoutSeconds        = util.GetOptionalEnv("SERVER_READ_TIMEOUT_SECONDS", 30).(int)
+	writeTimeoutSeconds       = util.GetOptionalEnv("SERVER_WRITE_TIMEOUT_SECONDS", 30).(int)
+	idleTimeoutSeconds        = util.GetOptionalEnv("SERVER_IDLE_TIMEOUT_SECONDS", 120).(int)
+
+	// Request size limits, so a malicious or misbehaving client can't exhaust server memory with
+	// an oversized header block, request body, or query string. maxHeaderBytes is enforced by
+	// net/http itself (rejecting with 431 before a handler ever runs); maxRequestBodyBytes and
+	// maxQueryStringBytes are enforced by requestLimitsMiddleware.
+	maxHeaderBytes      = util.GetOptionalEnv("SERVER_MAX_HEADER_BYTES", 1<<20).(int)
+	maxRequestBodyBytes = util.GetOptionalEnv("SERVER_MAX_REQUEST_BODY_BYTES", 1<<20).(int)
+	maxQueryStringBytes = util.GetOptionalEnv("SERVER_MAX_QUERY_STRING_BYTES", 2048).(int)
+
+	// stackRenames maps an old, no-longer-listed stack name to the name it was renamed to, so a
+	// request for the old name is redirected instead of breaking outright the moment a stack is
+	// renamed in the index. Configured as a comma-separated "old=new" list via STACK_RENAMES;
+	// unlike a version's Successor field (an author-declared recommendation shown to a client
+	// that already reached the old stack), this is an operator-configured alias that changes how
+	// the old name resolves at all.
+	stackRenames = parseStackRenames(os.Getenv("STACK_RENAMES"))
+
+	// auditTrailMaxRecords bounds how many pull records auditTrail retains in memory before
+	// evicting the oldest, so a long-running server's audit trail can't grow without bound. A
+	// platform team that needs history beyond this should export via /admin/audit on a schedule.
+	auditTrailMaxRecords = util.GetOptionalEnv("AUDIT_TRAIL_MAX_RECORDS", 100000).(int)
+
+	// enableArtifactValidation turns on a background pass that pulls each stack's default-version
+	// devfile artifact and checks that it still parses, quarantining any stack that fails rather
+	// than letting a client discover a broken artifact at pull time. Off by default since it adds
+	// an OCI pull per stack on every startup and reload.
+	enableArtifactValidation = util.GetOptionalEnv("ENABLE_ARTIFACT_VALIDATION", false).(bool)
+
+	// Artifact signature verification, so a compromised backing OCI registry can't silently serve
+	// tampered stack content. signatureEnforcement is "off" (the default), "warn" (verify and log
+	// but still serve), or "enforce" (refuse to serve on a missing or invalid signature).
+	// signaturePublicKeyPath must point at a PEM-encoded ed25519 public key when enforcement isn't
+	// off; signaturePublicKey/signaturePublicKeyErr are loaded from it once at startup.
+	signatureEnforcement                      = parseSignatureEnforcementLevel(util.GetOptionalEnv("SIGNATURE_ENFORCEMENT", "off").(string))
+	signaturePublicKeyPath                    = os.Getenv("SIGNATURE_PUBLIC_KEY_PATH")
+	signaturePublicKey, signaturePublicKeyErr = loadSignaturePublicKeyIfConfigured()
+
+	// Opt-in index response signing, so a client behind a caching proxy can verify the catalog it
+	// received end-to-end instead of only trusting the proxy's TLS session. Off by default;
+	// indexSigningPrivateKeyPath must point at a PEM-encoded ed25519 private key when enabled.
+	// indexSigningPrivateKey/indexSigningPrivateKeyErr are loaded from it once at startup.
+	indexSigningEnabled                               = util.GetOptionalEnv("INDEX_SIGNING_ENABLED", false).(bool)
+	indexSigningPrivateKeyPath                        = os.Getenv("INDEX_SIGNING_PRIVATE_KEY_PATH")
+	indexSigningPrivateKey, indexSigningPrivateKeyErr = loadIndexSigningPrivateKeyIfConfigured()
+
+	// pullCacheEnabled turns on an in-memory cache of resources (devfiles, icons, signatures, ...)
+	// pulled from the backing OCI registry, so a hot stack isn't re-pulled from the registry on
+	// every request for it. Off by default, matching this server's convention of new caching/perf
+	// behavior being opt-in. See pkg/server/cache.go for the cache and its admin purge endpoints.
+	pullCacheEnabled = util.GetOptionalEnv("PULL_CACHE_ENABLED", false).(bool)
+
+	// adminAPIToken, if set, is required as a "Bearer <adminAPIToken>" Authorization header on the
+	// cache purge endpoints (see adminAuthMiddleware), since purging cached content is destructive
+	// enough to warrant authentication unlike the rest of /admin, which is read-only or (for
+	// /admin/reload) only re-reads content this server already trusts. Left unset (the default)
+	// leaves purge open, matching the rest of /admin, for operators who already restrict admin
+	// access at the network layer.
+	adminAPIToken = os.Getenv("ADMIN_API_TOKEN")
+
+	// pullCacheWarmupStacks lists stacks pre-fetched into pullCache on startup, so the first real
+	// request for a popular stack right after a deployment doesn't pay a cold pull. Comma-separated
+	// stack names, optionally pinned to a version with "name:version" (e.g. "go,java-maven:1.1.0");
+	// an unpinned entry warms that stack's default version. Only consulted when pullCacheEnabled is
+	// set; empty (the default) warms nothing.
+	pullCacheWarmupStacks = os.Getenv("PULL_CACHE_WARMUP_STACKS")
+
+	// tracingEnabled turns on per-request trace IDs (see pkg/server/tracing.go): a trace ID is
+	// read from an inbound "traceparent" header or generated, echoed back as X-Trace-Id, and
+	// attached as an OpenMetrics exemplar to the pull and index/search latency histograms, so an
+	// operator can jump from a latency spike in Grafana straight to the request that caused it.
+	// Off by default, matching this server's other opt-in observability behavior.
+	tracingEnabled = util.GetOptionalEnv("ENABLE_TRACING", false).(bool)
+
+	// searchContentIndexingEnabled turns on indexing each stack's default-version devfile.yaml text
+	// (in memory, see pkg/server/contentsearch.go) so a "search" query can match a stack that
+	// mentions a term even when its tags are sparse. Off by default: building and holding this
+	// index costs an OCI pull and a chunk of memory per stack, on top of the index metadata this
+	// server already keeps resident. Stacks don't currently package a README as an indexed
+	// resource, so README content isn't covered.
+	searchContentIndexingEnabled = util.GetOptionalEnv("SEARCH_INDEX_CONTENT", false).(bool)
+)
+
+// loadIndexSigningPrivateKeyIfConfigured loads indexSigningPrivateKeyPath, if set, into memory once
+// at startup rather than on every request. It's a no-op returning (nil, nil) when indexSigningEnabled
+// is false, so a registry that never turns on signing never pays for the read or reports an error
+// for an unset path.
+func loadIndexSigningPrivateKeyIfConfigured() (ed25519.PrivateKey, error) {
+	if !indexSigningEnabled {
+		return nil, nil
+	}
+	if indexSigningPrivateKeyPath == "" {
+		return nil, fmt.Errorf("INDEX_SIGNING_ENABLED is true but INDEX_SIGNING_PRIVATE_KEY_PATH is not set")
+	}
+	return loadSignaturePrivateKey(indexSigningPrivateKeyPath)
+}
+
+// loadSignaturePublicKeyIfConfigured loads signaturePublicKeyPath, if set, into memory once at
+// startup rather than on every request. It's a no-op returning (nil, nil) when signatureEnforcement
+// is off, so a registry that never turns on verification never pays for the read or reports an
+// error for an unset path.
+func loadSignaturePublicKeyIfConfigured() (ed25519.PublicKey, error) {
+	if signatureEnforcement == signatureEnforcementOff {
+		return nil, nil
+	}
+	if signaturePublicKeyPath == "" {
+		return nil, fmt.Errorf("SIGNATURE_ENFORCEMENT is %q but SIGNATURE_PUBLIC_KEY_PATH is not set", signatureEnforcement)
+	}
+	return loadSignaturePublicKey(signaturePublicKeyPath)
+}
+
+// Atomically-swappable in-memory snapshots of the index files, populated in ServeRegistry.
+var (
+	allIndexStore    *util.IndexStore
+	sampleIndexStore *util.IndexStore
+	stackIndexStore  *util.IndexStore
+
+	// Canary counterparts of the stores above; each stays nil until its *IndexPath env var is
+	// set, so canaryStore lookups on an unconfigured index type are a plain nil check.
+	canaryAllIndexStore    *util.IndexStore
+	canarySampleIndexStore *util.IndexStore
+	canaryStackIndexStore  *util.IndexStore
+
+	// quarantineStore holds the result of the most recent artifact validation pass, exposed via
+	// the /admin/quarantine endpoint.
+	quarantineStore = util.NewQuarantineStore()
 )
+
+// popularityTracker records pulls (both OCI blob downloads and devfile fetches) so that
+// /v2index/popular can rank stacks without an external analytics round-trip.
+var popularityTracker = util.NewPopularityTracker(popularStacksDecayHalfLife)
+
+// auditTrail records per-stack pull events for the /admin/audit export, so internal platform
+// teams can pull chargeback and adoption reports without needing their own telemetry pipeline.
+var auditTrail = util.NewAuditTrail(auditTrailMaxRecords)
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+
+	"github.com/devfile/registry-support/index/server/pkg/util"
+)
+
+// contentSearchIndex holds each stack's default-version devfile.yaml text, lowercased, keyed by
+// stack name. It's only populated when searchContentIndexingEnabled is set; buildContentSearchIndex
+// fills it in the background after startup, the same way warmupPullCache fills pullCache.
+var (
+	contentSearchIndexMu sync.RWMutex
+	contentSearchIndex   = map[string]string{}
+)
+
+// buildContentSearchIndex pulls every stack's default-version devfile.yaml and stores its
+// lowercased text in contentSearchIndex, so filterBySearch can match a query against it. It's
+// started as its own goroutine after the index stores are already loaded, so a large registry
+// never delays startup: index requests are answered immediately, with content indexing filling in
+// behind them. A no-op unless searchContentIndexingEnabled is set.
+func buildContentSearchIndex() {
+	if !searchContentIndexingEnabled {
+		return
+	}
+
+	for _, stack := range allIndexStore.Snapshot() {
+		versionComponent, err := findWarmupVersion([]indexSchema.Schema{stack}, stack.Name, "")
+		if err != nil {
+			log.Printf("content search indexing: %v", err)
+			continue
+		}
+
+		devfile, err := pullStackFromRegistry(versionComponent)
+		if err != nil {
+			log.Printf("content search indexing: failed to pull %s: %v", stack.Name, err)
+			continue
+		}
+
+		contentSearchIndexMu.Lock()
+		contentSearchIndex[stack.Name] = strings.ToLower(string(devfile))
+		contentSearchIndexMu.Unlock()
+	}
+	log.Println("content search indexing: finished")
+}
+
+// filterBySearch filters index down to the entries util.FilterDevfileSearch already matches,
+// plus, when searchContentIndexingEnabled is set, any entry whose indexed devfile.yaml content
+// contains query even though its metadata doesn't. Content matches are appended after the
+// metadata matches, so callers that care about relevance ordering should treat metadata matches
+// as the stronger signal.
+func filterBySearch(index []indexSchema.Schema, query string) []indexSchema.Schema {
+	matched := util.FilterDevfileSearch(index, query)
+	if query == "" || !searchContentIndexingEnabled {
+		return matched
+	}
+
+	alreadyMatched := make(map[string]bool, len(matched))
+	for _, devfile := range matched {
+		alreadyMatched[devfile.Name] = true
+	}
+
+	lowerQuery := strings.ToLower(query)
+	contentSearchIndexMu.RLock()
+	defer contentSearchIndexMu.RUnlock()
+	for _, devfile := range index {
+		if alreadyMatched[devfile.Name] {
+			continue
+		}
+		if content, ok := contentSearchIndex[devfile.Name]; ok && strings.Contains(content, lowerQuery) {
+			matched = append(matched, devfile)
+		}
+	}
+	return matched
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	stdbytes "bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,8 +11,12 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/events"
 	"github.com/devfile/registry-support/index/server/pkg/util"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
@@ -34,8 +39,9 @@ func serveRootEndpoint(c *gin.Context) {
 func serveDevfileIndex(c *gin.Context) {
 	// Start the counter for the request
 	var status string
+	traceID := traceIDFromContext(c)
 	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-		getIndexLatency.WithLabelValues(status).Observe(v)
+		observeWithExemplar(getIndexLatency.WithLabelValues(status), v, traceID)
 	}))
 	defer func() {
 		timer.ObserveDuration()
@@ -62,6 +68,59 @@ func serveHealthCheck(c *gin.Context) {
 	})
 }
 
+// serveReadyz serves `/readyz`, reporting whether each in-memory index snapshot is fresh or
+// stale. A store falls stale when a Reload fails; it keeps serving its last good snapshot rather
+// than going empty, so staleness is reported here (and in metrics) instead of as a hard failure.
+func serveReadyz(c *gin.Context) {
+	catalog := catalogForRequest(c)
+	stores := gin.H{
+		"all":    indexStoreStatusJSON(catalog.allIndexStore),
+		"sample": indexStoreStatusJSON(catalog.sampleIndexStore),
+		"stack":  indexStoreStatusJSON(catalog.stackIndexStore),
+	}
+
+	// Ready means "able to serve a catalog", which is still true while stale: a failed reload
+	// keeps the previous snapshot in place rather than leaving the store empty.
+	stale := catalog.allIndexStore.Status().Stale || catalog.sampleIndexStore.Status().Stale || catalog.stackIndexStore.Status().Stale
+	c.JSON(http.StatusOK, gin.H{
+		"stale":  stale,
+		"stores": stores,
+	})
+}
+
+func indexStoreStatusJSON(store *util.IndexStore) gin.H {
+	status := store.Status()
+	return gin.H{
+		"stale":         status.Stale,
+		"lastSuccess":   status.LastSuccess,
+		"lastError":     status.LastError,
+		"lastErrorTime": status.LastErrorTime,
+	}
+}
+
+// apiVersion is the current stable API version served under /api/v1
+const apiVersion = "v1"
+
+// indexSchemaVersion describes the schema version of the index this server produces, so clients
+// can feature-detect before relying on newer index fields
+const indexSchemaVersion = "2.0.0"
+
+// serveAPIMeta serves endpoint `/api/v1/meta`, describing the server version, supported features,
+// and index schema version so that clients can feature-detect instead of hardcoding assumptions.
+func serveAPIMeta(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"apiVersion":         apiVersion,
+		"indexSchemaVersion": indexSchemaVersion,
+		"features": gin.H{
+			"search":      true,
+			"federation":  federatedUpstreams != "",
+			"auth":        false,
+			"quota":       true,
+			"deprecation": true,
+		},
+	})
+}
+
 // serveDevfile returns the devfile content
 func serveDevfile(c *gin.Context) {
 	name := c.Param("name")
@@ -89,6 +148,7 @@ func serveDevfile(c *gin.Context) {
 		if devfileIndex.Name == name {
 			var sampleDevfilePath string
 			var bytes []byte
+			modTime := time.Now()
 			if devfileIndex.Versions == nil || len(devfileIndex.Versions) == 0 {
 				if devfileIndex.Type == indexSchema.SampleDevfileType {
 					sampleDevfilePath = path.Join(samplesPath, devfileIndex.Name, devfileName)
@@ -98,8 +158,12 @@ func serveDevfile(c *gin.Context) {
 					if !version.Default {
 						continue
 					}
+					util.SetDeprecationHeaders(c, version)
 					if devfileIndex.Type == indexSchema.StackDevfileType {
 						bytes, err = pullStackFromRegistry(version)
+						if err == nil {
+							err = verifyStackVersionSignature(version, bytes)
+						}
 					} else {
 						// Retrieve the sample devfile stored under /registry/samples/<devfile>
 						sampleDevfilePath = path.Join(samplesPath, devfileIndex.Name, version.Version, devfileName)
@@ -107,8 +171,18 @@ func serveDevfile(c *gin.Context) {
 					break
 				}
 			}
+			if sampleDevfilePath == "" && err != nil {
+				log.Print(err.Error())
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":  err.Error(),
+					"status": fmt.Sprintf("failed to pull the devfile of %s", name),
+				})
+				return
+			}
 			if sampleDevfilePath != "" {
-				if _, err = os.Stat(sampleDevfilePath); err == nil {
+				var info os.FileInfo
+				if info, err = os.Stat(sampleDevfilePath); err == nil {
+					modTime = info.ModTime()
 					bytes, err = ioutil.ReadFile(sampleDevfilePath)
 				}
 				if err != nil {
@@ -128,29 +202,260 @@ func serveDevfile(c *gin.Context) {
 				client := util.GetClient(c)
 
 				err := util.TrackEvent(analytics.Track{
-					Event:   eventTrackMap["view"],
-					UserId:  user,
-					Context: util.SetContext(c),
-					Properties: analytics.NewProperties().
-						Set("name", name).
-						Set("type", string(devfileIndex.Type)).
-						Set("registry", registry).
-						Set("client", client),
+					Event:      eventTrackMap["view"],
+					UserId:     user,
+					Context:    util.SetContext(c),
+					Properties: events.NewIndexViewed(name, string(devfileIndex.Type), registry.(string), client).Properties(),
 				})
 				if err != nil {
 					log.Println(err)
 				}
 			}
+			util.SetContentCacheHeaders(c, bytes, modTime)
 			c.Data(http.StatusOK, http.DetectContentType(bytes), bytes)
 			return
 		}
 	}
 
+	if newName, renamed := stackRenames[name]; renamed {
+		c.Redirect(http.StatusPermanentRedirect, strings.Replace(c.Request.URL.RequestURI(), "/devfiles/"+name, "/devfiles/"+newName, 1))
+		return
+	}
+
 	c.JSON(http.StatusNotFound, gin.H{
 		"status": fmt.Sprintf("the devfile of %s didn't exist", name),
 	})
 }
 
+// serveReloadIndex serves `/admin/reload`, re-reading the index files from disk and atomically
+// publishing them as the new in-memory snapshots, so a registry whose index files are refreshed
+// by an external process (e.g. a mounted volume update) can pick up the change without restarting.
+// A store whose reload fails keeps serving its last good snapshot and is reported stale via
+// /readyz and the index_store_stale metric, rather than aborting the reload of the other stores
+// or leaving the server without a valid catalog.
+func serveReloadIndex(c *gin.Context) {
+	var reloadErrors []string
+	stores := map[string]*util.IndexStore{"all": allIndexStore, "sample": sampleIndexStore, "stack": stackIndexStore}
+	for host, catalog := range virtualRegistries {
+		stores[host+":all"] = catalog.allIndexStore
+		stores[host+":sample"] = catalog.sampleIndexStore
+		stores[host+":stack"] = catalog.stackIndexStore
+	}
+	for name, store := range stores {
+		if err := store.Reload(); err != nil {
+			reloadErrors = append(reloadErrors, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	recordIndexStoreMetrics()
+
+	if len(reloadErrors) > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "one or more index stores failed to reload and are serving stale data",
+			"errors": reloadErrors,
+		})
+		return
+	}
+
+	if enableArtifactValidation {
+		go runArtifactValidation()
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "index reloaded"})
+}
+
+// serveQuarantinedEntries serves `/admin/quarantine`, reporting the stacks quarantined by the
+// most recent artifact validation pass (see runArtifactValidation). Empty if
+// ENABLE_ARTIFACT_VALIDATION is unset or no pass has completed yet.
+func serveQuarantinedEntries(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"quarantined": quarantineStore.Snapshot(),
+	})
+}
+
+// serveUpstreamsHealth serves `/admin/upstreams`, reporting the reachability, entry count, and
+// latency of each upstream registry configured via FEDERATED_UPSTREAMS, so federation operators
+// can see at a glance which upstreams are stale or failing. Each call performs a live check; there
+// is no persisted sync history yet.
+func serveUpstreamsHealth(c *gin.Context) {
+	if federatedUpstreams == "" {
+		c.JSON(http.StatusOK, gin.H{
+			"upstreams": []util.UpstreamStatus{},
+		})
+		return
+	}
+
+	upstreamURLs := strings.Split(federatedUpstreams, ",")
+	c.JSON(http.StatusOK, gin.H{
+		"upstreams": util.CheckUpstreams(upstreamURLs),
+	})
+}
+
+// serveStackMetadata serves `/v2index/:stack` and `/v2index/:stack/:version`, returning just the
+// index entry (or a single version of it) a client already knows the name of, so it doesn't have
+// to fetch and scan the entire index to resolve one stack.
+func serveStackMetadata(c *gin.Context) {
+	name := c.Param("stack")
+	requestedVersion := c.Param("version")
+
+	catalog := catalogForRequest(c)
+	index := catalog.allIndexStore.Snapshot()
+
+	for _, devfileIndex := range index {
+		if devfileIndex.Name != name {
+			continue
+		}
+
+		if requestedVersion == "" {
+			respondWithStackMetadata(c, catalog, devfileIndex, name)
+			return
+		}
+
+		for _, version := range devfileIndex.Versions {
+			if version.Version == requestedVersion {
+				devfileIndex.Versions = []indexSchema.Version{version}
+				respondWithStackMetadata(c, catalog, devfileIndex, name)
+				return
+			}
+		}
+
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": fmt.Sprintf("version %s of stack %s doesn't exist", requestedVersion, name),
+		})
+		return
+	}
+
+	if newName, renamed := stackRenames[name]; renamed {
+		c.Redirect(http.StatusPermanentRedirect, strings.Replace(c.Request.URL.RequestURI(), "/v2index/"+name, "/v2index/"+newName, 1))
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{
+		"status": fmt.Sprintf("the stack %s doesn't exist", name),
+	})
+}
+
+// respondWithStackMetadata serializes a single index entry and writes it with cache headers.
+func respondWithStackMetadata(c *gin.Context, catalog *registryCatalog, entry indexSchema.Schema, name string) {
+	bytes, err := json.MarshalIndent(&entry, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": fmt.Sprintf("failed to serialize the metadata of %s: %v", name, err),
+		})
+		return
+	}
+
+	modTime := time.Now()
+	if info, err := os.Stat(catalog.indexPath); err == nil {
+		modTime = info.ModTime()
+	}
+	util.SetContentCacheHeaders(c, bytes, modTime)
+
+	c.Data(http.StatusOK, http.DetectContentType(bytes), bytes)
+}
+
+// servePopularStacks serves `/v2index/popular`, returning up to `limit` (default 10) stack index
+// entries ranked by pull volume, with recent pulls weighted more heavily than old ones, so
+// registry-viewer and IDE quick-pick lists can surface trending stacks without doing their own
+// client-side analytics.
+func servePopularStacks(c *gin.Context) {
+	limit := 10
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status": fmt.Sprintf("invalid limit %q: must be a positive integer", raw),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	names := popularityTracker.Top(limit, time.Now())
+
+	index := catalogForRequest(c).stackIndexStore.Snapshot()
+	byName := make(map[string]indexSchema.Schema, len(index))
+	for _, devfileIndex := range index {
+		byName[devfileIndex.Name] = devfileIndex
+	}
+
+	popular := make([]indexSchema.Schema, 0, len(names))
+	for _, name := range names {
+		if devfileIndex, ok := byName[name]; ok {
+			popular = append(popular, devfileIndex)
+		}
+	}
+
+	c.JSON(http.StatusOK, popular)
+}
+
+// serveNamespaceQuota reports the current quota usage for a namespace, so shared registry
+// instances can be monitored for a single team monopolizing stack count or artifact bytes.
+func serveNamespaceQuota(c *gin.Context) {
+	namespace := c.Param("namespace")
+
+	usage := namespaceUsage(c, namespace)
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// namespaceQuotaCheckRequest is the body serveCheckNamespaceQuota expects, describing the
+// content a caller is about to add to a namespace so its quota can be checked before the
+// content is actually pushed.
+type namespaceQuotaCheckRequest struct {
+	// AddingStack is true when the push being checked would add a new stack to the namespace,
+	// as opposed to adding a version to a stack the namespace already owns.
+	AddingStack bool `json:"addingStack"`
+	// NewBytes is the size, in bytes, of the resources the push being checked would add.
+	NewBytes int64 `json:"newBytes"`
+}
+
+// serveCheckNamespaceQuota serves `POST /admin/quota/:namespace/check`, the enforcement half of
+// serveNamespaceQuota's reporting: since this server has no push/upload endpoint of its own
+// (stacks are published out-of-band by index/generator's PushIndexedArtifacts), a push pipeline
+// calls this first and aborts the push on a non-2xx response instead of the registry silently
+// accepting content past a namespace's quota.
+func serveCheckNamespaceQuota(c *gin.Context) {
+	namespace := c.Param("namespace")
+
+	var req namespaceQuotaCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+
+	usage := namespaceUsage(c, namespace)
+	quota := util.NamespaceQuota{MaxStacks: quotaMaxStacks, MaxBytes: int64(quotaMaxBytes)}
+
+	if err := util.CheckNamespaceQuota(usage, quota, req.AddingStack, req.NewBytes); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"status": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// namespaceUsage computes the current NamespaceQuota usage for namespace against the stack
+// index in scope for c, annotated with the configured limits.
+func namespaceUsage(c *gin.Context, namespace string) util.NamespaceUsage {
+	index := catalogForRequest(c).stackIndexStore.Snapshot()
+
+	usage := util.ComputeNamespaceUsage(index, namespace, resourceSize)
+	usage.MaxStacks = quotaMaxStacks
+	usage.MaxBytes = int64(quotaMaxBytes)
+	return usage
+}
+
+// resourceSize returns the on-disk size, in bytes, of the resources for a given stack version
+func resourceSize(stackName string, version indexSchema.Version) int64 {
+	var total int64
+	for _, resource := range version.Resources {
+		resourcePath := path.Join(stacksPath, stackName, version.Version, resource)
+		if info, err := os.Stat(resourcePath); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
 func serveUI(c *gin.Context) {
 	remote, err := url.Parse(scheme + "://" + viewerService + "/viewer/")
 	if err != nil {
@@ -172,30 +477,43 @@ func serveUI(c *gin.Context) {
 	proxy.ServeHTTP(c.Writer, c.Request)
 }
 
+// encodeIndentedJSON marshals v the same way json.MarshalIndent(v, "", "  ") would, but through a
+// streaming json.Encoder rather than json.Marshal's separate marshal-then-indent pass, for the
+// filtered and paginated index responses that can't use IndexStore's precomputed serialization.
+func encodeIndentedJSON(v interface{}) ([]byte, error) {
+	var buf stdbytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return stdbytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
 // buildIndexAPIResponse builds the response of the REST API of getting the devfile index
 func buildIndexAPIResponse(c *gin.Context) {
 
 	indexType := c.Param("type")
 	iconType := c.Query("icon")
 	archs := c.QueryArray("arch")
+	search := c.Query("search")
 
 	var bytes []byte
+	var err error
 	var responseIndexPath, responseBase64IndexPath string
+	var responseIndexStore *util.IndexStore
+	var searchResultCount int
 
 	// Sets Access-Control-Allow-Origin response header to allow cross origin requests
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	catalog := catalogForRequest(c)
+
 	// Load the appropriate index file name based on the devfile type
 	switch indexType {
-	case string(indexSchema.StackDevfileType):
-		responseIndexPath = stackIndexPath
-		responseBase64IndexPath = stackBase64IndexPath
-	case string(indexSchema.SampleDevfileType):
-		responseIndexPath = sampleIndexPath
-		responseBase64IndexPath = sampleBase64IndexPath
-	case "all":
-		responseIndexPath = indexPath
-		responseBase64IndexPath = base64IndexPath
+	case string(indexSchema.StackDevfileType), string(indexSchema.SampleDevfileType), "all":
+		responseIndexPath = catalog.path(indexType)
+		responseIndexStore = catalog.storeForRequest(c, indexType)
 	default:
 		c.JSON(http.StatusNotFound, gin.H{
 			"status": fmt.Sprintf("the devfile with %s type doesn't exist", indexType),
@@ -203,7 +521,27 @@ func buildIndexAPIResponse(c *gin.Context) {
 		return
 	}
 
+	// The base64-encoded icon cache is only wired up for the default catalog's fixed set of
+	// on-disk paths (base64IndexPath/sampleBase64IndexPath/stackBase64IndexPath); a virtual
+	// registry has no configured cache path to write to, so it doesn't support icon=base64.
+	if !catalog.isVirtual {
+		switch indexType {
+		case string(indexSchema.StackDevfileType):
+			responseBase64IndexPath = stackBase64IndexPath
+		case string(indexSchema.SampleDevfileType):
+			responseBase64IndexPath = sampleBase64IndexPath
+		case "all":
+			responseBase64IndexPath = base64IndexPath
+		}
+	} else if iconType == encodeFormat {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": "icon=base64 is not supported for virtual registries",
+		})
+		return
+	}
+
 	// cache index with the encoded icon if required and save the encoded index location
+	useBase64 := false
 	if iconType != "" {
 		if iconType == encodeFormat {
 			if _, err := os.Stat(responseBase64IndexPath); os.IsNotExist(err) {
@@ -216,7 +554,7 @@ func buildIndexAPIResponse(c *gin.Context) {
 				}
 			}
 
-			responseIndexPath = responseBase64IndexPath
+			useBase64 = true
 		} else {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"status": fmt.Sprintf("the icon type %s is not supported", iconType),
@@ -224,25 +562,100 @@ func buildIndexAPIResponse(c *gin.Context) {
 			return
 		}
 	}
-	index, err := util.ReadIndexPath(responseIndexPath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": fmt.Sprintf("failed to read the devfile index: %v", err),
-		})
-		return
+
+	rawPageSize := c.Query("pageSize")
+
+	if !useBase64 && len(archs) == 0 && search == "" && rawPageSize == "" {
+		// The common case: no icon encoding, arch filter, search, or pagination requested, so the
+		// store's precomputed serialization can be served directly instead of re-marshaling the
+		// whole catalog on every request.
+		bytes = responseIndexStore.SerializedSnapshot()
+	} else {
+		var index []indexSchema.Schema
+		if useBase64 {
+			// The base64-encoded index is a derived, on-demand cache file rather than a
+			// continuously refreshed snapshot, so it's still read straight from disk.
+			index, err = util.ReadIndexPath(responseBase64IndexPath)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"status": fmt.Sprintf("failed to read the devfile index: %v", err),
+				})
+				return
+			}
+		} else {
+			index = responseIndexStore.Snapshot()
+		}
+		index = util.ConvertToOldIndexFormat(index)
+		// Filter the index if archs has been requested
+		if len(archs) > 0 {
+			if err := indexSchema.ValidateArchitectures(archs); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"status": fmt.Sprintf("invalid arch filter: %v", err),
+				})
+				return
+			}
+			index = util.FilterDevfileArchitectures(index, archs)
+		}
+		// Filter the index if a search query has been requested
+		if search != "" {
+			index = filterBySearch(index, search)
+			searchResultCount = len(index)
+		}
+
+		var responseBody interface{} = index
+		if rawPageSize != "" {
+			pageSize, err := strconv.Atoi(rawPageSize)
+			if err != nil || pageSize <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"status": fmt.Sprintf("invalid pageSize %q: must be a positive integer", rawPageSize),
+				})
+				return
+			}
+
+			page, err := paginate(index, pageSize, c.Query("cursor"))
+			if err == errCatalogChanged {
+				c.JSON(http.StatusConflict, gin.H{
+					"status": err.Error(),
+				})
+				return
+			} else if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"status": fmt.Sprintf("invalid cursor: %v", err),
+				})
+				return
+			}
+			responseBody = page
+		}
+
+		bytes, err = encodeIndentedJSON(responseBody)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"status": fmt.Sprintf("failed to serialize index data: %v", err),
+			})
+			return
+		}
 	}
-	index = util.ConvertToOldIndexFormat(index)
-	// Filter the index if archs has been requested
-	if len(archs) > 0 {
-		index = util.FilterDevfileArchitectures(index, archs)
+
+	// Last-Modified tracks the on-disk index file, since that's what the response derives from,
+	// even when arch filtering or icon encoding changes the response body itself
+	statPath := responseIndexPath
+	if useBase64 {
+		statPath = responseBase64IndexPath
 	}
-	bytes, err = json.MarshalIndent(&index, "", "  ")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status": fmt.Sprintf("failed to serialize index data: %v", err),
-		})
-		return
+	modTime := time.Now()
+	if info, err := os.Stat(statPath); err == nil {
+		modTime = info.ModTime()
+	}
+	util.SetContentCacheHeaders(c, bytes, modTime)
+
+	if indexSigningEnabled {
+		if sig, err := signIndexResponse(bytes); err != nil {
+			log.Printf("failed to sign index response: %v", err)
+		} else {
+			c.Header(indexSignatureHeader, sig)
+		}
 	}
+
 	c.Data(http.StatusOK, http.DetectContentType(bytes), bytes)
 
 	// Track event for telemetry.  Ignore events from the registry-viewer and DevConsole since those are tracked on the client side
@@ -250,16 +663,25 @@ func buildIndexAPIResponse(c *gin.Context) {
 		user := util.GetUser(c)
 		client := util.GetClient(c)
 		err := util.TrackEvent(analytics.Track{
-			Event:   eventTrackMap["list"],
-			UserId:  user,
-			Context: util.SetContext(c),
-			Properties: analytics.NewProperties().
-				Set("type", indexType).
-				Set("registry", registry).
-				Set("client", client),
+			Event:      eventTrackMap["list"],
+			UserId:     user,
+			Context:    util.SetContext(c),
+			Properties: events.NewIndexViewed("", indexType, registry.(string), client).Properties(),
 		})
 		if err != nil {
 			log.Println(err)
 		}
+
+		if search != "" {
+			searchErr := util.TrackEvent(analytics.Track{
+				Event:      eventTrackMap["search"],
+				UserId:     user,
+				Context:    util.SetContext(c),
+				Properties: events.NewSearchPerformed(search, indexType, registry.(string), client, searchResultCount).Properties(),
+			})
+			if searchErr != nil {
+				log.Println(searchErr)
+			}
+		}
 	}
 }
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// serveIndexExport serves `/index/export`, producing the live index in an alternate serialization
+// (yaml, csv, or jsonlines) for reporting and data pipelines that don't want to parse the default
+// JSON array.
+func serveIndexExport(c *gin.Context) {
+	format, err := util.ParseExportFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status": fmt.Sprintf("invalid format: %v", err),
+		})
+		return
+	}
+
+	indexType := c.DefaultQuery("type", "all")
+	switch indexType {
+	case string(indexSchema.StackDevfileType), string(indexSchema.SampleDevfileType), "all":
+	default:
+		c.JSON(http.StatusNotFound, gin.H{
+			"status": fmt.Sprintf("the devfile with %s type doesn't exist", indexType),
+		})
+		return
+	}
+
+	catalog := catalogForRequest(c)
+	index := util.ConvertToOldIndexFormat(catalog.storeForRequest(c, indexType).Snapshot())
+	if archs := c.QueryArray("arch"); len(archs) > 0 {
+		if err := indexSchema.ValidateArchitectures(archs); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status": fmt.Sprintf("invalid arch filter: %v", err),
+			})
+			return
+		}
+		index = util.FilterDevfileArchitectures(index, archs)
+	}
+
+	body, err := util.ExportIndex(index, format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": fmt.Sprintf("failed to export the devfile index: %v", err),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, format.ContentType(), body)
+}
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/devfile/registry-support/index/server/pkg/util"
 	"io/ioutil"
 	"log"
@@ -18,8 +20,10 @@ import (
 	indexLibrary "github.com/devfile/registry-support/index/generator/library"
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
 
+	"github.com/devfile/registry-support/index/server/pkg/events"
 	_ "github.com/devfile/registry-support/index/server/docs"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http2"
 	"gopkg.in/segmentio/analytics-go.v3"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -28,6 +32,7 @@ var eventTrackMap = map[string]string{
 	"list":     "list devfile",
 	"view":     "view devfile",
 	"download": "download devfile",
+	"search":   "search devfile",
 }
 
 var mediaTypeMapping = map[string]string{
@@ -48,11 +53,61 @@ var getIndexLatency = prometheus.NewHistogramVec(
 	[]string{"status"},
 )
 
+// pullLatency measures /v2 OCI proxy (pull) request duration, so a latency spike shows up
+// alongside getIndexLatency instead of only being visible in generic reverse-proxy timing.
+var pullLatency = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "pull_http_request_duration_seconds",
+		Help:    "Latency of /v2 OCI proxy (pull) requests in seconds.",
+		Buckets: prometheus.LinearBuckets(0.5, 0.5, 10),
+	},
+	[]string{"resource"},
+)
+
+// ociProxyTransfersCancelled counts /v2 proxy requests that were aborted because the client
+// disconnected or its request context was otherwise cancelled before the backend registry
+// finished responding, so abandoned pulls are visible in monitoring instead of looking like
+// ordinary backend errors.
+var ociProxyTransfersCancelled = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "oci_proxy_transfers_cancelled_total",
+		Help: "Number of /v2 proxy requests aborted because the client disconnected before the backend registry responded.",
+	},
+)
+
+// indexStoreStale reports whether an in-memory index snapshot is stale (1) or fresh (0),
+// labeled by store name, so a failed reload shows up in monitoring even though the server
+// keeps serving the last good snapshot instead of erroring out.
+var indexStoreStale = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "index_store_stale",
+		Help: "Whether an in-memory index snapshot is stale (1) because its last reload failed, or fresh (0).",
+	},
+	[]string{"store"},
+)
+
+// recordIndexStoreMetrics refreshes the indexStoreStale gauge from the current store statuses.
+// Called after every load and reload attempt so metrics stay in sync with what's actually served.
+func recordIndexStoreMetrics() {
+	for name, store := range map[string]*util.IndexStore{"all": allIndexStore, "sample": sampleIndexStore, "stack": stackIndexStore} {
+		stale := float64(0)
+		if store.Status().Stale {
+			stale = 1
+		}
+		indexStoreStale.WithLabelValues(name).Set(stale)
+	}
+}
+
 func ServeRegistry() {
 	// Enable metrics
 	// Run on a separate port and router from the index server so that it's not exposed publicly
-	http.Handle("/metrics", promhttp.Handler())
+	// EnableOpenMetrics is required for exemplars (see tracing.go's observeWithExemplar) to be
+	// serialized at all; Prometheus's text exposition format has no room for them.
+	http.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}))
 	prometheus.MustRegister(getIndexLatency)
+	prometheus.MustRegister(pullLatency)
+	prometheus.MustRegister(indexStoreStale)
+	prometheus.MustRegister(ociProxyTransfersCancelled)
 	go http.ListenAndServe(":7071", nil)
 
 	// Wait until registry is up and running
@@ -97,7 +152,7 @@ func ServeRegistry() {
 			stackIndex = append(stackIndex, devfileIndex)
 		}
 
-		if devfileIndex.Versions != nil && len(devfileIndex.Versions) != 0{
+		if devfileIndex.Versions != nil && len(devfileIndex.Versions) != 0 {
 			for _, versionComponent := range devfileIndex.Versions {
 				if len(versionComponent.Resources) != 0 {
 					err := pushStackToRegistry(versionComponent, devfileIndex.Name)
@@ -117,6 +172,44 @@ func ServeRegistry() {
 		log.Fatalf("failed to generate %s: %v", stackIndexPath, err)
 	}
 
+	// Load the index files into atomically-swappable in-memory snapshots, so request handlers
+	// never touch disk on the hot path and every request sees a consistent, point-in-time index
+	// even while a reload is in flight.
+	allIndexStore, err = util.NewIndexStore(indexPath)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", indexPath, err)
+	}
+	sampleIndexStore, err = util.NewIndexStore(sampleIndexPath)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", sampleIndexPath, err)
+	}
+	stackIndexStore, err = util.NewIndexStore(stackIndexPath)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", stackIndexPath, err)
+	}
+	recordIndexStoreMetrics()
+
+	if err := loadCanaryIndexStores(); err != nil {
+		log.Fatalf("failed to load canary index: %v", err)
+	}
+
+	if err := loadVirtualRegistries(); err != nil {
+		log.Fatalf("failed to load virtual registries: %v", err)
+	}
+
+	if enableArtifactValidation {
+		go runArtifactValidation()
+	}
+
+	// The index is already loaded and about to be served; warm the pull cache in the background
+	// afterward so a popular stack's first real request isn't the one that pays for a cold pull,
+	// without delaying when this registry starts answering index requests.
+	go warmupPullCache()
+
+	// Likewise, build the optional content search index in the background so a "search" query can
+	// match a stack's devfile.yaml text without delaying startup.
+	go buildContentSearchIndex()
+
 	// Logs for telemetry configuration
 	if enableTelemetry {
 		log.Println("Telemetry is enabled")
@@ -126,14 +219,72 @@ func ServeRegistry() {
 	}
 
 	// Start the server and serve requests and index.json
-	router := gin.Default()
+	// gin.Default()'s built-in logger has no notion of per-endpoint sampling, so it is
+	// replaced here with accessLogSamplingMiddleware.
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(traceIDMiddleware())
+	router.Use(accessLogSamplingMiddleware())
+	router.Use(requestLimitsMiddleware())
+	router.Use(shadowTrafficMiddleware())
 
 	// Registry REST APIs
 	router.GET("/", serveRootEndpoint)
 	router.GET("/index", serveDevfileIndex)
+	router.GET("/index/export", serveIndexExport)
 	router.GET("/index/:type", serveDevfileIndexWithType)
 	router.GET("/health", serveHealthCheck)
+	router.GET("/readyz", serveReadyz)
+	router.GET("/sitemap.xml", serveSitemap)
+	router.GET("/robots.txt", serveRobotsTxt)
 	router.GET("/devfiles/:name", serveDevfile)
+	router.GET("/quota/:namespace", serveNamespaceQuota)
+	router.POST("/admin/quota/:namespace/check", adminAuthMiddleware(), serveCheckNamespaceQuota)
+	router.GET("/v2index/popular", servePopularStacks)
+	router.GET("/v2index/:stack", serveStackMetadata)
+	router.GET("/v2index/:stack/:version", serveStackMetadata)
+	router.GET("/admin/upstreams", serveUpstreamsHealth)
+	router.GET("/admin/quarantine", serveQuarantinedEntries)
+	router.GET("/admin/audit", serveAuditExport)
+	router.POST("/admin/reload", serveReloadIndex)
+	router.DELETE("/admin/cache", adminAuthMiddleware(), servePurgeCache)
+	router.DELETE("/admin/cache/:stack/:version", adminAuthMiddleware(), servePurgeCache)
+	router.DELETE("/admin/cache/:stack/:version/:resource", adminAuthMiddleware(), servePurgeCache)
+	router.POST("/validate", serveValidateDevfile)
+
+	// HEAD variants of the content-serving endpoints, so clients and CDNs can cheaply check
+	// Content-Length/ETag/Last-Modified before downloading. Go's net/http server automatically
+	// drops the response body for HEAD requests, so it's safe to reuse the GET handlers here.
+	router.HEAD("/index", serveDevfileIndex)
+	router.HEAD("/index/:type", serveDevfileIndexWithType)
+	router.HEAD("/devfiles/:name", serveDevfile)
+
+	// Versioned API surface. /api/v1 is the canonical, stability-guaranteed path going forward;
+	// the unversioned paths above are kept as aliases for existing clients.
+	v1 := router.Group("/api/v1")
+	v1.GET("/meta", serveAPIMeta)
+	v1.GET("/index", serveDevfileIndex)
+	v1.GET("/index/export", serveIndexExport)
+	v1.GET("/index/:type", serveDevfileIndexWithType)
+	v1.GET("/health", serveHealthCheck)
+	v1.GET("/readyz", serveReadyz)
+	v1.GET("/devfiles/:name", serveDevfile)
+	v1.GET("/quota/:namespace", serveNamespaceQuota)
+	v1.POST("/admin/quota/:namespace/check", adminAuthMiddleware(), serveCheckNamespaceQuota)
+	v1.GET("/v2index/popular", servePopularStacks)
+	v1.GET("/v2index/:stack", serveStackMetadata)
+	v1.GET("/v2index/:stack/:version", serveStackMetadata)
+	v1.GET("/admin/upstreams", serveUpstreamsHealth)
+	v1.GET("/admin/quarantine", serveQuarantinedEntries)
+	v1.GET("/admin/audit", serveAuditExport)
+	v1.POST("/admin/reload", serveReloadIndex)
+	v1.DELETE("/admin/cache", adminAuthMiddleware(), servePurgeCache)
+	v1.DELETE("/admin/cache/:stack/:version", adminAuthMiddleware(), servePurgeCache)
+	v1.DELETE("/admin/cache/:stack/:version/:resource", adminAuthMiddleware(), servePurgeCache)
+	v1.POST("/validate", serveValidateDevfile)
+	v1.HEAD("/index", serveDevfileIndex)
+	v1.HEAD("/index/:type", serveDevfileIndexWithType)
+	v1.HEAD("/devfiles/:name", serveDevfile)
 
 	// Set up a simple proxy for /v2 endpoints
 	// Only allow HEAD and GET requests
@@ -150,10 +301,35 @@ func ServeRegistry() {
 	// Serve static content for stacks
 	router.Static("/stacks", stacksPath)
 
-	router.Run(":8080")
+	// Tuned in place of gin's router.Run(":8080") shortcut so IDE clients that open many small
+	// parallel devfile/icon requests don't queue up behind net/http's generous defaults.
+	httpServer := &http.Server{
+		Addr:           ":8080",
+		Handler:        router,
+		ReadTimeout:    time.Duration(readTimeoutSeconds) * time.Second,
+		WriteTimeout:   time.Duration(writeTimeoutSeconds) * time.Second,
+		IdleTimeout:    time.Duration(idleTimeoutSeconds) * time.Second,
+		MaxHeaderBytes: maxHeaderBytes,
+	}
+	if enableHTTP2 {
+		// Only takes effect once this server negotiates TLS (ALPN); in cluster deployments TLS is
+		// normally terminated by a fronting route/ingress that already speaks HTTP/2 to clients, so
+		// this mainly matters if the server is ever run with TLS enabled directly.
+		err := http2.ConfigureServer(httpServer, &http2.Server{
+			MaxConcurrentStreams: uint32(http2MaxConcurrentStreams),
+		})
+		if err != nil {
+			log.Fatalf("failed to configure HTTP/2: %v", err)
+		}
+	}
+	log.Fatal(httpServer.ListenAndServe())
 }
 
-// ociServerProxy forwards all GET requests on /v2 to the OCI registry server
+// ociServerProxy forwards all GET requests on /v2 to the OCI registry server. httputil.ReverseProxy
+// derives its outbound request from c.Request's context, so a client disconnect (or the incoming
+// request's deadline expiring) already cancels the in-flight backend request; the ErrorHandler
+// below just distinguishes that expected cancellation from a genuine backend failure so it shows
+// up in ociProxyTransfersCancelled instead of being logged as an error.
 func ociServerProxy(c *gin.Context) {
 	remote, err := url.Parse(scheme + "://" + registryService + "/v2")
 	if err != nil {
@@ -163,40 +339,57 @@ func ociServerProxy(c *gin.Context) {
 	proxy := httputil.NewSingleHostReverseProxy(remote)
 
 	// Set up the request to the proxy
-	// Track event for telemetry
-	if enableTelemetry {
-		proxyPath := c.Param("proxyPath")
-		if proxyPath != "" {
-			var name string
-			var resource string
-			parts := strings.Split(proxyPath, "/")
-			// Check proxyPath (e.g. /devfile-catalog/java-quarkus/blobs/sha256:d913cab108c3bc1bd06ce61f1e0cdb6eea2222a7884378f7e656fa26249990b9)
-			if len(parts) == 5 {
-				name = parts[2]
-				resource = parts[3]
-			}
+	// Track event for telemetry, and record the pull for the /v2index/popular ranking
+	proxyPath := c.Param("proxyPath")
+	resourceLabel := "manifest"
+	if proxyPath != "" {
+		var name string
+		var resource string
+		parts := strings.Split(proxyPath, "/")
+		// Check proxyPath (e.g. /devfile-catalog/java-quarkus/blobs/sha256:d913cab108c3bc1bd06ce61f1e0cdb6eea2222a7884378f7e656fa26249990b9)
+		if len(parts) == 5 {
+			name = parts[2]
+			resource = parts[3]
+		}
 
-			//Ignore events from the registry-viewer and DevConsole since those are tracked on the client side
-			if resource == "blobs" && !util.IsWebClient(c) {
-				user := util.GetUser(c)
-				client := util.GetClient(c)
-
-				err := util.TrackEvent(analytics.Track{
-					Event:   eventTrackMap["download"],
-					UserId:  user,
-					Context: util.SetContext(c),
-					Properties: analytics.NewProperties().
-						Set("name", name).
-						Set("registry", registry).
-						Set("client", client),
-				})
-				if err != nil {
-					log.Println(err.Error())
-				}
+		if resource != "" {
+			resourceLabel = resource
+		}
+
+		if resource == "blobs" {
+			now := time.Now()
+			popularityTracker.Record(name, now)
+			auditTrail.Record(util.PullRecord{
+				Timestamp:  now,
+				Stack:      name,
+				ClientType: util.GetClient(c),
+				ClientID:   util.AnonymizeClientID(util.GetUser(c)),
+			})
+		}
+
+		//Ignore events from the registry-viewer and DevConsole since those are tracked on the client side
+		if enableTelemetry && resource == "blobs" && !util.IsWebClient(c) {
+			user := util.GetUser(c)
+			client := util.GetClient(c)
+
+			err := util.TrackEvent(analytics.Track{
+				Event:      eventTrackMap["download"],
+				UserId:     user,
+				Context:    util.SetContext(c),
+				Properties: events.NewStackPulled(name, registry.(string), client).Properties(),
+			})
+			if err != nil {
+				log.Println(err.Error())
 			}
 		}
 	}
 
+	pullStart := time.Now()
+	traceID := traceIDFromContext(c)
+	defer func() {
+		observeWithExemplar(pullLatency.WithLabelValues(resourceLabel), time.Since(pullStart).Seconds(), traceID)
+	}()
+
 	proxy.Director = func(req *http.Request) {
 		req.Header.Add("X-Forwarded-Host", req.Host)
 		req.Header.Add("X-Origin-Host", remote.Host)
@@ -204,5 +397,14 @@ func ociServerProxy(c *gin.Context) {
 		req.URL.Host = remote.Host
 	}
 
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.Is(err, context.Canceled) || errors.Is(r.Context().Err(), context.Canceled) {
+			ociProxyTransfersCancelled.Inc()
+			return
+		}
+		log.Printf("oci proxy error: %v", err)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
 	proxy.ServeHTTP(c.Writer, c.Request)
 }
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLimitsMiddleware caps the query string length and request body size of every request,
+// so a client can't exhaust server memory or CPU with an oversized query or POST body (the
+// header block itself is capped by maxHeaderBytes on the http.Server, which net/http enforces
+// before a handler ever runs, responding 431). Query strings over maxQueryStringBytes are
+// rejected outright with 413 Request Entity Too Large. A body whose declared Content-Length
+// exceeds maxRequestBodyBytes is rejected the same way before a handler reads a single byte of
+// it; a body with no declared length (or a lying one) is still capped via http.MaxBytesReader,
+// which surfaces as a read error to the handler's existing body-parsing error handling.
+func requestLimitsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(c.Request.URL.RawQuery) > maxQueryStringBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"status": fmt.Sprintf("query string exceeds the %d byte limit", maxQueryStringBytes),
+			})
+			return
+		}
+
+		if c.Request.ContentLength > int64(maxRequestBodyBytes) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"status": fmt.Sprintf("request body exceeds the %d byte limit", maxRequestBodyBytes),
+			})
+			return
+		}
+
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxRequestBodyBytes))
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// requestClass buckets a request so its access log sampling rate can be configured
+// independently per class of endpoint.
+type requestClass string
+
+const (
+	// requestClassBlob is an OCI blob/manifest download under /v2, which dominates request
+	// volume on a busy public registry and can be safely sampled down.
+	requestClassBlob requestClass = "blob"
+	// requestClassAdmin is an operator-facing endpoint (health checks, quota, reload,
+	// upstream health) that is low-volume and must stay fully logged.
+	requestClassAdmin requestClass = "admin"
+	// requestClassIndex is everything else: index/devfile/stack metadata requests.
+	requestClassIndex requestClass = "index"
+)
+
+// classifyRequest buckets c by request path.
+func classifyRequest(c *gin.Context) requestClass {
+	path := c.Request.URL.Path
+	switch {
+	case path == "/health", path == "/readyz", strings.HasPrefix(path, "/quota/"), strings.HasPrefix(path, "/admin/"):
+		return requestClassAdmin
+	case strings.HasPrefix(path, "/v2/"):
+		return requestClassBlob
+	default:
+		return requestClassIndex
+	}
+}
+
+// accessLogSamplePercentFor returns the configured access log sampling rate (0-100) for class.
+func accessLogSamplePercentFor(class requestClass) int {
+	switch class {
+	case requestClassBlob:
+		return blobAccessLogSamplePercent
+	case requestClassAdmin:
+		return adminAccessLogSamplePercent
+	default:
+		return indexAccessLogSamplePercent
+	}
+}
+
+// accessLogSamplingMiddleware logs each request in gin's default access log format, but only
+// for a configurable percentage of requests per endpoint class, so a high-traffic public
+// registry can sample down its noisiest endpoint class (blob downloads) without losing
+// visibility into low-volume admin endpoints. Replaces gin.Default()'s built-in logger, which
+// has no notion of per-endpoint sampling.
+func accessLogSamplingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		raw := c.Request.URL.RawQuery
+
+		c.Next()
+
+		if !util.ShouldShadow(accessLogSamplePercentFor(classifyRequest(c))) {
+			return
+		}
+
+		if raw != "" {
+			path = path + "?" + raw
+		}
+		log.Printf("[GIN] %3d | %13v | %-7s %s",
+			c.Writer.Status(),
+			time.Since(start),
+			c.Request.Method,
+			path,
+		)
+	}
+}
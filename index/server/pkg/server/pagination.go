@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+)
+
+// paginatedIndexResponse is served instead of a bare index array when the caller opts into
+// pagination via pageSize, so it has somewhere to carry nextCursor alongside the page itself.
+type paginatedIndexResponse struct {
+	Items      []indexSchema.Schema `json:"items"`
+	NextCursor string               `json:"nextCursor,omitempty"`
+	TotalCount int                  `json:"totalCount"`
+}
+
+// indexCursor is the decoded form of an opaque pagination cursor. Etag pins the cursor to the
+// exact index snapshot it was issued against, so resuming pagination after the catalog changed
+// underneath the client (e.g. an /admin/reload) is refused instead of silently skipping or
+// repeating entries.
+type indexCursor struct {
+	Etag   string `json:"etag"`
+	Offset int    `json:"offset"`
+}
+
+// encodeCursor packs etag and offset into an opaque token safe to hand back to clients as a
+// query parameter.
+func encodeCursor(etag string, offset int) string {
+	bytes, _ := json.Marshal(indexCursor{Etag: etag, Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// decodeCursor reverses encodeCursor, rejecting anything that isn't a token this server issued.
+func decodeCursor(token string) (indexCursor, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return indexCursor{}, fmt.Errorf("malformed cursor: %v", err)
+	}
+	var cursor indexCursor
+	if err := json.Unmarshal(bytes, &cursor); err != nil {
+		return indexCursor{}, fmt.Errorf("malformed cursor: %v", err)
+	}
+	if cursor.Offset < 0 {
+		return indexCursor{}, fmt.Errorf("malformed cursor: offset %d is negative", cursor.Offset)
+	}
+	return cursor, nil
+}
+
+// paginate slices index into a single page of pageSize entries starting at cursorToken's offset
+// (or the beginning, if cursorToken is empty), returning a 409-worthy error if cursorToken was
+// issued against a snapshot that no longer matches index's current content.
+func paginate(index []indexSchema.Schema, pageSize int, cursorToken string) (paginatedIndexResponse, error) {
+	etag := indexETag(index)
+
+	offset := 0
+	if cursorToken != "" {
+		cursor, err := decodeCursor(cursorToken)
+		if err != nil {
+			return paginatedIndexResponse{}, err
+		}
+		if cursor.Etag != etag {
+			return paginatedIndexResponse{}, errCatalogChanged
+		}
+		offset = cursor.Offset
+	}
+
+	if offset > len(index) {
+		offset = len(index)
+	}
+	end := offset + pageSize
+	if end > len(index) {
+		end = len(index)
+	}
+
+	response := paginatedIndexResponse{
+		Items:      index[offset:end],
+		TotalCount: len(index),
+	}
+	if end < len(index) {
+		response.NextCursor = encodeCursor(etag, end)
+	}
+	return response, nil
+}
+
+// errCatalogChanged is returned by paginate when a cursor no longer matches the current index
+// snapshot's etag, so buildIndexAPIResponse can turn it into a 409 with a clear refresh signal.
+var errCatalogChanged = fmt.Errorf("the catalog changed since this cursor was issued; restart pagination from the beginning")
+
+// indexETag fingerprints index's content, independent of pagination, so a cursor built from one
+// page can be validated against a later page's view of the same (or a different) snapshot.
+func indexETag(index []indexSchema.Schema) string {
+	bytes, err := json.Marshal(index)
+	if err != nil {
+		return ""
+	}
+	return util.ContentETag(bytes)
+}
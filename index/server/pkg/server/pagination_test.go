@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func testIndex(n int) []indexSchema.Schema {
+	index := make([]indexSchema.Schema, n)
+	for i := range index {
+		index[i] = indexSchema.Schema{Name: string(rune('a' + i))}
+	}
+	return index
+}
+
+func TestPaginateRoundTripsCursor(t *testing.T) {
+	index := testIndex(5)
+
+	first, err := paginate(index, 2, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Items) != 2 || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+
+	second, err := paginate(index, 2, first.NextCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Items) != 2 || second.Items[0].Name != "c" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+}
+
+func TestPaginateRejectsCursorWithNegativeOffset(t *testing.T) {
+	index := testIndex(5)
+
+	forged := encodeCursorWithOffset(t, indexETag(index), -5)
+
+	_, err := paginate(index, 2, forged)
+	if err == nil {
+		t.Fatal("expected an error for a cursor with a negative offset")
+	}
+	if err == errCatalogChanged {
+		t.Fatalf("expected a malformed-cursor error, got errCatalogChanged")
+	}
+}
+
+func TestPaginateClampsOffsetPastEnd(t *testing.T) {
+	index := testIndex(3)
+
+	forged := encodeCursorWithOffset(t, indexETag(index), 1000)
+
+	page, err := paginate(index, 2, forged)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Items) != 0 {
+		t.Fatalf("expected an empty page past the end of the index, got %+v", page.Items)
+	}
+}
+
+// encodeCursorWithOffset builds a cursor token with an arbitrary offset, bypassing
+// encodeCursor's normal offset (which is always non-negative), so tests can exercise how
+// decodeCursor handles a cursor a client forged or corrupted.
+func encodeCursorWithOffset(t *testing.T, etag string, offset int) string {
+	t.Helper()
+	bytes, err := json.Marshal(indexCursor{Etag: etag, Offset: offset})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
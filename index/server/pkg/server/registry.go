@@ -76,18 +76,8 @@ func pushStackToRegistry(versionComponent indexSchema.Version, stackName string)
 	return nil
 }
 
-// pullStackFromRegistry pulls the given devfile stack from the OCI registry
+// pullStackFromRegistry pulls the given devfile stack's devfile.yaml from the OCI registry
 func pullStackFromRegistry(versionComponent indexSchema.Version) ([]byte, error) {
-	// Pull the devfile from registry and save to disk
-	ref := path.Join(registryService, "/", versionComponent.Links["self"])
-
-	ctx := context.Background()
-	resolver := docker.NewResolver(docker.ResolverOptions{PlainHTTP: true})
-
-	// Initialize memory store
-	memoryStore := content.NewMemoryStore()
-	allowedMediaTypes := []string{devfileMediaType}
-
 	var devfile string
 	for _, resource := range versionComponent.Resources {
 		if resource == devfileName {
@@ -99,16 +89,40 @@ func pullStackFromRegistry(versionComponent indexSchema.Version) ([]byte, error)
 			break
 		}
 	}
-	log.Printf("Pulling %s from %s...\n", devfile, ref)
-	desc, _, err := oras.Pull(ctx, resolver, ref, memoryStore, oras.WithAllowedMediaTypes(allowedMediaTypes))
+	return pullResourceFromRegistry(versionComponent, devfile)
+}
+
+// pullResourceFromRegistry pulls the named resource (e.g. a devfile.yaml or a signature file
+// alongside it) out of versionComponent's OCI artifact.
+func pullResourceFromRegistry(versionComponent indexSchema.Version, resource string) ([]byte, error) {
+	versionLink := versionComponent.Links["self"]
+	if pullCacheEnabled {
+		if data, ok := pullCache.get(versionLink, resource); ok {
+			return data, nil
+		}
+	}
+
+	ref := path.Join(registryService, "/", versionLink)
+
+	ctx := context.Background()
+	resolver := docker.NewResolver(docker.ResolverOptions{PlainHTTP: true})
+
+	// Initialize memory store
+	memoryStore := content.NewMemoryStore()
+
+	log.Printf("Pulling %s from %s...\n", resource, ref)
+	desc, _, err := oras.Pull(ctx, resolver, ref, memoryStore)
 	if err != nil {
-		return nil, fmt.Errorf("failed to pull %s from %s: %v", devfile, ref, err)
+		return nil, fmt.Errorf("failed to pull %s from %s: %v", resource, ref, err)
 	}
-	_, bytes, ok := memoryStore.GetByName(devfile)
+	_, bytes, ok := memoryStore.GetByName(resource)
 	if !ok {
-		return nil, fmt.Errorf("failed to load %s to memory", devfile)
+		return nil, fmt.Errorf("failed to load %s to memory", resource)
 	}
 
 	log.Printf("Pulled from %s with digest %s\n", ref, desc.Digest)
+	if pullCacheEnabled {
+		pullCache.put(versionLink, resource, bytes)
+	}
 	return bytes, nil
 }
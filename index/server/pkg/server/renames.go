@@ -0,0 +1,32 @@
+package server
+
+import "strings"
+
+// parseStackRenames parses a comma-separated "old=new" list (the STACK_RENAMES env var) into a
+// lookup from old stack name to new stack name. Malformed entries (missing "=", or an empty old
+// or new name) are skipped rather than failing startup, so one typo in the config doesn't take
+// the whole registry down.
+func parseStackRenames(raw string) map[string]string {
+	renames := map[string]string{}
+	if raw == "" {
+		return renames
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		oldName := strings.TrimSpace(parts[0])
+		newName := strings.TrimSpace(parts[1])
+		if oldName == "" || newName == "" {
+			continue
+		}
+		renames[oldName] = newName
+	}
+	return renames
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// shadowTrafficMiddleware mirrors a sample of GET/HEAD requests to shadowRegistryURL, so
+// operators can validate a new registry deployment against production traffic before cutover.
+// Mirroring happens after the primary response has already been written to the client and never
+// blocks or affects it; mismatches between the primary and shadow status codes are just logged.
+func shadowTrafficMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if shadowRegistryURL == "" {
+			return
+		}
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return
+		}
+		if !util.ShouldShadow(shadowSamplePercent) {
+			return
+		}
+
+		method := c.Request.Method
+		path := c.Request.URL.RequestURI()
+		status := c.Writer.Status()
+		go util.MirrorRequest(shadowRegistryURL, method, path, status)
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// signatureEnforcementLevel controls what happens when a stack artifact's signature is missing or
+// doesn't verify: signatureEnforcementOff skips verification entirely, signatureEnforcementWarn
+// logs the failure but still serves the artifact, and signatureEnforcementEnforce refuses to serve
+// it. This lets a registry operator roll out signing gradually instead of an all-or-nothing switch.
+type signatureEnforcementLevel string
+
+const (
+	signatureEnforcementOff     signatureEnforcementLevel = "off"
+	signatureEnforcementWarn    signatureEnforcementLevel = "warn"
+	signatureEnforcementEnforce signatureEnforcementLevel = "enforce"
+
+	// signatureResourceSuffix is appended to a resource's name to get the name of the OCI resource
+	// carrying its detached signature, e.g. "devfile.yaml" -> "devfile.yaml.sig".
+	signatureResourceSuffix = ".sig"
+
+	// indexSignatureHeader carries the base64-encoded ed25519 signature of an index response body,
+	// so a client that already has the registry's public key can verify the catalog it received
+	// wasn't tampered with by an intermediate caching proxy. This is a detached raw signature
+	// rather than a full JWS, since this module doesn't vendor a JOSE library; it's encoded the
+	// same way stack artifact signatures already are (see verifyStackVersionSignature).
+	indexSignatureHeader = "X-Index-Signature"
+)
+
+// parseSignatureEnforcementLevel parses the SIGNATURE_ENFORCEMENT env var, falling back to off (and
+// logging a warning) for anything unrecognized, so a typo in configuration fails open to the
+// existing unsigned-serving behavior rather than refusing to serve every stack.
+func parseSignatureEnforcementLevel(value string) signatureEnforcementLevel {
+	switch signatureEnforcementLevel(strings.ToLower(value)) {
+	case signatureEnforcementWarn:
+		return signatureEnforcementWarn
+	case signatureEnforcementEnforce:
+		return signatureEnforcementEnforce
+	case "", signatureEnforcementOff:
+		return signatureEnforcementOff
+	default:
+		log.Printf("unrecognized SIGNATURE_ENFORCEMENT value %q, defaulting to off", value)
+		return signatureEnforcementOff
+	}
+}
+
+// loadSignaturePublicKey reads and parses the PEM-encoded ed25519 public key at path, used to
+// verify artifact signatures. Note that this only covers detached-signature verification against a
+// statically configured key; it does not implement the keyless (Fulcio certificate + Rekor
+// transparency log) verification cosign also supports, since the sigstore/cosign client libraries
+// aren't vendored into this module.
+func loadSignaturePublicKey(path string) (ed25519.PublicKey, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature public key %s: %v", path, err)
+	}
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block from signature public key %s", path)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signature public key %s: %v", path, err)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("signature public key %s is not an ed25519 public key", path)
+	}
+	return key, nil
+}
+
+// loadSignaturePrivateKey reads and parses the PEM-encoded PKCS#8 ed25519 private key at path, used
+// to sign index responses.
+func loadSignaturePrivateKey(path string) (ed25519.PrivateKey, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature private key %s: %v", path, err)
+	}
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block from signature private key %s", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signature private key %s: %v", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signature private key %s is not an ed25519 private key", path)
+	}
+	return key, nil
+}
+
+// signIndexResponse returns the base64-encoded ed25519 signature of body, for a caller to set on
+// indexSignatureHeader, or an error if index signing isn't configured correctly. Callers should
+// treat a returned error as non-fatal to the response: an unsigned response is still a correct
+// response, just not one a zero-trust client can verify.
+func signIndexResponse(body []byte) (string, error) {
+	if indexSigningPrivateKeyErr != nil {
+		return "", fmt.Errorf("index signing is not configured correctly: %v", indexSigningPrivateKeyErr)
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(indexSigningPrivateKey, body)), nil
+}
+
+// verifyStackVersionSignature checks devfileBytes (the version's default devfile.yaml artifact,
+// already pulled from the registry) against its detached signature resource, and returns an error
+// describing the failure if enforcement is not off and either the signature resource is missing,
+// the configured public key failed to load, or the signature doesn't verify. Enforcement level
+// signatureEnforcementWarn logs the same failure instead of returning it, so callers only need to
+// branch on the returned error to decide whether to refuse to serve.
+func verifyStackVersionSignature(version indexSchema.Version, devfileBytes []byte) error {
+	if signatureEnforcement == signatureEnforcementOff {
+		return nil
+	}
+
+	err := doVerifyStackVersionSignature(version, devfileBytes)
+	if err == nil {
+		return nil
+	}
+	if signatureEnforcement == signatureEnforcementWarn {
+		log.Printf("signature verification failed for %s: %v", version.Links["self"], err)
+		return nil
+	}
+	return err
+}
+
+func doVerifyStackVersionSignature(version indexSchema.Version, devfileBytes []byte) error {
+	if signaturePublicKeyErr != nil {
+		return fmt.Errorf("signature verification is not configured correctly: %v", signaturePublicKeyErr)
+	}
+
+	sigResource := ""
+	for _, resource := range version.Resources {
+		if resource == devfileName+signatureResourceSuffix || resource == devfileNameHidden+signatureResourceSuffix {
+			sigResource = resource
+			break
+		}
+	}
+	if sigResource == "" {
+		return fmt.Errorf("no signature resource found for %s", version.Links["self"])
+	}
+
+	encodedSig, err := pullResourceFromRegistry(version, sigResource)
+	if err != nil {
+		return fmt.Errorf("failed to pull signature resource %s: %v", sigResource, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encodedSig)))
+	if err != nil {
+		return fmt.Errorf("signature resource %s is not valid base64: %v", sigResource, err)
+	}
+
+	if !ed25519.Verify(signaturePublicKey, devfileBytes, signature) {
+		return fmt.Errorf("signature %s did not verify against the configured public key", sigResource)
+	}
+	return nil
+}
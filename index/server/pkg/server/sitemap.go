@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// sitemapURLSet and sitemapURL model the small subset of the sitemap protocol
+// (https://www.sitemaps.org/protocol.html) this registry needs: one <url> entry per stack detail
+// page in the viewer.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// serveSitemap serves `/sitemap.xml`, listing every stack's viewer detail page, so a public
+// registry's stacks are discoverable by search engines instead of only by clients that already
+// know to query the index API. Samples aren't included since the viewer has no per-sample detail
+// page to link to. The base URL is derived from the request itself (scheme via
+// X-Forwarded-Proto, defaulting to https since a public registry sits behind a TLS-terminating
+// front door) rather than a fixed config value, so the same registry serves correct sitemaps
+// under every hostname it's reachable at, including virtual-hosted registries.
+func serveSitemap(c *gin.Context) {
+	catalog := catalogForRequest(c)
+	base := publicBaseURL(c)
+
+	urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, entry := range catalog.stackIndexStore.Snapshot() {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{Loc: base + "/viewer/stack/" + entry.Name})
+	}
+
+	body, err := xml.MarshalIndent(&urlSet, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status": "failed to generate sitemap",
+		})
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	util.SetContentCacheHeaders(c, body, time.Now())
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// serveRobotsTxt serves `/robots.txt`, allowing crawlers to index the catalog and viewer while
+// keeping them out of the admin and OCI proxy surfaces, and points them at /sitemap.xml.
+func serveRobotsTxt(c *gin.Context) {
+	body := "User-agent: *\n" +
+		"Disallow: /admin\n" +
+		"Disallow: /v2\n" +
+		"Allow: /\n" +
+		"Sitemap: " + publicBaseURL(c) + "/sitemap.xml\n"
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(body))
+}
+
+// publicBaseURL returns the scheme and host this registry is externally reachable at for c,
+// e.g. "https://registry.example.com", for composing absolute URLs in generated documents like
+// the sitemap. The scheme honors X-Forwarded-Proto since TLS is normally terminated in front of
+// this server (see the HTTP/2 comment in ServeRegistry), defaulting to https as the safer
+// assumption for a public-facing registry rather than downgrading a crawler to plain HTTP.
+func publicBaseURL(c *gin.Context) string {
+	proto := c.Request.Header.Get("X-Forwarded-Proto")
+	if proto == "" {
+		proto = "https"
+	}
+	return proto + "://" + c.Request.Host
+}
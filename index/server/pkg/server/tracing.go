@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// traceIDContextKey is the gin.Context key traceIDMiddleware stores the request's trace ID under.
+const traceIDContextKey = "traceID"
+
+// traceparentHeader is the W3C Trace Context request header this server reads an inbound trace ID
+// from, so a request already traced by an upstream proxy or client keeps the same trace ID here
+// instead of getting a disconnected one.
+const traceparentHeader = "traceparent"
+
+// traceIDMiddleware assigns every request a trace ID (from an inbound W3C "traceparent" header, or
+// freshly generated when absent or malformed) and stores it on the request context, so latency
+// histogram observations can be tagged with it as an exemplar. A no-op unless tracingEnabled is
+// set, since generating and threading a trace ID through every request costs a little on every
+// request for a benefit operators may not use.
+func traceIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !tracingEnabled {
+			c.Next()
+			return
+		}
+
+		traceID := traceIDFromTraceparent(c.GetHeader(traceparentHeader))
+		if traceID == "" {
+			traceID = newTraceID()
+		}
+		c.Set(traceIDContextKey, traceID)
+		c.Header("X-Trace-Id", traceID)
+		c.Next()
+	}
+}
+
+// traceIDFromTraceparent extracts the trace-id field from a W3C Trace Context header value
+// ("<version>-<trace-id>-<parent-id>-<flags>"), returning "" if header doesn't parse as one.
+func traceIDFromTraceparent(header string) string {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
+
+// newTraceID generates a random 128-bit trace ID, hex-encoded like a W3C trace-id field.
+func newTraceID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// observeWithExemplar records v on observer, attaching traceID as an OpenMetrics exemplar when
+// tracingEnabled is set and traceID is non-empty, so an operator looking at a latency spike in
+// Grafana can jump straight to the trace that produced it. Falls back to a plain Observe
+// otherwise, or if observer doesn't support exemplars.
+func observeWithExemplar(observer prometheus.Observer, v float64, traceID string) {
+	if tracingEnabled && traceID != "" {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(v, prometheus.Labels{"traceID": traceID})
+			return
+		}
+	}
+	observer.Observe(v)
+}
+
+// traceIDFromContext returns the trace ID traceIDMiddleware stored on c, or "" if tracing is
+// disabled or the middleware didn't run.
+func traceIDFromContext(c *gin.Context) string {
+	traceID, _ := c.Get(traceIDContextKey)
+	id, _ := traceID.(string)
+	return id
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	devfileParser "github.com/devfile/library/pkg/devfile"
+	"github.com/devfile/library/pkg/devfile/parser"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
+)
+
+// minimalDevfile is the smallest structural shape every devfile.yaml is expected to have. Full
+// devfile schema validation belongs in the generator's build-time pipeline
+// (index/generator/library.GenerateIndexStructWithOptions); this is a lightweight,
+// dependency-free sanity check that a stored/pulled artifact still parses as YAML and carries
+// the fields the server itself relies on, so a corrupted or truncated artifact is caught by an
+// admin here rather than by a client at pull time.
+type minimalDevfile struct {
+	SchemaVersion string `yaml:"schemaVersion"`
+	Metadata      struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+}
+
+// validateStackArtifacts pulls the default version's devfile.yaml for every stack in index and
+// checks that it parses as YAML and has the fields the server relies on, returning one
+// util.QuarantinedEntry per stack whose artifact fails either check.
+func validateStackArtifacts(index []indexSchema.Schema) []util.QuarantinedEntry {
+	var quarantined []util.QuarantinedEntry
+	for _, devfileIndex := range index {
+		if devfileIndex.Type != indexSchema.StackDevfileType {
+			continue
+		}
+		for _, version := range devfileIndex.Versions {
+			if !version.Default {
+				continue
+			}
+			if reason := validateStackVersionArtifact(version); reason != "" {
+				quarantined = append(quarantined, util.QuarantinedEntry{
+					Name:    devfileIndex.Name,
+					Version: version.Version,
+					Reason:  reason,
+				})
+			}
+			break
+		}
+	}
+	return quarantined
+}
+
+// validateStackVersionArtifact pulls version's devfile artifact and returns a non-empty reason
+// if it fails to parse or is missing a required field, or "" if it looks valid.
+func validateStackVersionArtifact(version indexSchema.Version) string {
+	bytes, err := pullStackFromRegistry(version)
+	if err != nil {
+		return fmt.Sprintf("failed to pull devfile: %v", err)
+	}
+	if err := verifyStackVersionSignature(version, bytes); err != nil {
+		return fmt.Sprintf("signature verification failed: %v", err)
+	}
+
+	var devfile minimalDevfile
+	if err := yaml.Unmarshal(bytes, &devfile); err != nil {
+		return fmt.Sprintf("devfile is not valid YAML: %v", err)
+	}
+	if devfile.SchemaVersion == "" {
+		return "devfile is missing schemaVersion"
+	}
+	if devfile.Metadata.Name == "" {
+		return "devfile is missing metadata.name"
+	}
+	return ""
+}
+
+// ValidationResult is the response body for POST /validate: whether the supplied devfile passed,
+// and every error found along the way.
+type ValidationResult struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// serveValidateDevfile serves `POST /validate`, running a user-supplied devfile.yaml through the
+// same devfile/library parser, schema validation, and required-metadata checks the generator
+// applies to every stack at index-build time (see
+// index/generator/library.checkForRequiredMetadata, which the metadata checks below mirror), so
+// stack authors and UIs can pre-validate a contribution without pushing it to a registry first.
+func serveValidateDevfile(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ValidationResult{Valid: false, Errors: []string{fmt.Sprintf("failed to read request body: %v", err)}})
+		return
+	}
+	if len(body) == 0 {
+		c.JSON(http.StatusBadRequest, ValidationResult{Valid: false, Errors: []string{"request body must contain a devfile.yaml"}})
+		return
+	}
+
+	result := validateDevfileContent(body)
+	status := http.StatusOK
+	if !result.Valid {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, result)
+}
+
+// validateDevfileContent parses and schema-validates data with the devfile library, then checks
+// for the metadata fields this registry requires of every stack.
+func validateDevfileContent(data []byte) ValidationResult {
+	devfileObj, _, err := devfileParser.ParseDevfileAndValidate(parser.ParserArgs{Data: data})
+	if err != nil {
+		return ValidationResult{Valid: false, Errors: []string{err.Error()}}
+	}
+
+	var errs []string
+	metadata := devfileObj.Data.GetMetadata()
+	if metadata.Name == "" {
+		errs = append(errs, "metadata.name is not set")
+	}
+	if metadata.DisplayName == "" {
+		errs = append(errs, "metadata.displayName is not set")
+	}
+	if metadata.Language == "" {
+		errs = append(errs, "metadata.language is not set")
+	}
+	if metadata.ProjectType == "" {
+		errs = append(errs, "metadata.projectType is not set")
+	}
+
+	return ValidationResult{Valid: len(errs) == 0, Errors: errs}
+}
+
+// runArtifactValidation validates every stack's default-version devfile artifact against the
+// current stack index snapshot and publishes the result to quarantineStore, so
+// /admin/quarantine reflects the outcome of the most recent pass.
+func runArtifactValidation() {
+	quarantined := validateStackArtifacts(stackIndexStore.Snapshot())
+	quarantineStore.Set(quarantined)
+	if len(quarantined) > 0 {
+		log.Printf("artifact validation: quarantined %d stack version(s): %v", len(quarantined), quarantined)
+	} else {
+		log.Println("artifact validation: no issues found")
+	}
+}
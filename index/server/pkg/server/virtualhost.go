@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/devfile/registry-support/index/server/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// registryCatalog bundles the index file paths and in-memory snapshots (all/sample/stack) served
+// for one catalog, whether that's the default catalog configured by DEVFILE_INDEX and friends, or
+// one selected by virtual host routing.
+type registryCatalog struct {
+	indexPath        string
+	sampleIndexPath  string
+	stackIndexPath   string
+	allIndexStore    *util.IndexStore
+	sampleIndexStore *util.IndexStore
+	stackIndexStore  *util.IndexStore
+	// Canary index stores, nil unless configured. Only ever set on the default catalog; a
+	// virtual registry has no canary index paths of its own, the same way it has no base64 icon
+	// cache (see isVirtual).
+	canaryAllIndexStore    *util.IndexStore
+	canarySampleIndexStore *util.IndexStore
+	canaryStackIndexStore  *util.IndexStore
+	// isVirtual is false for the default catalog (backed by the fixed DEVFILE_INDEX and friends
+	// paths, including their base64 icon caches) and true for one selected by virtual host
+	// routing, which only has the three index paths above configured.
+	isVirtual bool
+}
+
+// path returns the on-disk index file path for indexType ("all", "stack", or "sample").
+func (rc *registryCatalog) path(indexType string) string {
+	switch indexType {
+	case string(indexSchema.StackDevfileType):
+		return rc.stackIndexPath
+	case string(indexSchema.SampleDevfileType):
+		return rc.sampleIndexPath
+	default:
+		return rc.indexPath
+	}
+}
+
+// store returns the in-memory IndexStore for indexType ("all", "stack", or "sample").
+func (rc *registryCatalog) store(indexType string) *util.IndexStore {
+	switch indexType {
+	case string(indexSchema.StackDevfileType):
+		return rc.stackIndexStore
+	case string(indexSchema.SampleDevfileType):
+		return rc.sampleIndexStore
+	default:
+		return rc.allIndexStore
+	}
+}
+
+// canaryStore returns the canary IndexStore for indexType ("all", "stack", or "sample"), or nil
+// if no canary index is configured for it.
+func (rc *registryCatalog) canaryStore(indexType string) *util.IndexStore {
+	switch indexType {
+	case string(indexSchema.StackDevfileType):
+		return rc.canaryStackIndexStore
+	case string(indexSchema.SampleDevfileType):
+		return rc.canarySampleIndexStore
+	default:
+		return rc.canaryAllIndexStore
+	}
+}
+
+// storeForRequest returns the IndexStore that should answer c for indexType: the canary store, if
+// one is configured for indexType and c is routed to it (via canaryHeader or canaryPercent), and
+// the stable store otherwise.
+func (rc *registryCatalog) storeForRequest(c *gin.Context, indexType string) *util.IndexStore {
+	canary := rc.canaryStore(indexType)
+	if canary == nil {
+		return rc.store(indexType)
+	}
+	if canaryHeader != "" && c.GetHeader(canaryHeader) != "" {
+		return canary
+	}
+	if util.ShouldShadow(canaryPercent) {
+		return canary
+	}
+	return rc.store(indexType)
+}
+
+// loadCanaryIndexStores loads the canary counterpart of each package-level index store whose
+// *IndexPath env var is set, so the default catalog can route a slice of traffic to it. A canary
+// path left unset simply leaves that index type's canary store nil, which storeForRequest treats
+// as "no canary configured" rather than an error.
+func loadCanaryIndexStores() error {
+	var err error
+	if canaryIndexPath != "" {
+		canaryAllIndexStore, err = util.NewIndexStore(canaryIndexPath)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", canaryIndexPath, err)
+		}
+	}
+	if canarySampleIndexPath != "" {
+		canarySampleIndexStore, err = util.NewIndexStore(canarySampleIndexPath)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", canarySampleIndexPath, err)
+		}
+	}
+	if canaryStackIndexPath != "" {
+		canaryStackIndexStore, err = util.NewIndexStore(canaryStackIndexPath)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", canaryStackIndexPath, err)
+		}
+	}
+	return nil
+}
+
+// virtualRegistriesConfigPath points at a JSON file mapping a hostname to the index file paths
+// for the catalog that hostname should serve, so one deployment can serve distinct catalogs (e.g.
+// registry.company.com and registry-staging.company.com) by Host header instead of requiring a
+// separate deployment per catalog.
+var virtualRegistriesConfigPath = util.GetOptionalEnv("VIRTUAL_REGISTRIES_CONFIG", "").(string)
+
+// virtualRegistryConfig is one entry of the virtualRegistriesConfigPath file.
+type virtualRegistryConfig struct {
+	IndexPath       string `json:"indexPath"`
+	SampleIndexPath string `json:"sampleIndexPath"`
+	StackIndexPath  string `json:"stackIndexPath"`
+}
+
+// virtualRegistries maps a Host header (hostname only, no port) to the registryCatalog it should
+// be served from. Populated once at startup by loadVirtualRegistries; empty (not nil) when
+// virtual host routing isn't configured, so defaultCatalog is used for every request.
+var virtualRegistries = map[string]*registryCatalog{}
+
+// defaultCatalog is the registryCatalog backed by the package-level allIndexStore/
+// sampleIndexStore/stackIndexStore, used for any request whose Host header doesn't match an
+// entry in virtualRegistries (or when virtual host routing isn't configured at all).
+func defaultCatalog() *registryCatalog {
+	return &registryCatalog{
+		indexPath:              indexPath,
+		sampleIndexPath:        sampleIndexPath,
+		stackIndexPath:         stackIndexPath,
+		allIndexStore:          allIndexStore,
+		sampleIndexStore:       sampleIndexStore,
+		stackIndexStore:        stackIndexStore,
+		canaryAllIndexStore:    canaryAllIndexStore,
+		canarySampleIndexStore: canarySampleIndexStore,
+		canaryStackIndexStore:  canaryStackIndexStore,
+	}
+}
+
+// catalogForRequest resolves which registryCatalog should answer c, based on its Host header.
+// Falls back to defaultCatalog when virtual host routing isn't configured, or the request's host
+// doesn't match any configured virtual registry.
+func catalogForRequest(c *gin.Context) *registryCatalog {
+	if len(virtualRegistries) > 0 {
+		host := c.Request.Host
+		if colonIndex := strings.LastIndex(host, ":"); colonIndex != -1 {
+			host = host[:colonIndex]
+		}
+		if catalog, ok := virtualRegistries[host]; ok {
+			return catalog
+		}
+	}
+	return defaultCatalog()
+}
+
+// loadVirtualRegistries reads virtualRegistriesConfigPath, if set, and loads an independent
+// registryCatalog for every configured hostname. Each hostname's index files are expected to
+// already be split into all/sample/stack the same way the default catalog's are (see
+// ServeRegistry), since IndexStore always serves whatever is at the path it was given.
+func loadVirtualRegistries() error {
+	if virtualRegistriesConfigPath == "" {
+		return nil
+	}
+
+	bytes, err := ioutil.ReadFile(virtualRegistriesConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", virtualRegistriesConfigPath, err)
+	}
+	var configs map[string]virtualRegistryConfig
+	if err := json.Unmarshal(bytes, &configs); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", virtualRegistriesConfigPath, err)
+	}
+
+	for host, config := range configs {
+		catalog, err := loadRegistryCatalog(config)
+		if err != nil {
+			return fmt.Errorf("failed to load virtual registry %s: %v", host, err)
+		}
+		virtualRegistries[host] = catalog
+		log.Printf("Loaded virtual registry for host %s from %s\n", host, config.IndexPath)
+	}
+	return nil
+}
+
+// loadRegistryCatalog loads the three index stores described by config.
+func loadRegistryCatalog(config virtualRegistryConfig) (*registryCatalog, error) {
+	allIndexStore, err := util.NewIndexStore(config.IndexPath)
+	if err != nil {
+		return nil, err
+	}
+	sampleIndexStore, err := util.NewIndexStore(config.SampleIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	stackIndexStore, err := util.NewIndexStore(config.StackIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	return &registryCatalog{
+		indexPath:        config.IndexPath,
+		sampleIndexPath:  config.SampleIndexPath,
+		stackIndexPath:   config.StackIndexPath,
+		allIndexStore:    allIndexStore,
+		sampleIndexStore: sampleIndexStore,
+		stackIndexStore:  stackIndexStore,
+		isVirtual:        true,
+	}, nil
+}
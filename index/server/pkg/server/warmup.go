@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// warmupPullCache pre-fetches the stacks named in pullCacheWarmupStacks into pullCache. It's
+// started as its own goroutine after the index stores are already loaded and this server is about
+// to start serving, so a slow or large warm-up list never delays startup: index requests are
+// answered immediately, with cache warming filling in behind them.
+func warmupPullCache() {
+	if !pullCacheEnabled || pullCacheWarmupStacks == "" {
+		return
+	}
+
+	index := allIndexStore.Snapshot()
+	for _, entry := range strings.Split(pullCacheWarmupStacks, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, version := entry, ""
+		if i := strings.Index(entry, ":"); i != -1 {
+			name, version = entry[:i], entry[i+1:]
+		}
+
+		versionComponent, err := findWarmupVersion(index, name, version)
+		if err != nil {
+			log.Printf("cache warmup: %v", err)
+			continue
+		}
+
+		if _, err := pullStackFromRegistry(versionComponent); err != nil {
+			log.Printf("cache warmup: failed to pre-fetch %s: %v", entry, err)
+			continue
+		}
+		log.Printf("cache warmup: pre-fetched %s", entry)
+	}
+}
+
+// findWarmupVersion finds name's version within index: the exact version, if version is
+// non-empty, otherwise name's default version.
+func findWarmupVersion(index []indexSchema.Schema, name, version string) (indexSchema.Version, error) {
+	for _, stack := range index {
+		if stack.Name != name {
+			continue
+		}
+		for _, v := range stack.Versions {
+			if version != "" && v.Version == version {
+				return v, nil
+			}
+			if version == "" && v.Default {
+				return v, nil
+			}
+		}
+		return indexSchema.Version{}, fmt.Errorf("stack %s has no matching version %q", name, version)
+	}
+	return indexSchema.Version{}, fmt.Errorf("stack %s not found in index", name)
+}
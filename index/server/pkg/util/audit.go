@@ -0,0 +1,78 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// PullRecord is one entry in an AuditTrail: a single stack pull, with just enough detail for
+// chargeback and adoption reporting without retaining anything that identifies a specific person.
+type PullRecord struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Stack      string    `json:"stack"`
+	ClientType string    `json:"clientType"`
+	ClientID   string    `json:"clientId"`
+}
+
+// AuditTrail retains the most recent pull records in memory, evicting the oldest once maxRecords
+// is reached, so a long-running server's audit trail can't grow without bound. It is not
+// persisted across restarts; a platform team that needs a durable history should export
+// regularly via the /admin/audit endpoint.
+type AuditTrail struct {
+	maxRecords int
+
+	mu      sync.Mutex
+	records []PullRecord
+}
+
+// NewAuditTrail returns an AuditTrail retaining at most maxRecords entries. A non-positive
+// maxRecords disables retention entirely; Record becomes a no-op.
+func NewAuditTrail(maxRecords int) *AuditTrail {
+	return &AuditTrail{maxRecords: maxRecords}
+}
+
+// Record appends rec to the trail, evicting the oldest record first if the trail is already at
+// capacity.
+func (a *AuditTrail) Record(rec PullRecord) {
+	if a.maxRecords <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.records) >= a.maxRecords {
+		a.records = a.records[len(a.records)-a.maxRecords+1:]
+	}
+	a.records = append(a.records, rec)
+}
+
+// Query returns every retained record with a timestamp in [from, to], oldest first. A zero from
+// or to leaves that end of the range unbounded.
+func (a *AuditTrail) Query(from, to time.Time) []PullRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var matched []PullRecord
+	for _, rec := range a.records {
+		if !from.IsZero() && rec.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && rec.Timestamp.After(to) {
+			continue
+		}
+		matched = append(matched, rec)
+	}
+	return matched
+}
+
+// AnonymizeClientID hashes raw (the "User" header value GetUser resolves, which may otherwise be
+// an email address or other identifier a caller supplies) into a stable but non-reversible id, so
+// an exported audit trail can group records by client without retaining anything that identifies
+// a specific person.
+func AnonymizeClientID(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditTrailQueryFiltersByRange(t *testing.T) {
+	trail := NewAuditTrail(10)
+	now := time.Now()
+
+	trail.Record(PullRecord{Timestamp: now.Add(-2 * time.Hour), Stack: "go"})
+	trail.Record(PullRecord{Timestamp: now.Add(-1 * time.Hour), Stack: "nodejs"})
+	trail.Record(PullRecord{Timestamp: now, Stack: "python"})
+
+	got := trail.Query(now.Add(-90*time.Minute), now.Add(-30*time.Minute))
+	if len(got) != 1 || got[0].Stack != "nodejs" {
+		t.Fatalf("expected only nodejs in range, got %v", got)
+	}
+}
+
+func TestAuditTrailQueryUnboundedRange(t *testing.T) {
+	trail := NewAuditTrail(10)
+	now := time.Now()
+
+	trail.Record(PullRecord{Timestamp: now.Add(-2 * time.Hour), Stack: "go"})
+	trail.Record(PullRecord{Timestamp: now, Stack: "python"})
+
+	got := trail.Query(time.Time{}, time.Time{})
+	if len(got) != 2 {
+		t.Fatalf("expected both records with an unbounded range, got %v", got)
+	}
+}
+
+func TestAuditTrailEvictsOldestOnceFull(t *testing.T) {
+	trail := NewAuditTrail(2)
+	now := time.Now()
+
+	trail.Record(PullRecord{Timestamp: now, Stack: "go"})
+	trail.Record(PullRecord{Timestamp: now, Stack: "nodejs"})
+	trail.Record(PullRecord{Timestamp: now, Stack: "python"})
+
+	got := trail.Query(time.Time{}, time.Time{})
+	if len(got) != 2 || got[0].Stack != "nodejs" || got[1].Stack != "python" {
+		t.Fatalf("expected the oldest record to be evicted, got %v", got)
+	}
+}
+
+func TestAuditTrailDisabledWhenMaxRecordsNotPositive(t *testing.T) {
+	trail := NewAuditTrail(0)
+	trail.Record(PullRecord{Timestamp: time.Now(), Stack: "go"})
+
+	got := trail.Query(time.Time{}, time.Time{})
+	if len(got) != 0 {
+		t.Fatalf("expected no records retained, got %v", got)
+	}
+}
+
+func TestAnonymizeClientIDIsStableAndOpaque(t *testing.T) {
+	id := AnonymizeClientID("alice@example.com")
+	if id == "alice@example.com" {
+		t.Fatal("expected the client id to be hashed, not returned verbatim")
+	}
+	if id != AnonymizeClientID("alice@example.com") {
+		t.Fatal("expected the same input to hash to the same id")
+	}
+	if id == AnonymizeClientID("bob@example.com") {
+		t.Fatal("expected different inputs to hash to different ids")
+	}
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetContentCacheHeaders sets the Content-Length, Last-Modified, and ETag response headers for
+// a response body, so clients and CDNs can cheaply check freshness and size before downloading
+// the body itself, including via HEAD requests. modTime is the time the underlying content was
+// last known to change; callers without a meaningful source (e.g. content pulled from an OCI
+// registry with no local file) should pass time.Now().
+func SetContentCacheHeaders(c *gin.Context, body []byte, modTime time.Time) {
+	c.Header("Content-Length", strconv.Itoa(len(body)))
+	c.Header("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	c.Header("ETag", fmt.Sprintf("%q", ContentETag(body)))
+}
+
+// ContentETag returns a short, stable fingerprint of body suitable for use as an ETag or as part
+// of a pagination cursor, so two reads of identical content always agree without comparing the
+// content itself.
+func ContentETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:16])
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetContentCacheHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	body := []byte(`{"hello":"world"}`)
+	modTime := time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	SetContentCacheHeaders(c, body, modTime)
+
+	if got, want := w.Header().Get("Content-Length"), "17"; got != want {
+		t.Errorf("Content-Length: got %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get("Last-Modified"), "Mon, 02 Jan 2023 03:04:05 GMT"; got != want {
+		t.Errorf("Last-Modified: got %q, want %q", got, want)
+	}
+	if got := w.Header().Get("ETag"); got == "" {
+		t.Error("expected a non-empty ETag header")
+	}
+}
+
+func TestSetContentCacheHeadersStableETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := []byte("same content")
+	modTime := time.Now()
+
+	w1 := httptest.NewRecorder()
+	c1, _ := gin.CreateTestContext(w1)
+	SetContentCacheHeaders(c1, body, modTime)
+
+	w2 := httptest.NewRecorder()
+	c2, _ := gin.CreateTestContext(w2)
+	SetContentCacheHeaders(c2, body, modTime)
+
+	if w1.Header().Get("ETag") != w2.Header().Get("ETag") {
+		t.Error("expected the same body to produce the same ETag")
+	}
+}
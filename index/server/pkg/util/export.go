@@ -0,0 +1,130 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// ExportFormat identifies one of the alternate serializations the export endpoint can produce.
+type ExportFormat string
+
+const (
+	// YAMLExportFormat serializes the index the same way the generator's stack.yaml/devfile.yaml
+	// files are marshaled, using the Schema struct's yaml tags.
+	YAMLExportFormat ExportFormat = "yaml"
+
+	// CSVExportFormat flattens the index into one row per entry, for spreadsheets and data pipelines.
+	CSVExportFormat ExportFormat = "csv"
+
+	// JSONLinesExportFormat writes one JSON-encoded entry per line, so large indexes can be streamed
+	// and processed record-by-record instead of parsed as a single JSON array.
+	JSONLinesExportFormat ExportFormat = "jsonlines"
+)
+
+// exportFormats is the set of ExportFormat values accepted by ParseExportFormat
+var exportFormats = map[ExportFormat]bool{
+	YAMLExportFormat:      true,
+	CSVExportFormat:       true,
+	JSONLinesExportFormat: true,
+}
+
+// ParseExportFormat validates that format is a known ExportFormat and returns it typed.
+func ParseExportFormat(format string) (ExportFormat, error) {
+	parsed := ExportFormat(format)
+	if !exportFormats[parsed] {
+		return "", fmt.Errorf("%q is not a supported export format", format)
+	}
+	return parsed, nil
+}
+
+// ContentType returns the HTTP Content-Type header value for the format.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case YAMLExportFormat:
+		return "application/x-yaml"
+	case CSVExportFormat:
+		return "text/csv"
+	case JSONLinesExportFormat:
+		return "application/x-ndjson"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// exportCSVHeader lists the columns written by IndexToCSV, in order.
+var exportCSVHeader = []string{"name", "displayName", "version", "type", "projectType", "language", "tags", "architectures", "maturity", "deprecated"}
+
+// ExportIndex serializes index into the given format, using the same Schema struct (and, for
+// yaml, the same struct tags) the generator uses to read and write stack.yaml/devfile.yaml.
+func ExportIndex(index []indexSchema.Schema, format ExportFormat) ([]byte, error) {
+	switch format {
+	case YAMLExportFormat:
+		return yaml.Marshal(index)
+	case CSVExportFormat:
+		return indexToCSV(index)
+	case JSONLinesExportFormat:
+		return indexToJSONLines(index)
+	default:
+		return nil, fmt.Errorf("%q is not a supported export format", format)
+	}
+}
+
+func indexToCSV(index []indexSchema.Schema) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(exportCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, entry := range index {
+		if err := w.Write([]string{
+			entry.Name,
+			entry.DisplayName,
+			entry.Version,
+			string(entry.Type),
+			entry.ProjectType,
+			entry.Language,
+			strings.Join(entry.Tags, ";"),
+			strings.Join(entry.Architectures, ";"),
+			string(entry.Maturity),
+			strconv.FormatBool(entryDeprecated(entry)),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// entryDeprecated reports whether the entry's default version (or, once collapsed to the old
+// index format, the entry itself) is deprecated.
+func entryDeprecated(entry indexSchema.Schema) bool {
+	for _, version := range entry.Versions {
+		if version.Default {
+			return version.Deprecated
+		}
+	}
+	return false
+}
+
+func indexToJSONLines(index []indexSchema.Schema) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range index {
+		if err := encoder.Encode(entry); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func testExportIndex() []indexSchema.Schema {
+	return []indexSchema.Schema{
+		{
+			Name:          "java-maven",
+			DisplayName:   "Maven Java",
+			Type:          indexSchema.StackDevfileType,
+			Tags:          []string{"Java", "Maven"},
+			Architectures: []string{"amd64", "arm64"},
+			Maturity:      indexSchema.StableMaturity,
+			Versions: []indexSchema.Version{
+				{Version: "1.1.0", Default: true, Deprecated: true},
+			},
+		},
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	if _, err := ParseExportFormat("csv"); err != nil {
+		t.Errorf("expected csv to be a valid export format, got error: %v", err)
+	}
+	if _, err := ParseExportFormat("xml"); err == nil {
+		t.Error("expected xml to be rejected as an unsupported export format")
+	}
+}
+
+func TestExportIndexYAML(t *testing.T) {
+	bytes, err := ExportIndex(testExportIndex(), YAMLExportFormat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(bytes), "name: java-maven") {
+		t.Errorf("expected yaml output to contain the entry name, got: %s", bytes)
+	}
+}
+
+func TestExportIndexCSV(t *testing.T) {
+	bytes, err := ExportIndex(testExportIndex(), CSVExportFormat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(bytes), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header row and one data row, got %d lines: %v", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[1], "java-maven,Maven Java,,stack,,,Java;Maven,amd64;arm64,stable,true") {
+		t.Errorf("unexpected csv row: %s", lines[1])
+	}
+}
+
+func TestExportIndexJSONLines(t *testing.T) {
+	index := testExportIndex()
+	index = append(index, indexSchema.Schema{Name: "nodejs", Type: indexSchema.StackDevfileType})
+
+	bytes, err := ExportIndex(index, JSONLinesExportFormat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(bytes), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per entry, got %d lines: %v", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], `"name":"java-maven"`) {
+		t.Errorf("expected first line to describe java-maven, got: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"name":"nodejs"`) {
+		t.Errorf("expected second line to describe nodejs, got: %s", lines[1])
+	}
+}
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"strings"
+
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
 )
 
@@ -41,3 +43,40 @@ func FilterDevfileArchitectures(index []indexSchema.Schema, archs []string) []in
 
 	return index
 }
+
+// FilterDevfileSearch filters index down to the entries whose name, display name, description, or
+// tags contain query, case-insensitively. It's a baseline text-field search, matching only the
+// index metadata already loaded in memory; a caller that also wants to match devfile/README
+// content has to layer that on separately, since that content isn't part of the index schema.
+func FilterDevfileSearch(index []indexSchema.Schema, query string) []indexSchema.Schema {
+	if query == "" {
+		return index
+	}
+
+	query = strings.ToLower(query)
+	matched := make([]indexSchema.Schema, 0, len(index))
+	for _, devfile := range index {
+		if matchesSearchFields(devfile, query) {
+			matched = append(matched, devfile)
+		}
+	}
+	return matched
+}
+
+func matchesSearchFields(devfile indexSchema.Schema, lowerQuery string) bool {
+	if strings.Contains(strings.ToLower(devfile.Name), lowerQuery) {
+		return true
+	}
+	if strings.Contains(strings.ToLower(devfile.DisplayName), lowerQuery) {
+		return true
+	}
+	if strings.Contains(strings.ToLower(devfile.Description), lowerQuery) {
+		return true
+	}
+	for _, tag := range devfile.Tags {
+		if strings.Contains(strings.ToLower(tag), lowerQuery) {
+			return true
+		}
+	}
+	return false
+}
@@ -112,3 +112,56 @@ func TestFilterDevfileArchitectures(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterDevfileSearch(t *testing.T) {
+	index := []indexSchema.Schema{
+		{Name: "go", DisplayName: "Go Runtime", Description: "A stack for Go apps", Tags: []string{"Go"}},
+		{Name: "python", DisplayName: "Python", Description: "A stack for Python apps", Tags: []string{"Python", "Flask"}},
+		{Name: "nodejs", DisplayName: "Node.js Runtime", Description: "A stack for Node apps", Tags: []string{"NodeJS"}},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantNames []string
+	}{
+		{
+			name:      "matches by name",
+			query:     "go",
+			wantNames: []string{"go"},
+		},
+		{
+			name:      "matches by tag, case-insensitively",
+			query:     "flask",
+			wantNames: []string{"python"},
+		},
+		{
+			name:      "matches by description",
+			query:     "node apps",
+			wantNames: []string{"nodejs"},
+		},
+		{
+			name:      "empty query returns everything",
+			query:     "",
+			wantNames: []string{"go", "python", "nodejs"},
+		},
+		{
+			name:      "no match",
+			query:     "postgresql",
+			wantNames: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FilterDevfileSearch(index, test.query)
+			gotNames := make([]string, 0, len(got))
+			for _, devfile := range got {
+				gotNames = append(gotNames, devfile.Name)
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("Got: %v, Expected: %v", gotNames, test.wantNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PopularityTracker maintains an exponentially-decaying pull count per stack name, so a
+// popularity ranking can weigh recent pulls more heavily than old ones without storing and
+// re-scanning a full event history on every request.
+type PopularityTracker struct {
+	halfLife time.Duration
+
+	mu     sync.Mutex
+	scores map[string]*decayingScore
+}
+
+type decayingScore struct {
+	value      float64
+	lastUpdate time.Time
+}
+
+// NewPopularityTracker returns a tracker whose recorded scores halve every halfLife. A non-positive
+// halfLife disables decay, so scores accumulate for as long as the process runs.
+func NewPopularityTracker(halfLife time.Duration) *PopularityTracker {
+	return &PopularityTracker{halfLife: halfLife, scores: make(map[string]*decayingScore)}
+}
+
+// Record registers one pull of name at the given time.
+func (t *PopularityTracker) Record(name string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	score, ok := t.scores[name]
+	if !ok {
+		score = &decayingScore{}
+		t.scores[name] = score
+	}
+	score.value = t.decayedLocked(score, at) + 1
+	score.lastUpdate = at
+}
+
+// Top returns up to limit stack names ordered by decayed pull score, highest first. A limit <= 0
+// returns every name that has ever been recorded.
+func (t *PopularityTracker) Top(limit int, at time.Time) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	type entry struct {
+		name  string
+		value float64
+	}
+	entries := make([]entry, 0, len(t.scores))
+	for name, score := range t.scores {
+		entries = append(entries, entry{name: name, value: t.decayedLocked(score, at)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].value != entries[j].value {
+			return entries[i].value > entries[j].value
+		}
+		return entries[i].name < entries[j].name
+	})
+
+	if limit <= 0 || limit > len(entries) {
+		limit = len(entries)
+	}
+	names := make([]string, limit)
+	for i := 0; i < limit; i++ {
+		names[i] = entries[i].name
+	}
+	return names
+}
+
+// decayedLocked returns score's value decayed from its lastUpdate time to at. Callers must hold t.mu.
+func (t *PopularityTracker) decayedLocked(score *decayingScore, at time.Time) float64 {
+	if t.halfLife <= 0 || score.lastUpdate.IsZero() {
+		return score.value
+	}
+	elapsed := at.Sub(score.lastUpdate)
+	if elapsed <= 0 {
+		return score.value
+	}
+	return score.value * math.Pow(0.5, elapsed.Seconds()/t.halfLife.Seconds())
+}
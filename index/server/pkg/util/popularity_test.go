@@ -0,0 +1,53 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPopularityTrackerRanksByPullCount(t *testing.T) {
+	tracker := NewPopularityTracker(time.Hour)
+	now := time.Now()
+
+	tracker.Record("nodejs", now)
+	tracker.Record("nodejs", now)
+	tracker.Record("go", now)
+
+	top := tracker.Top(10, now)
+	if len(top) != 2 || top[0] != "nodejs" || top[1] != "go" {
+		t.Fatalf("expected [nodejs go], got %v", top)
+	}
+}
+
+func TestPopularityTrackerLimit(t *testing.T) {
+	tracker := NewPopularityTracker(time.Hour)
+	now := time.Now()
+
+	tracker.Record("nodejs", now)
+	tracker.Record("go", now)
+	tracker.Record("python", now)
+
+	top := tracker.Top(2, now)
+	if len(top) != 2 {
+		t.Fatalf("expected 2 entries, got %v", top)
+	}
+}
+
+func TestPopularityTrackerDecaysOldPulls(t *testing.T) {
+	tracker := NewPopularityTracker(time.Hour)
+	now := time.Now()
+
+	// go accumulates many pulls a long time ago; nodejs gets fewer, recent pulls.
+	tracker.Record("go", now)
+	for i := 0; i < 4; i++ {
+		tracker.Record("go", now)
+	}
+	later := now.Add(10 * time.Hour)
+	tracker.Record("nodejs", later)
+	tracker.Record("nodejs", later)
+
+	top := tracker.Top(1, later)
+	if len(top) != 1 || top[0] != "nodejs" {
+		t.Fatalf("expected decayed pulls to rank nodejs first, got %v", top)
+	}
+}
@@ -0,0 +1,35 @@
+package util
+
+import "sync/atomic"
+
+// QuarantinedEntry records a stack version whose devfile artifact failed an artifact validation
+// pass, along with why, so admins learn about a broken artifact from an admin endpoint instead
+// of a client discovering it the hard way, at pull time.
+type QuarantinedEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// QuarantineStore holds the most recent set of quarantined entries, atomically swappable so a
+// validation pass in progress never hands a reader a half-updated list.
+type QuarantineStore struct {
+	val atomic.Value
+}
+
+// NewQuarantineStore returns a QuarantineStore with an empty quarantine list.
+func NewQuarantineStore() *QuarantineStore {
+	store := &QuarantineStore{}
+	store.Set(nil)
+	return store
+}
+
+// Set atomically publishes entries as the current quarantine list.
+func (s *QuarantineStore) Set(entries []QuarantinedEntry) {
+	s.val.Store(entries)
+}
+
+// Snapshot returns the quarantine list as of the most recent Set call.
+func (s *QuarantineStore) Snapshot() []QuarantinedEntry {
+	return s.val.Load().([]QuarantinedEntry)
+}
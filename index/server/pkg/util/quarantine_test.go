@@ -0,0 +1,18 @@
+package util
+
+import "testing"
+
+func TestQuarantineStore(t *testing.T) {
+	store := NewQuarantineStore()
+	if got := store.Snapshot(); len(got) != 0 {
+		t.Errorf("expected an empty quarantine list, got %v", got)
+	}
+
+	entries := []QuarantinedEntry{{Name: "nodejs", Version: "1.0.0", Reason: "devfile is not valid YAML"}}
+	store.Set(entries)
+
+	got := store.Snapshot()
+	if len(got) != 1 || got[0] != entries[0] {
+		t.Errorf("got %v, want %v", got, entries)
+	}
+}
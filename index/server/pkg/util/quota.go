@@ -0,0 +1,87 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// NamespaceQuota describes the limits enforced for a single namespace (the portion of a
+// stack name before the first "/", e.g. "team-a/java-quarkus"). Stacks without a namespace
+// prefix are grouped under the "default" namespace and are not subject to quota enforcement,
+// preserving current behavior for existing single-tenant registries.
+type NamespaceQuota struct {
+	// MaxStacks is the maximum number of stacks a namespace may own. Zero means unlimited.
+	MaxStacks int
+	// MaxBytes is the maximum total size, in bytes, of all resources a namespace may own. Zero means unlimited.
+	MaxBytes int64
+}
+
+// NamespaceUsage reports the current resource consumption of a namespace against its quota.
+type NamespaceUsage struct {
+	Namespace  string `json:"namespace"`
+	StackCount int    `json:"stackCount"`
+	TotalBytes int64  `json:"totalBytes"`
+	MaxStacks  int    `json:"maxStacks,omitempty"`
+	MaxBytes   int64  `json:"maxBytes,omitempty"`
+}
+
+// QuotaExceededError is returned when an operation would push a namespace over one of its quotas
+type QuotaExceededError struct {
+	Namespace string
+	Reason    string
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("namespace %s exceeded its quota: %s", e.Namespace, e.Reason)
+}
+
+const defaultNamespace = "default"
+
+// NamespaceOf returns the namespace a stack belongs to, derived from the "<namespace>/<stack>"
+// naming convention. Stacks with no "/" belong to the default namespace.
+func NamespaceOf(stackName string) string {
+	if idx := strings.Index(stackName, "/"); idx > 0 {
+		return stackName[:idx]
+	}
+	return defaultNamespace
+}
+
+// ComputeNamespaceUsage tallies the stack count and total resource bytes owned by a namespace
+// given the current index contents and the on-disk size of each stack version's resources.
+func ComputeNamespaceUsage(index []indexSchema.Schema, namespace string, resourceSizer func(stackName string, version indexSchema.Version) int64) NamespaceUsage {
+	usage := NamespaceUsage{Namespace: namespace}
+	for _, entry := range index {
+		if entry.Type != indexSchema.StackDevfileType || NamespaceOf(entry.Name) != namespace {
+			continue
+		}
+		usage.StackCount++
+		for _, version := range entry.Versions {
+			if resourceSizer != nil {
+				usage.TotalBytes += resourceSizer(entry.Name, version)
+			}
+		}
+	}
+	return usage
+}
+
+// CheckNamespaceQuota returns a QuotaExceededError if adding a new stack, or newBytes worth of
+// resources, to namespace would exceed the given quota. Callers (e.g. an admin push API) should
+// invoke this before accepting new content for a namespace.
+func CheckNamespaceQuota(usage NamespaceUsage, quota NamespaceQuota, addingStack bool, newBytes int64) error {
+	if usage.Namespace == defaultNamespace {
+		return nil
+	}
+	stackCount := usage.StackCount
+	if addingStack {
+		stackCount++
+	}
+	if quota.MaxStacks > 0 && stackCount > quota.MaxStacks {
+		return &QuotaExceededError{Namespace: usage.Namespace, Reason: fmt.Sprintf("stack count %d exceeds limit of %d", stackCount, quota.MaxStacks)}
+	}
+	if quota.MaxBytes > 0 && usage.TotalBytes+newBytes > quota.MaxBytes {
+		return &QuotaExceededError{Namespace: usage.Namespace, Reason: fmt.Sprintf("total artifact size %d bytes exceeds limit of %d bytes", usage.TotalBytes+newBytes, quota.MaxBytes)}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package util
+
+import (
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestNamespaceOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		stackName string
+		want      string
+	}{
+		{name: "namespaced stack", stackName: "team-a/java-quarkus", want: "team-a"},
+		{name: "unnamespaced stack", stackName: "java-quarkus", want: defaultNamespace},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NamespaceOf(test.stackName); got != test.want {
+				t.Errorf("Got: %v, Expected: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckNamespaceQuota(t *testing.T) {
+	tests := []struct {
+		name        string
+		usage       NamespaceUsage
+		quota       NamespaceQuota
+		addingStack bool
+		newBytes    int64
+		wantErr     bool
+	}{
+		{
+			name:  "unlimited quota never exceeded",
+			usage: NamespaceUsage{StackCount: 100, TotalBytes: 1 << 30},
+			quota: NamespaceQuota{},
+		},
+		{
+			name:        "stack count exceeded",
+			usage:       NamespaceUsage{StackCount: 2},
+			quota:       NamespaceQuota{MaxStacks: 2},
+			addingStack: true,
+			wantErr:     true,
+		},
+		{
+			name:     "byte quota exceeded",
+			usage:    NamespaceUsage{TotalBytes: 900},
+			quota:    NamespaceQuota{MaxBytes: 1000},
+			newBytes: 200,
+			wantErr:  true,
+		},
+		{
+			name:     "within limits",
+			usage:    NamespaceUsage{StackCount: 1, TotalBytes: 100},
+			quota:    NamespaceQuota{MaxStacks: 5, MaxBytes: 1000},
+			newBytes: 100,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckNamespaceQuota(test.usage, test.quota, test.addingStack, test.newBytes)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Got error: %v, want error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestComputeNamespaceUsage(t *testing.T) {
+	index := []indexSchema.Schema{
+		{
+			Name: "team-a/java-quarkus",
+			Type: indexSchema.StackDevfileType,
+			Versions: []indexSchema.Version{
+				{Version: "1.0.0"},
+			},
+		},
+		{
+			Name: "team-b/java-maven",
+			Type: indexSchema.StackDevfileType,
+		},
+		{
+			Name: "some-sample",
+			Type: indexSchema.SampleDevfileType,
+		},
+	}
+
+	usage := ComputeNamespaceUsage(index, "team-a", func(stackName string, version indexSchema.Version) int64 {
+		return 42
+	})
+
+	if usage.StackCount != 1 {
+		t.Errorf("Got stack count: %v, expected: 1", usage.StackCount)
+	}
+	if usage.TotalBytes != 42 {
+		t.Errorf("Got total bytes: %v, expected: 42", usage.TotalBytes)
+	}
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// shadowRequestTimeout bounds how long a mirrored shadow request may take. It has no effect on
+// the client-facing response, which has already been sent by the time a mirror fires.
+const shadowRequestTimeout = 10 * time.Second
+
+// ShouldShadow reports whether a request should be mirrored to the shadow registry, sampled at
+// samplePercent (0-100).
+func ShouldShadow(samplePercent int) bool {
+	if samplePercent <= 0 {
+		return false
+	}
+	if samplePercent >= 100 {
+		return true
+	}
+	return rand.Intn(100) < samplePercent
+}
+
+// MirrorRequest fires a copy of an already-served GET/HEAD request at shadowURL and compares the
+// mirrored response's status code against the primary's, logging any mismatch. It is fire-and-
+// forget: the caller does not wait on it and its outcome never affects a response already sent to
+// the client. Meant to be invoked in its own goroutine.
+func MirrorRequest(shadowURL, method, path string, primaryStatus int) {
+	client := http.Client{Timeout: shadowRequestTimeout}
+	req, err := http.NewRequest(method, strings.TrimSuffix(shadowURL, "/")+path, nil)
+	if err != nil {
+		log.Printf("shadow: failed to build request to %s: %v", shadowURL, err)
+		return
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("shadow: request to %s failed: %v", shadowURL, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != primaryStatus {
+		log.Printf("shadow: response mismatch for %s %s: primary=%d shadow=%d", method, path, primaryStatus, resp.StatusCode)
+	}
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestShouldShadow(t *testing.T) {
+	tests := []struct {
+		name          string
+		samplePercent int
+		want          bool
+	}{
+		{"zero percent never shadows", 0, false},
+		{"negative percent never shadows", -5, false},
+		{"100 percent always shadows", 100, true},
+		{"above 100 percent always shadows", 150, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ShouldShadow(tt.samplePercent)
+			if got != tt.want {
+				t.Errorf("ShouldShadow(%d) = %v, want %v", tt.samplePercent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirrorRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index" {
+			t.Errorf("Expected path /index, got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// MirrorRequest is fire-and-forget and only logs on mismatch, so this just exercises the
+	// happy path for panics/errors.
+	MirrorRequest(server.URL, http.MethodGet, "/index", http.StatusOK)
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// IndexStoreStatus reports whether an IndexStore is serving fresh data. A failed Reload leaves
+// the store serving its last good Snapshot, so Stale being true doesn't mean the store has no
+// data to serve, only that the data may be out of date.
+type IndexStoreStatus struct {
+	Stale         bool
+	LastSuccess   time.Time
+	LastError     string
+	LastErrorTime time.Time
+}
+
+// IndexStore holds an immutable snapshot of a parsed index file that can be swapped atomically,
+// so concurrent requests read a consistent, point-in-time view without taking a lock on the hot
+// path, and a reload never hands out a slice that's half old, half new.
+type IndexStore struct {
+	path   string
+	val    atomic.Value
+	status atomic.Value
+
+	// serialized caches the old-index-format serialization of val, computed once per Reload
+	// instead of on every request, since the unfiltered, non-paginated response (the large
+	// majority of index requests) is otherwise a full re-marshal of the whole catalog per hit.
+	serialized atomic.Value
+}
+
+// NewIndexStore reads indexPath and returns a Store snapshotting it.
+func NewIndexStore(indexPath string) (*IndexStore, error) {
+	store := &IndexStore{path: indexPath}
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Snapshot returns the index as of the most recent successful Reload. The returned slice is
+// never mutated in place, so callers can range over it freely even while a reload is in flight.
+func (s *IndexStore) Snapshot() []indexSchema.Schema {
+	return s.val.Load().([]indexSchema.Schema)
+}
+
+// SerializedSnapshot returns the old-index-format JSON serialization of Snapshot, indented the
+// same way an unfiltered index response is, computed once at the most recent successful Reload
+// rather than on every call. Callers that need to filter or paginate the index should marshal
+// Snapshot themselves instead; this is only correct for the unfiltered, non-paginated response.
+func (s *IndexStore) SerializedSnapshot() []byte {
+	return s.serialized.Load().([]byte)
+}
+
+// Status reports whether this store is currently serving stale data, and details of its most
+// recent successful and failed reloads.
+func (s *IndexStore) Status() IndexStoreStatus {
+	if status, ok := s.status.Load().(IndexStoreStatus); ok {
+		return status
+	}
+	return IndexStoreStatus{}
+}
+
+// Reload re-reads the index file from disk and atomically publishes it as the new snapshot.
+// In-flight Snapshot() calls keep observing the previous version until this returns. If the
+// re-read fails, the store keeps serving its last good snapshot and is marked stale instead of
+// being left without a valid catalog.
+func (s *IndexStore) Reload() error {
+	index, err := ReadIndexPath(s.path)
+	if err != nil {
+		status := s.Status()
+		status.Stale = true
+		status.LastError = err.Error()
+		status.LastErrorTime = time.Now()
+		s.status.Store(status)
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(ConvertToOldIndexFormat(index)); err != nil {
+		return err
+	}
+	serialized := bytes.TrimRight(buf.Bytes(), "\n")
+
+	s.val.Store(index)
+	s.serialized.Store(serialized)
+	s.status.Store(IndexStoreStatus{LastSuccess: time.Now()})
+	return nil
+}
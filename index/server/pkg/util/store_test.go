@@ -0,0 +1,137 @@
+package util
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestIndexStoreSnapshotAndReload(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "index-store-test")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(`[{"name":"nodejs"}]`); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		t.Fatalf("failed to close temp file: %v", err)
+	}
+
+	store, err := NewIndexStore(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("NewIndexStore returned error: %v", err)
+	}
+
+	snapshot := store.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Name != "nodejs" {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	if err := ioutil.WriteFile(tmpFile.Name(), []byte(`[{"name":"nodejs"},{"name":"python"}]`), 0644); err != nil {
+		t.Fatalf("failed to update temp file: %v", err)
+	}
+
+	// The snapshot taken before Reload must be unaffected by the on-disk update.
+	if len(snapshot) != 1 {
+		t.Fatalf("earlier snapshot was mutated: %+v", snapshot)
+	}
+
+	if err := store.Reload(); err != nil {
+		t.Fatalf("Reload returned error: %v", err)
+	}
+
+	reloaded := store.Snapshot()
+	if len(reloaded) != 2 {
+		t.Fatalf("expected 2 entries after reload, got %d", len(reloaded))
+	}
+}
+
+func TestIndexStoreSerializedSnapshot(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "index-store-test")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(`[{"name":"nodejs"}]`); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		t.Fatalf("failed to close temp file: %v", err)
+	}
+
+	store, err := NewIndexStore(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("NewIndexStore returned error: %v", err)
+	}
+
+	want, err := json.MarshalIndent(ConvertToOldIndexFormat(store.Snapshot()), "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal expected serialization: %v", err)
+	}
+	if got := store.SerializedSnapshot(); string(got) != string(want) {
+		t.Fatalf("SerializedSnapshot() = %s, want %s", got, want)
+	}
+
+	if err := ioutil.WriteFile(tmpFile.Name(), []byte(`[{"name":"nodejs"},{"name":"python"}]`), 0644); err != nil {
+		t.Fatalf("failed to update temp file: %v", err)
+	}
+	if err := store.Reload(); err != nil {
+		t.Fatalf("Reload returned error: %v", err)
+	}
+
+	want, err = json.MarshalIndent(ConvertToOldIndexFormat(store.Snapshot()), "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal expected serialization: %v", err)
+	}
+	if got := store.SerializedSnapshot(); string(got) != string(want) {
+		t.Fatalf("SerializedSnapshot() after Reload = %s, want %s", got, want)
+	}
+}
+
+func TestIndexStoreFallsBackToLastGoodSnapshotOnReloadFailure(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "index-store-test")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(`[{"name":"nodejs"}]`); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		t.Fatalf("failed to close temp file: %v", err)
+	}
+
+	store, err := NewIndexStore(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("NewIndexStore returned error: %v", err)
+	}
+	if store.Status().Stale {
+		t.Fatalf("expected a freshly loaded store not to be stale")
+	}
+
+	if err := ioutil.WriteFile(tmpFile.Name(), []byte(`not valid json`), 0644); err != nil {
+		t.Fatalf("failed to corrupt temp file: %v", err)
+	}
+
+	if err := store.Reload(); err == nil {
+		t.Fatalf("expected Reload to fail on invalid json")
+	}
+
+	if !store.Status().Stale {
+		t.Fatalf("expected store to be marked stale after a failed reload")
+	}
+	if store.Status().LastError == "" {
+		t.Fatalf("expected LastError to be populated after a failed reload")
+	}
+
+	snapshot := store.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Name != "nodejs" {
+		t.Fatalf("expected the last good snapshot to still be served, got: %+v", snapshot)
+	}
+}
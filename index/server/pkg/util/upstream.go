@@ -0,0 +1,84 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// UpstreamStatus reports the health of a single upstream registry, for federation operators to
+// see at a glance which upstreams are stale or failing.
+type UpstreamStatus struct {
+	// URL is the upstream registry's base URL
+	URL string `json:"url"`
+	// Reachable is true if the upstream responded with a valid index within the request timeout
+	Reachable bool `json:"reachable"`
+	// EntryCount is the number of index entries the upstream reported, if reachable
+	EntryCount int `json:"entryCount,omitempty"`
+	// LatencyMs is how long the health check request took, in milliseconds
+	LatencyMs int64 `json:"latencyMs"`
+	// Error describes why the upstream was not reachable, if it wasn't
+	Error string `json:"error,omitempty"`
+	// CheckedAt is when this status was collected. There is no persisted sync history yet, so
+	// this reflects an on-demand check rather than a background sync timestamp.
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// upstreamCheckTimeout bounds how long a single upstream health check may take, so one
+// unreachable upstream can't stall the whole dashboard response.
+const upstreamCheckTimeout = 5 * time.Second
+
+// CheckUpstream fetches the index of an upstream registry and reports its reachability, entry
+// count, and latency.
+func CheckUpstream(upstreamURL string) UpstreamStatus {
+	status := UpstreamStatus{
+		URL:       upstreamURL,
+		CheckedAt: time.Now(),
+	}
+
+	client := http.Client{Timeout: upstreamCheckTimeout}
+	start := time.Now()
+	resp, err := client.Get(strings.TrimSuffix(upstreamURL, "/") + "/index")
+	status.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		status.Error = fmt.Sprintf("unexpected status %s", resp.Status)
+		return status
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	var index []indexSchema.Schema
+	if err := json.Unmarshal(body, &index); err != nil {
+		status.Error = fmt.Sprintf("failed to parse index: %v", err)
+		return status
+	}
+
+	status.Reachable = true
+	status.EntryCount = len(index)
+	return status
+}
+
+// CheckUpstreams checks each upstream registry independently and returns their statuses in the
+// same order as upstreamURLs.
+func CheckUpstreams(upstreamURLs []string) []UpstreamStatus {
+	statuses := make([]UpstreamStatus, len(upstreamURLs))
+	for i, upstreamURL := range upstreamURLs {
+		statuses[i] = CheckUpstream(upstreamURL)
+	}
+	return statuses
+}
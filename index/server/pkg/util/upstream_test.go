@@ -0,0 +1,68 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckUpstreamReachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name":"a"},{"name":"b"}]`))
+	}))
+	defer server.Close()
+
+	status := CheckUpstream(server.URL)
+	if !status.Reachable {
+		t.Fatalf("expected upstream to be reachable, got error: %v", status.Error)
+	}
+	if status.EntryCount != 2 {
+		t.Errorf("EntryCount: got %d, want 2", status.EntryCount)
+	}
+	if status.Error != "" {
+		t.Errorf("expected no error, got %q", status.Error)
+	}
+}
+
+func TestCheckUpstreamUnreachable(t *testing.T) {
+	status := CheckUpstream("http://127.0.0.1:0")
+	if status.Reachable {
+		t.Error("expected an unreachable upstream to report Reachable=false")
+	}
+	if status.Error == "" {
+		t.Error("expected an error message for an unreachable upstream")
+	}
+}
+
+func TestCheckUpstreamBadStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	status := CheckUpstream(server.URL)
+	if status.Reachable {
+		t.Error("expected Reachable=false on a non-200 response")
+	}
+	if status.Error == "" {
+		t.Error("expected an error message on a non-200 response")
+	}
+}
+
+func TestCheckUpstreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	statuses := CheckUpstreams([]string{server.URL, "http://127.0.0.1:0"})
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(statuses))
+	}
+	if !statuses[0].Reachable {
+		t.Error("expected the first upstream to be reachable")
+	}
+	if statuses[1].Reachable {
+		t.Error("expected the second upstream to be unreachable")
+	}
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 
 	indexLibrary "github.com/devfile/registry-support/index/generator/library"
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
 )
 
 // IsHtmlRequested checks the accept header if html has been requested
@@ -142,6 +144,23 @@ func GetOptionalEnv(key string, defaultValue interface{}) interface{} {
 	return defaultValue
 }
 
+// SetDeprecationHeaders sets the Deprecation and Sunset response headers, along with a successor
+// Link header, when the given stack version has been marked as deprecated in the index metadata.
+// This allows automated clients to warn users and suggest a replacement stack version.
+func SetDeprecationHeaders(c *gin.Context, version indexSchema.Version) {
+	if !version.Deprecated {
+		return
+	}
+
+	c.Header("Deprecation", "true")
+	if version.SunsetDate != "" {
+		c.Header("Sunset", version.SunsetDate)
+	}
+	if version.Successor != "" {
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", version.Successor))
+	}
+}
+
 func ConvertToOldIndexFormat(schemaList []indexSchema.Schema) []indexSchema.Schema {
 	var oldSchemaList []indexSchema.Schema
 	for _, schema := range schemaList {
@@ -3,7 +3,9 @@ package util
 import (
 	"encoding/json"
 	"github.com/devfile/registry-support/index/generator/schema"
+	"github.com/gin-gonic/gin"
 	"io/ioutil"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"testing"
@@ -225,4 +227,51 @@ func TestConvertToOldIndexFormat(t *testing.T) {
 			t.Errorf("Want index %v, got index %v", wantIndex, gotIndex)
 		}
 	})
+}
+
+func TestSetDeprecationHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     schema.Version
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "not deprecated",
+			version:     schema.Version{Version: "1.0.0"},
+			wantHeaders: map[string]string{},
+		},
+		{
+			name: "deprecated with sunset date and successor",
+			version: schema.Version{
+				Version:    "1.0.0",
+				Deprecated: true,
+				SunsetDate: "2023-01-01",
+				Successor:  "2.0.0",
+			},
+			wantHeaders: map[string]string{
+				"Deprecation": "true",
+				"Sunset":      "2023-01-01",
+				"Link":        `<2.0.0>; rel="successor-version"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			SetDeprecationHeaders(c, test.version)
+
+			for header, want := range test.wantHeaders {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("header %s: got %q, want %q", header, got, want)
+				}
+			}
+			if len(test.wantHeaders) == 0 && w.Header().Get("Deprecation") != "" {
+				t.Errorf("expected no Deprecation header, got %q", w.Header().Get("Deprecation"))
+			}
+		})
+	}
 }
\ No newline at end of file
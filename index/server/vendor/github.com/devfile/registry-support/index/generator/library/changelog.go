@@ -0,0 +1,115 @@
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// changelogFileName is the file a per-stack version changelog is written under, alongside
+// devfile.yaml, so it packages into the stack's archive.tar the same way any other resource does.
+const changelogFileName = "CHANGELOG.md"
+
+// GenerateChangelogs writes a CHANGELOG.md for each stack version summarizing the git commits
+// that touched that version's directory since the last tag reachable from HEAD (or the version's
+// full history, if the checkout has no tags), and records it on the index entry as both a
+// resource and a changelogUrl. registryDirPath must be inside a git checkout; a version outside
+// one, or with no matching commits, is left without a changelog.
+func GenerateChangelogs(registryDirPath string, index []schema.Schema) error {
+	lastTag := lastGitTag(registryDirPath)
+
+	for i := range index {
+		if index[i].Type != schema.StackDevfileType {
+			continue
+		}
+		for j := range index[i].Versions {
+			version := &index[i].Versions[j]
+			versionDir := stackVersionDir(registryDirPath, index[i].Name, version.Version)
+
+			changelog, err := gitChangelog(registryDirPath, versionDir, lastTag)
+			if err != nil {
+				return fmt.Errorf("failed to generate changelog for %s version %s: %v", index[i].Name, version.Version, err)
+			}
+			if changelog == "" {
+				continue
+			}
+
+			changelogPath := filepath.Join(versionDir, changelogFileName)
+			if err := ioutil.WriteFile(changelogPath, []byte(changelog), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %v", changelogPath, err)
+			}
+
+			if !inArray(version.Resources, changelogFileName) {
+				version.Resources = append(version.Resources, changelogFileName)
+			}
+			// The changelog is packaged as a resource alongside the devfile, not served at its
+			// own HTTP endpoint, so changelogUrl points at it the same way Links["self"] points
+			// at the stack version's artifact: an OCI-style ref plus the resource's file name.
+			version.ChangelogUrl = fmt.Sprintf("%s#%s", version.Links["self"], changelogFileName)
+		}
+	}
+	return nil
+}
+
+// stackVersionDir returns the on-disk directory for a stack version, matching
+// parseDevfileRegistry's two supported layouts: stacks/<name>/<version> (stack.yaml present) or
+// stacks/<name> (a bare devfile.yaml, single implicit version).
+func stackVersionDir(registryDirPath, stackName, version string) string {
+	versionedDir := filepath.Join(registryDirPath, "stacks", stackName, version)
+	if fileExists(versionedDir) {
+		return versionedDir
+	}
+	return filepath.Join(registryDirPath, "stacks", stackName)
+}
+
+// lastGitTag returns the most recent tag reachable from HEAD in repoDir, or "" if repoDir isn't a
+// git checkout or has no tags.
+func lastGitTag(repoDir string) string {
+	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// gitChangelog renders a Markdown changelog of the commits touching targetDir since sinceTag (or
+// its full history, if sinceTag is ""), or "" if repoDir isn't a git checkout or there are no
+// matching commits.
+func gitChangelog(repoDir, targetDir, sinceTag string) (string, error) {
+	relDir, err := filepath.Rel(repoDir, targetDir)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"log", "--oneline"}
+	if sinceTag != "" {
+		args = append(args, sinceTag+"..HEAD")
+	}
+	args = append(args, "--", relDir)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", nil
+	}
+
+	commits := strings.TrimSpace(string(output))
+	if commits == "" {
+		return "", nil
+	}
+
+	var changelog bytes.Buffer
+	changelog.WriteString("# Changelog\n\n")
+	for _, commit := range strings.Split(commits, "\n") {
+		changelog.WriteString(fmt.Sprintf("- %s\n", commit))
+	}
+	return changelog.String(), nil
+}
@@ -0,0 +1,130 @@
+package library
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// ContentHash is a hash of a stack folder's file contents, used by incremental generation to
+// detect whether a stack changed since the previous run.
+type ContentHash string
+
+// HashStackFolder computes a ContentHash over every file under stackFolderPath (path and
+// content), so a change anywhere else in the registry doesn't force a stack whose own files are
+// untouched to be reparsed and revalidated.
+func HashStackFolder(stackFolderPath string) (ContentHash, error) {
+	h := sha256.New()
+	err := filepath.Walk(stackFolderPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(stackFolderPath, p)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %v", stackFolderPath, err)
+	}
+	return ContentHash(hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// IncrementalCache maps a stack name to the ContentHash it had the last time index generation
+// processed it, so a later run can tell which stacks are unchanged and skip reparsing them.
+type IncrementalCache map[string]ContentHash
+
+// LoadIncrementalCache reads a cache previously written by IncrementalCache.Save, or returns an
+// empty cache if cacheFilePath doesn't exist yet (e.g. the first incremental run).
+func LoadIncrementalCache(cacheFilePath string) (IncrementalCache, error) {
+	cache := IncrementalCache{}
+	data, err := ioutil.ReadFile(cacheFilePath)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incremental cache %s: %v", cacheFilePath, err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse incremental cache %s: %v", cacheFilePath, err)
+	}
+	return cache, nil
+}
+
+// Save writes c as JSON to cacheFilePath.
+func (c IncrementalCache) Save(cacheFilePath string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal incremental cache: %v", err)
+	}
+	if err := ioutil.WriteFile(cacheFilePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", cacheFilePath, err)
+	}
+	return nil
+}
+
+// IncrementalOptions configures incremental generation. See ValidationOptions.Incremental.
+type IncrementalOptions struct {
+	// Cache maps a stack name to its ContentHash as of the previous run. It's updated in place
+	// with every stack's current hash as parseDevfileRegistry processes it.
+	Cache IncrementalCache
+	// PreviousIndex is the index a prior run produced. A stack whose current ContentHash matches
+	// Cache's entry reuses its entry from here instead of being reparsed and revalidated.
+	PreviousIndex []schema.Schema
+}
+
+// previousStack returns the PreviousIndex entry named name, or nil if there isn't one.
+func (o *IncrementalOptions) previousStack(name string) *schema.Schema {
+	for i := range o.PreviousIndex {
+		if o.PreviousIndex[i].Name == name {
+			return &o.PreviousIndex[i]
+		}
+	}
+	return nil
+}
+
+// GenerateIndexStructIncremental behaves like GenerateIndexStructWithOptions, except a stack
+// whose folder is unchanged (by ContentHash) since cacheFilePath was last written reuses its
+// entry from previousIndexFilePath instead of being reparsed and revalidated. On success, the
+// updated cache, covering every stack hashed this run, is written back to cacheFilePath.
+func GenerateIndexStructIncremental(registryDirPath string, previousIndexFilePath string, cacheFilePath string, opts ValidationOptions) ([]schema.Schema, []StageTiming, error) {
+	cache, err := LoadIncrementalCache(cacheFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var previousIndex []schema.Schema
+	if data, err := ioutil.ReadFile(previousIndexFilePath); err == nil {
+		if err := json.Unmarshal(data, &previousIndex); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse previous index %s: %v", previousIndexFilePath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read previous index %s: %v", previousIndexFilePath, err)
+	}
+
+	opts.Incremental = &IncrementalOptions{Cache: cache, PreviousIndex: previousIndex}
+	index, timings, err := GenerateIndexStructWithOptions(registryDirPath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cache.Save(cacheFilePath); err != nil {
+		return nil, nil, err
+	}
+	return index, timings, nil
+}
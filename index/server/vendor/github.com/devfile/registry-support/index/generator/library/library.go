@@ -1,15 +1,23 @@
 package library
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	devfileParser "github.com/devfile/library/pkg/devfile"
 	"github.com/devfile/library/pkg/devfile/parser"
+	"github.com/devfile/library/pkg/devfile/parser/data/v2/common"
 	"github.com/devfile/registry-support/index/generator/schema"
 	"gopkg.in/yaml.v2"
 )
@@ -18,8 +26,72 @@ const (
 	devfile             = "devfile.yaml"
 	devfileHidden       = ".devfile.yaml"
 	extraDevfileEntries = "extraDevfileEntries.yaml"
+	stackYaml           = "stack.yaml"
+
+	// defaultEmbedDevfileMaxBytes is the size limit applied to ValidationOptions.EmbedDevfiles
+	// when ValidationOptions.EmbedDevfilesMaxBytes is not set.
+	defaultEmbedDevfileMaxBytes int64 = 32 * 1024
+)
+
+// ValidationCode is a stable identifier for a validation failure, independent of the human-
+// readable message text, so documentation can reference a code (e.g. "DFR0001") and downstream
+// tooling can map codes to remediation hints even as message wording changes.
+type ValidationCode string
+
+const (
+	CodeMissingProvider           ValidationCode = "DFR0001"
+	CodeMissingSupportUrl         ValidationCode = "DFR0002"
+	CodeMissingArch               ValidationCode = "DFR0003"
+	CodeInvalidArch               ValidationCode = "DFR0004"
+	CodeMetadataNameNotSet        ValidationCode = "DFR0005"
+	CodeMetadataDisplayNameNotSet ValidationCode = "DFR0006"
+	CodeMetadataLanguageNotSet    ValidationCode = "DFR0007"
+	CodeMetadataProjectTypeNotSet ValidationCode = "DFR0008"
+	CodeDuplicateDisplayName      ValidationCode = "DFR0009"
+	CodeDuplicateIcon             ValidationCode = "DFR0010"
+	CodeCaseInsensitiveCollision  ValidationCode = "DFR0011"
+	CodeWindowsInvalidPath        ValidationCode = "DFR0012"
+	CodeUnsupportedMediaType      ValidationCode = "DFR0013"
+	CodeInvalidToolVersion        ValidationCode = "DFR0014"
+	CodeMissingOuterloopResource  ValidationCode = "DFR0015"
 )
 
+// validationHints is the message catalog mapping each ValidationCode to a short remediation hint.
+// Consuming tooling (docs, linters, IDE diagnostics) can look up a hint by code instead of parsing
+// the free-form Error() string, so the hints keep working even if that wording changes.
+var validationHints = map[ValidationCode]string{
+	CodeMissingProvider:           "set the provider field so consumers know who maintains this devfile",
+	CodeMissingSupportUrl:         "set the supportUrl field so consumers know where to get help",
+	CodeMissingArch:               "list at least one supported architecture",
+	CodeInvalidArch:               "use a supported architecture value (amd64, arm64, ppc64le, s390x)",
+	CodeMetadataNameNotSet:        "set metadata.name in the devfile",
+	CodeMetadataDisplayNameNotSet: "set metadata.displayName in the devfile",
+	CodeMetadataLanguageNotSet:    "set metadata.language in the devfile",
+	CodeMetadataProjectTypeNotSet: "set metadata.projectType in the devfile",
+	CodeDuplicateDisplayName:      "give this devfile a displayName that isn't already used by another devfile in the registry",
+	CodeDuplicateIcon:             "give this devfile its own icon URL instead of reusing another devfile's",
+	CodeCaseInsensitiveCollision:  "rename one of the colliding resource files so they no longer differ only by case",
+	CodeWindowsInvalidPath:        "rename the resource file to avoid characters and reserved names that Windows can't represent",
+	CodeUnsupportedMediaType:      "rename or remove the resource file so every declared resource maps to a media type the index server's push step recognizes",
+	CodeInvalidToolVersion:        "set minimumToolVersions entries to plain dotted-numeric versions, e.g. \"3.2.0\"",
+	CodeMissingOuterloopResource:  "add the referenced Dockerfile/manifest to the stack, or fix the component's uri, so it's still there after a registry pull",
+}
+
+// ValidationHint returns the remediation hint registered for code, or "" if code is unknown.
+func ValidationHint(code ValidationCode) string {
+	return validationHints[code]
+}
+
+// Coder is implemented by validation errors that carry a stable ValidationCode.
+type Coder interface {
+	Code() ValidationCode
+}
+
+// Pather is implemented by validation errors that reference a specific resource file path.
+type Pather interface {
+	Path() string
+}
+
 // MissingArchError is an error if the architecture list is empty
 type MissingArchError struct {
 	devfile string
@@ -29,6 +101,10 @@ func (e *MissingArchError) Error() string {
 	return fmt.Sprintf("the %s devfile has no architecture(s) mentioned\n", e.devfile)
 }
 
+func (e *MissingArchError) Code() ValidationCode {
+	return CodeMissingArch
+}
+
 // MissingProviderError is an error if the provider field is missing
 type MissingProviderError struct {
 	devfile string
@@ -38,6 +114,10 @@ func (e *MissingProviderError) Error() string {
 	return fmt.Sprintf("the %s devfile has no provider mentioned\n", e.devfile)
 }
 
+func (e *MissingProviderError) Code() ValidationCode {
+	return CodeMissingProvider
+}
+
 // MissingSupportUrlError is an error if the supportUrl field is missing
 type MissingSupportUrlError struct {
 	devfile string
@@ -47,25 +127,503 @@ func (e *MissingSupportUrlError) Error() string {
 	return fmt.Sprintf("the %s devfile has no supportUrl mentioned\n", e.devfile)
 }
 
-// GenerateIndexStruct parses registry then generates index struct according to the schema
+func (e *MissingSupportUrlError) Code() ValidationCode {
+	return CodeMissingSupportUrl
+}
+
+// InvalidArchError is an error if the architecture list contains an unrecognized architecture
+type InvalidArchError struct {
+	devfile string
+	err     error
+}
+
+func (e *InvalidArchError) Error() string {
+	return fmt.Sprintf("the %s devfile has an invalid architecture: %v\n", e.devfile, e.err)
+}
+
+func (e *InvalidArchError) Code() ValidationCode {
+	return CodeInvalidArch
+}
+
+// MetadataValidationError reports a required devfile.yaml metadata field that was not set.
+type MetadataValidationError struct {
+	field string
+	code  ValidationCode
+}
+
+func (e *MetadataValidationError) Error() string {
+	return fmt.Sprintf("metadata.%s is not set", e.field)
+}
+
+func (e *MetadataValidationError) Code() ValidationCode {
+	return e.code
+}
+
+// DuplicateDisplayNameError reports that two or more index entries share the same displayName,
+// which would otherwise render as indistinguishable entries in the viewer and IDE pickers.
+type DuplicateDisplayNameError struct {
+	displayName string
+	devfiles    []string
+}
+
+func (e *DuplicateDisplayNameError) Error() string {
+	return fmt.Sprintf("displayName %q is used by multiple devfiles: %v", e.displayName, e.devfiles)
+}
+
+func (e *DuplicateDisplayNameError) Code() ValidationCode {
+	return CodeDuplicateDisplayName
+}
+
+// DuplicateIconError reports that two or more index entries share the same icon URL.
+type DuplicateIconError struct {
+	icon     string
+	devfiles []string
+}
+
+func (e *DuplicateIconError) Error() string {
+	return fmt.Sprintf("icon %q is used by multiple devfiles: %v", e.icon, e.devfiles)
+}
+
+func (e *DuplicateIconError) Code() ValidationCode {
+	return CodeDuplicateIcon
+}
+
+// CaseInsensitiveCollisionError reports resource filenames within one devfile that differ only
+// by case. They collide when extracted on a case-insensitive filesystem (macOS default, Windows).
+type CaseInsensitiveCollisionError struct {
+	devfile string
+	paths   []string
+}
+
+func (e *CaseInsensitiveCollisionError) Error() string {
+	return fmt.Sprintf("the %s devfile has resource filenames that collide case-insensitively: %v", e.devfile, e.paths)
+}
+
+func (e *CaseInsensitiveCollisionError) Code() ValidationCode {
+	return CodeCaseInsensitiveCollision
+}
+
+// WindowsInvalidPathError reports a resource filename containing a character or reserved device
+// name that Windows can't represent, so a packaged archive that extracts fine on Linux or macOS
+// would fail to extract for a Windows user of odo.
+type WindowsInvalidPathError struct {
+	devfile string
+	path    string
+}
+
+func (e *WindowsInvalidPathError) Error() string {
+	return fmt.Sprintf("the %s devfile has a resource filename that is invalid on Windows: %s", e.devfile, e.path)
+}
+
+func (e *WindowsInvalidPathError) Code() ValidationCode {
+	return CodeWindowsInvalidPath
+}
+
+func (e *WindowsInvalidPathError) Path() string {
+	return e.path
+}
+
+// UnsupportedMediaTypeError reports a resource filename that the index server's push step (see
+// pushStackToRegistry) has no OCI media type mapping for, so it would be silently dropped instead
+// of pushed, even though the index promises it as one of the version's Resources.
+type UnsupportedMediaTypeError struct {
+	devfile string
+	path    string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("the %s devfile has a resource file with no recognized OCI media type: %s", e.devfile, e.path)
+}
+
+func (e *UnsupportedMediaTypeError) Code() ValidationCode {
+	return CodeUnsupportedMediaType
+}
+
+func (e *UnsupportedMediaTypeError) Path() string {
+	return e.path
+}
+
+// InvalidToolVersionError reports a version's minimumToolVersions entry that isn't a plain
+// dotted-numeric version string, so a malformed value doesn't silently fail to compare later when
+// a client checks it against the tool it's running.
+type InvalidToolVersionError struct {
+	devfile string
+	tool    string
+	version string
+}
+
+func (e *InvalidToolVersionError) Error() string {
+	return fmt.Sprintf("the %s devfile has an invalid minimumToolVersions.%s value: %q", e.devfile, e.tool, e.version)
+}
+
+func (e *InvalidToolVersionError) Code() ValidationCode {
+	return CodeInvalidToolVersion
+}
+
+// MissingOuterloopResourceError reports an Image or Kubernetes/Openshift component whose uri
+// points at a file that isn't among the stack version's resources, so a client that pulls the
+// version and runs an outerloop command (e.g. odo deploy) would fail on a missing Dockerfile or
+// manifest instead of failing here, at registry build time.
+type MissingOuterloopResourceError struct {
+	devfile   string
+	component string
+	path      string
+}
+
+func (e *MissingOuterloopResourceError) Error() string {
+	return fmt.Sprintf("the %s devfile's %q component references outerloop resource %q, which is not one of its packaged files", e.devfile, e.component, e.path)
+}
+
+func (e *MissingOuterloopResourceError) Code() ValidationCode {
+	return CodeMissingOuterloopResource
+}
+
+func (e *MissingOuterloopResourceError) Path() string {
+	return e.path
+}
+
+// GenerateIndexStruct parses registry then generates index struct according to the schema.
+// force disables every validation stage; use GenerateIndexStructWithOptions to skip stages
+// individually or to get per-stage timing.
 func GenerateIndexStruct(registryDirPath string, force bool) ([]schema.Schema, error) {
+	return GenerateIndexStructWithContext(context.Background(), registryDirPath, force)
+}
+
+// GenerateIndexStructWithContext is GenerateIndexStruct, but aborts as soon as ctx is done
+// instead of running the whole registry to completion, so a caller embedding this library can
+// bound how long a generate call is allowed to run.
+func GenerateIndexStructWithContext(ctx context.Context, registryDirPath string, force bool) ([]schema.Schema, error) {
+	index, _, err := GenerateIndexStructWithOptionsContext(ctx, registryDirPath, ValidationOptions{
+		SkipSchemaValidate:   force,
+		SkipMetadataValidate: force,
+		SkipDeepValidate:     force,
+	})
+	return index, err
+}
+
+// GenerateIndexStructWithOptions parses the registry into an index struct the same way
+// GenerateIndexStruct does, but runs the parse -> schema validate -> metadata validate ->
+// deep validate pipeline with the stages individually skippable, and returns how long each
+// stage took across the whole registry. This is useful for diagnosing slow local builds and
+// for running a faster partial validation while iterating.
+func GenerateIndexStructWithOptions(registryDirPath string, opts ValidationOptions) ([]schema.Schema, []StageTiming, error) {
+	return GenerateIndexStructWithOptionsContext(context.Background(), registryDirPath, opts)
+}
+
+// GenerateIndexStructWithOptionsContext is GenerateIndexStructWithOptions, but checks ctx between
+// stacks so a caller can time-box or cancel a run that's parsing a large registry.
+func GenerateIndexStructWithOptionsContext(ctx context.Context, registryDirPath string, opts ValidationOptions) ([]schema.Schema, []StageTiming, error) {
+	if opts.SkipSamples && opts.SamplesOnly {
+		return nil, nil, fmt.Errorf("skipSamples and samplesOnly are mutually exclusive")
+	}
+
+	pipeline := newStagePipeline(opts)
+
 	// Parse devfile registry then populate index struct
-	index, err := parseDevfileRegistry(registryDirPath, force)
+	index, err := parseDevfileRegistry(ctx, registryDirPath, pipeline)
 	if err != nil {
-		return index, err
+		return index, pipeline.report(), err
 	}
 
 	// Parse extraDevfileEntries.yaml then populate the index struct (optional)
 	extraDevfileEntriesPath := path.Join(registryDirPath, extraDevfileEntries)
 	if fileExists(extraDevfileEntriesPath) {
-		indexFromExtraDevfileEntries, err := parseExtraDevfileEntries(registryDirPath, force)
+		indexFromExtraDevfileEntries, err := parseExtraDevfileEntries(registryDirPath, pipeline)
 		if err != nil {
-			return index, err
+			return index, pipeline.report(), err
 		}
 		index = append(index, indexFromExtraDevfileEntries...)
 	}
 
-	return index, nil
+	if !opts.SkipDeepValidate {
+		var duplicateErrors []error
+		pipeline.time(StageDeepValidate, func() {
+			duplicateErrors = checkForDuplicateDisplayNamesAndIcons(index)
+		})
+		if len(duplicateErrors) > 0 {
+			return index, pipeline.report(), fmt.Errorf("registry has duplicate displayName/icon values: %v", duplicateErrors)
+		}
+	}
+
+	index = filterByDevfileType(index, opts)
+
+	return index, pipeline.report(), nil
+}
+
+// checkForDuplicateDisplayNamesAndIcons reports every displayName or icon URL that is reused
+// by more than one index entry, so a copy-pasted stack.yaml or devfile.yaml doesn't silently
+// produce indistinguishable entries in the viewer and IDE pickers.
+func checkForDuplicateDisplayNamesAndIcons(index []schema.Schema) []error {
+	displayNameToDevfiles := map[string][]string{}
+	iconToDevfiles := map[string][]string{}
+	for _, indexComponent := range index {
+		if indexComponent.DisplayName != "" {
+			displayNameToDevfiles[indexComponent.DisplayName] = append(displayNameToDevfiles[indexComponent.DisplayName], indexComponent.Name)
+		}
+		if indexComponent.Icon != "" {
+			iconToDevfiles[indexComponent.Icon] = append(iconToDevfiles[indexComponent.Icon], indexComponent.Name)
+		}
+	}
+
+	var errs []error
+	for _, displayName := range sortedKeys(displayNameToDevfiles) {
+		if devfiles := displayNameToDevfiles[displayName]; len(devfiles) > 1 {
+			errs = append(errs, &DuplicateDisplayNameError{displayName: displayName, devfiles: devfiles})
+		}
+	}
+	for _, icon := range sortedKeys(iconToDevfiles) {
+		if devfiles := iconToDevfiles[icon]; len(devfiles) > 1 {
+			errs = append(errs, &DuplicateIconError{icon: icon, devfiles: devfiles})
+		}
+	}
+	return errs
+}
+
+// setVersionSummaryFields populates indexComponent.DefaultVersion and indexComponent.LatestVersion
+// from indexComponent.Versions, so a client reading the generated index.json can find the version
+// it should pull without walking the Versions array itself. LatestVersion here is the numerically
+// highest version; registry-library's Stack.LatestVersion instead returns the Default-flagged
+// version, i.e. the one recorded here as DefaultVersion.
+func setVersionSummaryFields(indexComponent *schema.Schema) {
+	for _, version := range indexComponent.Versions {
+		if version.Default {
+			indexComponent.DefaultVersion = version.Version
+			break
+		}
+	}
+
+	var latest string
+	for _, version := range indexComponent.Versions {
+		if latest == "" || isVersionNewer(version.Version, latest) {
+			latest = version.Version
+		}
+	}
+	indexComponent.LatestVersion = latest
+}
+
+// isVersionNewer reports whether version is a later stack version than other, comparing
+// dot-separated numeric components (e.g. "1.2.0" is newer than "1.1.0"). Falls back to a plain
+// string comparison for either value that isn't a plain major[.minor[.patch]] number, so a
+// non-numeric version scheme degrades to a stable, if arbitrary, ordering instead of an error.
+func isVersionNewer(version, other string) bool {
+	v, vErr := parseNumericVersion(version)
+	o, oErr := parseNumericVersion(other)
+	if vErr != nil || oErr != nil {
+		return version > other
+	}
+	for i := 0; i < 3; i++ {
+		if v[i] != o[i] {
+			return v[i] > o[i]
+		}
+	}
+	return false
+}
+
+// parseNumericVersion parses a "major[.minor[.patch]]" version into its three numeric
+// components, padding missing trailing components with 0 (e.g. "1.2" becomes [1, 2, 0]).
+func parseNumericVersion(version string) ([3]int, error) {
+	var out [3]int
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, fmt.Errorf("invalid version %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return out, fmt.Errorf("invalid version %q: %v", version, err)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// sortedKeys returns the keys of m in ascending order, so map-derived error output is
+// deterministic across runs.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// windowsReservedNames are device names Windows reserves regardless of extension, case-insensitive.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsInvalidPathChars are characters Windows never allows in a filename.
+const windowsInvalidPathChars = `<>:"/\|?*`
+
+// isWindowsInvalidPath reports whether name would be rejected by Windows: an invalid character,
+// a control character, a reserved device name, or a trailing dot or space.
+func isWindowsInvalidPath(name string) bool {
+	if strings.ContainsAny(name, windowsInvalidPathChars) {
+		return true
+	}
+	for _, r := range name {
+		if r < 0x20 {
+			return true
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return true
+	}
+	base := strings.ToUpper(strings.TrimSuffix(name, filepath.Ext(name)))
+	return windowsReservedNames[base]
+}
+
+// supportedResourceNames are exact resource filenames the index server's push step
+// (pushStackToRegistry) has an OCI media type for. meta.yaml is deliberately excluded here even
+// though the push step also skips it: that skip is intentional cleanup of a legacy file, not a
+// media type gap, so it shouldn't be flagged as a resource that would be dropped by mistake.
+var supportedResourceNames = map[string]bool{
+	"devfile.yaml":  true,
+	".devfile.yaml": true,
+	"archive.tar":   true,
+	"logo.svg":      true,
+	"logo.png":      true,
+}
+
+// supportedResourceExtensions are file extensions (as returned by filepath.Ext, including the
+// leading dot) the index server's push step recognizes regardless of the rest of the filename.
+var supportedResourceExtensions = map[string]bool{
+	".vsx": true,
+}
+
+// checkForUnsupportedMediaTypes reports every resource filename in resources, other than
+// meta.yaml, that the index server's push step has no OCI media type mapping for. Those files
+// would be silently dropped at push time, leaving the index promising a resource that never
+// reaches the OCI registry.
+func checkForUnsupportedMediaTypes(devfileName string, resources []string) []error {
+	var errs []error
+	for _, resource := range resources {
+		if resource == "meta.yaml" {
+			continue
+		}
+		if supportedResourceNames[resource] {
+			continue
+		}
+		if supportedResourceExtensions[filepath.Ext(resource)] {
+			continue
+		}
+		errs = append(errs, &UnsupportedMediaTypeError{devfile: devfileName, path: resource})
+	}
+	return errs
+}
+
+// toolVersionPattern matches a plain dotted-numeric version string (e.g. "3.2.0" or "3.2"),
+// the only form minimumToolVersions entries are expected to take.
+var toolVersionPattern = regexp.MustCompile(`^\d+(\.\d+){1,2}$`)
+
+// checkForInvalidToolVersions reports every entry in versions that isn't a plain dotted-numeric
+// version string, so a typo doesn't silently fail to compare later when a client checks it
+// against the tool it's running. A nil versions (the common case: no minimum declared) reports
+// nothing.
+func checkForInvalidToolVersions(devfileName string, versions *schema.ToolVersions) []error {
+	if versions == nil {
+		return nil
+	}
+	var errs []error
+	for _, entry := range []struct{ tool, version string }{
+		{"odo", versions.Odo},
+		{"che", versions.Che},
+		{"devfileCLI", versions.DevfileCLI},
+	} {
+		if entry.version == "" {
+			continue
+		}
+		if !toolVersionPattern.MatchString(entry.version) {
+			errs = append(errs, &InvalidToolVersionError{devfile: devfileName, tool: entry.tool, version: entry.version})
+		}
+	}
+	return errs
+}
+
+// checkForCrossPlatformPathIssues reports every resource filename in resources that collides
+// case-insensitively with another, or that is invalid on Windows, so a packaged archive that
+// extracts fine on Linux or macOS doesn't silently break for a Windows user of odo.
+func checkForCrossPlatformPathIssues(devfileName string, resources []string) []error {
+	pathsByLowercase := map[string][]string{}
+	var errs []error
+	for _, resource := range resources {
+		key := strings.ToLower(resource)
+		pathsByLowercase[key] = append(pathsByLowercase[key], resource)
+		if isWindowsInvalidPath(resource) {
+			errs = append(errs, &WindowsInvalidPathError{devfile: devfileName, path: resource})
+		}
+	}
+	for _, key := range sortedKeys(pathsByLowercase) {
+		if paths := pathsByLowercase[key]; len(paths) > 1 {
+			errs = append(errs, &CaseInsensitiveCollisionError{devfile: devfileName, paths: paths})
+		}
+	}
+	return errs
+}
+
+// checkForMissingOuterloopResources reports every Image or Kubernetes/Openshift component in
+// devfileObj whose uri is a relative path that doesn't resolve to one of resources, so a stack
+// that references, say, a Dockerfile or Kubernetes manifest that never made it into the packaged
+// archive fails at registry build time instead of at odo deploy time, after a client has already
+// pulled the stack. A uri with a scheme (e.g. "https://...") is left alone, since it's fetched
+// directly by the client rather than expected to ship with the stack.
+func checkForMissingOuterloopResources(devfileName string, devfileObj parser.DevfileObj, resources []string) []error {
+	components, err := devfileObj.Data.GetComponents(common.DevfileOptions{})
+	if err != nil {
+		return nil
+	}
+
+	var errs []error
+	for _, component := range components {
+		var uri string
+		switch {
+		case component.Image != nil && component.Image.Dockerfile != nil:
+			uri = component.Image.Dockerfile.Uri
+		case component.Kubernetes != nil:
+			uri = component.Kubernetes.Uri
+		case component.Openshift != nil:
+			uri = component.Openshift.Uri
+		default:
+			continue
+		}
+		if uri == "" || isRemoteUri(uri) {
+			continue
+		}
+		if !inArray(resources, uri) {
+			errs = append(errs, &MissingOuterloopResourceError{devfile: devfileName, component: component.Name, path: uri})
+		}
+	}
+	return errs
+}
+
+// isRemoteUri reports whether uri has a scheme (e.g. "https://...", "git://..."), meaning it's
+// fetched directly by the client rather than expected to ship with the stack.
+func isRemoteUri(uri string) bool {
+	parsed, err := url.Parse(uri)
+	return err == nil && parsed.Scheme != ""
+}
+
+// filterByDevfileType applies SkipSamples/SamplesOnly to the fully-parsed index. Filtering
+// happens after parsing (rather than skipping the stacks directory or extraDevfileEntries.yaml
+// outright) because extraDevfileEntries.yaml can declare both stack and sample entries.
+func filterByDevfileType(index []schema.Schema, opts ValidationOptions) []schema.Schema {
+	if !opts.SkipSamples && !opts.SamplesOnly {
+		return index
+	}
+	var filtered []schema.Schema
+	for _, indexComponent := range index {
+		if opts.SkipSamples && indexComponent.Type == schema.SampleDevfileType {
+			continue
+		}
+		if opts.SamplesOnly && indexComponent.Type != schema.SampleDevfileType {
+			continue
+		}
+		filtered = append(filtered, indexComponent)
+	}
+	return filtered
 }
 
 // CreateIndexFile creates index file in disk
@@ -83,23 +641,90 @@ func CreateIndexFile(index []schema.Schema, indexFilePath string) error {
 	return nil
 }
 
+// CreateIndexFileYAML writes index to indexFilePath as YAML with the same schema CreateIndexFile
+// writes as JSON, for downstream GitOps tooling that consumes YAML catalogs directly.
+func CreateIndexFileYAML(index []schema.Schema, indexFilePath string) error {
+	bytes, err := yaml.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s data: %v", indexFilePath, err)
+	}
+
+	err = ioutil.WriteFile(indexFilePath, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", indexFilePath, err)
+	}
+
+	return nil
+}
+
 func validateIndexComponent(indexComponent schema.Schema, componentType schema.DevfileType) error {
 	if componentType == schema.StackDevfileType {
 		if indexComponent.Name == "" {
 			return fmt.Errorf("index component name is not initialized")
 		}
-		if indexComponent.Links == nil {
-			return fmt.Errorf("index component links are empty")
-		}
-		if indexComponent.Resources == nil {
-			return fmt.Errorf("index component resources are empty")
+		if indexComponent.Versions == nil || len(indexComponent.Versions) == 0 {
+			return fmt.Errorf("index component versions list is empty")
+		} else {
+			defaultFound := false
+			for _, version := range indexComponent.Versions {
+				if version.Version == "" {
+					return fmt.Errorf("index component versions list contains an entry with no version specified")
+				}
+				if version.SchemaVersion == "" {
+					return fmt.Errorf("index component version %s: schema version is empty", version.Version)
+				}
+				if version.Links == nil || len(version.Links) == 0 {
+					return fmt.Errorf("index component version %s: links are empty", version.Version)
+				}
+				if version.Resources == nil || len(version.Resources) == 0 {
+					return fmt.Errorf("index component version %s: resources are empty", version.Version)
+				}
+				if version.Default {
+					if !defaultFound {
+						defaultFound = true
+					} else {
+						return fmt.Errorf("index component has multiple default versions")
+					}
+				}
+			}
+			if !defaultFound {
+				return fmt.Errorf("index component has no default version defined")
+			}
 		}
 	} else if componentType == schema.SampleDevfileType {
-		if indexComponent.Git == nil {
-			return fmt.Errorf("index component git is empty")
-		}
-		if len(indexComponent.Git.Remotes) > 1 {
-			return fmt.Errorf("index component has multiple remotes")
+		if indexComponent.Versions != nil && len(indexComponent.Versions) > 0 {
+			defaultFound := false
+			for _, version := range indexComponent.Versions {
+				if version.Version == "" {
+					return fmt.Errorf("index component versions list contains an entry with no version specified")
+				}
+				if version.SchemaVersion == "" {
+					return fmt.Errorf("index component version %s: schema version is empty", version.Version)
+				}
+				if version.Git == nil {
+					return fmt.Errorf("index component version %s: git is empty", version.Version)
+				}
+				if len(version.Git.Remotes) > 1 {
+					return fmt.Errorf("index component version %s: has multiple remotes", version.Version)
+				}
+				if version.Default {
+					if !defaultFound {
+						defaultFound = true
+					} else {
+						return fmt.Errorf("index component has multiple default versions")
+					}
+				}
+			}
+			if !defaultFound {
+				return fmt.Errorf("index component has no default version defined")
+			}
+		} else {
+			if indexComponent.Git == nil {
+				return fmt.Errorf("index component git is empty")
+			}
+			if len(indexComponent.Git.Remotes) > 1 {
+				return fmt.Errorf("index component has multiple remotes")
+			}
 		}
 	}
 
@@ -113,10 +738,33 @@ func validateIndexComponent(indexComponent schema.Schema, componentType schema.D
 	if len(indexComponent.Architectures) == 0 {
 		return &MissingArchError{devfile: indexComponent.Name}
 	}
+	if err := schema.ValidateArchitectures(indexComponent.Architectures); err != nil {
+		return &InvalidArchError{devfile: indexComponent.Name, err: err}
+	}
+
+	if err := runRegisteredValidators(indexComponent, componentType); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// utf8BOM is the byte-order mark some Windows editors prepend to UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizeYAMLInput strips a leading UTF-8 BOM and converts CRLF line endings to LF, so a
+// devfile.yaml, stack.yaml, or extraDevfileEntries.yaml authored on Windows parses the same way
+// as one authored on Linux or macOS instead of failing with a confusing YAML syntax error. A
+// warning is printed when either was found, so contributors know why their line endings changed.
+func normalizeYAMLInput(raw []byte, path string) []byte {
+	normalized := bytes.TrimPrefix(raw, utf8BOM)
+	normalized = bytes.ReplaceAll(normalized, []byte("\r\n"), []byte("\n"))
+	if !bytes.Equal(normalized, raw) {
+		fmt.Printf("Warning: %s contains a UTF-8 byte-order mark or Windows-style (CRLF) line endings; normalizing before parsing\n", path)
+	}
+	return normalized
+}
+
 func fileExists(filepath string) bool {
 	if _, err := os.Stat(filepath); os.IsNotExist(err) {
 		return false
@@ -125,103 +773,355 @@ func fileExists(filepath string) bool {
 	return true
 }
 
-func parseDevfileRegistry(registryDirPath string, force bool) ([]schema.Schema, error) {
+func dirExists(dirpath string) error {
+	dir, err := os.Stat(dirpath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("path: %s does not exist: %w", dirpath, err)
+	}
+	if !dir.IsDir() {
+		return fmt.Errorf("%s is not a directory", dirpath)
+	}
+	return nil
+}
+
+func parseDevfileRegistry(ctx context.Context, registryDirPath string, pipeline *stagePipeline) ([]schema.Schema, error) {
+
 	var index []schema.Schema
 	stackDirPath := path.Join(registryDirPath, "stacks")
 	stackDir, err := ioutil.ReadDir(stackDirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read stack directory %s: %v", stackDirPath, err)
 	}
-	for _, devfileDir := range stackDir {
-		if !devfileDir.IsDir() {
+	for _, stackFolderDir := range stackDir {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("parsing devfile registry %s: %w", registryDirPath, err)
+		}
+		if !stackFolderDir.IsDir() {
 			continue
 		}
+		stackFolderPath := filepath.Join(stackDirPath, stackFolderDir.Name())
 
-		// Allow devfile.yaml or .devfile.yaml
-		devfilePath := filepath.Join(stackDirPath, devfileDir.Name(), devfile)
-		devfileHiddenPath := filepath.Join(stackDirPath, devfileDir.Name(), devfileHidden)
-		if fileExists(devfilePath) && fileExists(devfileHiddenPath) {
-			return nil, fmt.Errorf("both %s and %s exist", devfilePath, devfileHiddenPath)
-		}
-		if fileExists(devfileHiddenPath) {
-			devfilePath = devfileHiddenPath
+		if pipeline.opts.Incremental != nil {
+			hash, err := HashStackFolder(stackFolderPath)
+			if err != nil {
+				return nil, err
+			}
+			if cached, ok := pipeline.opts.Incremental.Cache[stackFolderDir.Name()]; ok && cached == hash {
+				if previous := pipeline.opts.Incremental.previousStack(stackFolderDir.Name()); previous != nil {
+					index = append(index, *previous)
+					continue
+				}
+			}
+			pipeline.opts.Incremental.Cache[stackFolderDir.Name()] = hash
 		}
 
-		if !force {
-			// Devfile validation
-			devfileObj, err := devfileParser.ParseAndValidate(devfilePath)
+		stackYamlPath := filepath.Join(stackFolderPath, stackYaml)
+		// if stack.yaml exist,  parse stack.yaml
+		var indexComponent schema.Schema
+		if fileExists(stackYamlPath) {
+			pipeline.time(StageParse, func() {
+				indexComponent, err = parseStackInfo(stackYamlPath)
+			})
 			if err != nil {
-				return nil, fmt.Errorf("%s devfile is not valid: %v", devfileDir.Name(), err)
+				return nil, err
 			}
-
-			metadataErrors := checkForRequiredMetadata(devfileObj)
-			if metadataErrors != nil {
-				return nil, fmt.Errorf("%s devfile is not valid: %v", devfileDir.Name(), metadataErrors)
+			if !pipeline.opts.SkipDeepValidate {
+				var stackYamlErrors []error
+				pipeline.time(StageDeepValidate, func() {
+					stackYamlErrors = validateStackInfo(indexComponent, stackFolderPath)
+				})
+				if stackYamlErrors != nil {
+					return nil, fmt.Errorf("%s stack.yaml is not valid: %v", stackFolderDir.Name(), stackYamlErrors)
+				}
 			}
-		}
 
-		bytes, err := ioutil.ReadFile(devfilePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read %s: %v", devfilePath, err)
-		}
-		var devfile schema.Devfile
-		err = yaml.Unmarshal(bytes, &devfile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal %s data: %v", devfilePath, err)
-		}
-		indexComponent := devfile.Meta
-		if indexComponent.Links == nil {
-			indexComponent.Links = make(map[string]string)
-		}
-		indexComponent.Links["self"] = fmt.Sprintf("%s/%s:%s", "devfile-catalog", indexComponent.Name, "latest")
-		indexComponent.Type = schema.StackDevfileType
+			var gitJobs []stackGitDownloadJob
+			for _, versionComponent := range indexComponent.Versions {
+				if versionComponent.Git == nil {
+					continue
+				}
+				gitJobs = append(gitJobs, stackGitDownloadJob{
+					label:   fmt.Sprintf("%s@%s", stackFolderDir.Name(), versionComponent.Version),
+					git:     versionComponent.Git,
+					destDir: filepath.Join(stackFolderPath, versionComponent.Version),
+				})
+			}
+			if len(gitJobs) > 0 {
+				pipeline.time(StageParse, func() {
+					err = downloadStackVersionsGit(ctx, gitJobs, pipeline.opts.StackGitDownload)
+				})
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", stackFolderDir.Name(), err)
+				}
+			}
 
-		for _, starterProject := range devfile.StarterProjects {
-			indexComponent.StarterProjects = append(indexComponent.StarterProjects, starterProject.Name)
-		}
+			i := 0
+			for i < len(indexComponent.Versions) {
+				versionComponent := indexComponent.Versions[i]
+				stackVersonDirPath := filepath.Join(stackFolderPath, versionComponent.Version)
 
-		// Get the files in the stack folder
-		stackFolder := filepath.Join(stackDirPath, devfileDir.Name())
-		stackFiles, err := ioutil.ReadDir(stackFolder)
-		if err != nil {
-			return index, err
-		}
-		for _, stackFile := range stackFiles {
-			// The registry build should have already packaged any folders and miscellaneous files into an archive.tar file
-			// But, add this check as a safeguard, as OCI doesn't support unarchived folders being pushed up.
-			if !stackFile.IsDir() {
-				indexComponent.Resources = append(indexComponent.Resources, stackFile.Name())
+				err := parseStackDevfile(stackVersonDirPath, stackFolderDir.Name(), pipeline, &versionComponent, &indexComponent)
+				if err != nil {
+					return nil, err
+				}
+				if err := runTesters(pipeline.opts.Testers, pipeline.opts.Report, pipeline.opts.StrictTestValidation, stackVersonDirPath, indexComponent, versionComponent); err != nil {
+					return nil, fmt.Errorf("%s version %s: stack test failed: %v", stackFolderDir.Name(), versionComponent.Version, err)
+				}
+				indexComponent.Versions[i] = versionComponent
+				i++
+			}
+		} else { // if stack.yaml not exist, old stack repo struct, directly lookfor & parse devfile.yaml
+			versionComponent := schema.Version{}
+			err := parseStackDevfile(stackFolderPath, stackFolderDir.Name(), pipeline, &versionComponent, &indexComponent)
+			if err != nil {
+				return nil, err
+			}
+			versionComponent.Default = true
+			if err := runTesters(pipeline.opts.Testers, pipeline.opts.Report, pipeline.opts.StrictTestValidation, stackFolderPath, indexComponent, versionComponent); err != nil {
+				return nil, fmt.Errorf("%s: stack test failed: %v", stackFolderDir.Name(), err)
 			}
+			indexComponent.Versions = append(indexComponent.Versions, versionComponent)
 		}
+		indexComponent.Type = schema.StackDevfileType
 
-		if !force {
+		if !pipeline.opts.SkipDeepValidate {
 			// Index component validation
-			err := validateIndexComponent(indexComponent, schema.StackDevfileType)
+			var err error
+			pipeline.time(StageDeepValidate, func() {
+				err = validateIndexComponent(indexComponent, schema.StackDevfileType)
+			})
 			switch err.(type) {
-			case *MissingProviderError, *MissingSupportUrlError, *MissingArchError:
+			case *MissingProviderError, *MissingSupportUrlError, *MissingArchError, *InvalidArchError:
 				// log to the console as FYI if the devfile has no architectures/provider/supportUrl
 				fmt.Printf("%s", err.Error())
+				pipeline.opts.Report.record(stackFolderDir.Name(), "", SeverityWarning, err)
 			default:
 				// only return error if we dont want to print
 				if err != nil {
-					return nil, fmt.Errorf("%s index component is not valid: %v", devfileDir.Name(), err)
+					return nil, fmt.Errorf("%s index component is not valid: %v", stackFolderDir.Name(), err)
 				}
 			}
 		}
 
+		setVersionSummaryFields(&indexComponent)
+
+		if err := runEnrichers(pipeline.opts.Enrichers, stackFolderPath, &indexComponent); err != nil {
+			return nil, fmt.Errorf("%s: enrichment failed: %v", stackFolderDir.Name(), err)
+		}
+
 		index = append(index, indexComponent)
 	}
 
 	return index, nil
 }
 
-func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Schema, error) {
+// resolveDevfilePath returns the devfile.yaml path to use within dir, preferring the hidden
+// .devfile.yaml variant when present. Errors if both exist, so a stack, sample, or cloned remote
+// repo with conflicting devfiles is never silently resolved to one or the other. Neither existing
+// is not an error here; callers that require the devfile to be present check for that themselves.
+func resolveDevfilePath(dir string) (string, error) {
+	devfilePath := filepath.Join(dir, devfile)
+	devfileHiddenPath := filepath.Join(dir, devfileHidden)
+	if fileExists(devfilePath) && fileExists(devfileHiddenPath) {
+		return "", fmt.Errorf("both %s and %s exist", devfilePath, devfileHiddenPath)
+	}
+	if fileExists(devfileHiddenPath) {
+		return devfileHiddenPath, nil
+	}
+	return devfilePath, nil
+}
+
+func parseStackDevfile(devfileDirPath string, stackName string, pipeline *stagePipeline, versionComponent *schema.Version, indexComponent *schema.Schema) error {
+	// Allow devfile.yaml or .devfile.yaml
+	devfilePath, err := resolveDevfilePath(devfileDirPath)
+	if err != nil {
+		return err
+	}
+
+	var devfileObj parser.DevfileObj
+	var devfileObjParsed bool
+	if !pipeline.opts.SkipSchemaValidate || !pipeline.opts.SkipMetadataValidate {
+		// The devfile library parses and schema-validates a devfile in the same call, so that
+		// work is timed as a unit under StageSchemaValidate even when only metadata validation
+		// was requested.
+		var err error
+		pipeline.time(StageSchemaValidate, func() {
+			devfileObj, _, err = devfileParser.ParseDevfileAndValidate(parser.ParserArgs{Path: devfilePath})
+		})
+		if err != nil {
+			if !pipeline.opts.SkipSchemaValidate {
+				return fmt.Errorf("%s devfile is not valid: %v", devfileDirPath, err)
+			}
+		} else {
+			devfileObjParsed = true
+			if !pipeline.opts.SkipMetadataValidate {
+				var metadataErrors []error
+				pipeline.time(StageMetadataValidate, func() {
+					metadataErrors = checkForRequiredMetadata(devfileObj)
+				})
+				if metadataErrors != nil {
+					return fmt.Errorf("%s devfile is not valid: %v", devfileDirPath, metadataErrors)
+				}
+			}
+		}
+	}
+
+	bytes, err := ioutil.ReadFile(devfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", devfilePath, err)
+	}
+	bytes = normalizeYAMLInput(bytes, devfilePath)
+
+	var devfile schema.Devfile
+	err = yaml.Unmarshal(bytes, &devfile)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal %s data: %v", devfilePath, err)
+	}
+	metaBytes, err := yaml.Marshal(devfile.Meta)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal %s data: %v", devfilePath, err)
+	}
+	var versionProp schema.Version
+	err = yaml.Unmarshal(metaBytes, &versionProp)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal %s data: %v", devfilePath, err)
+	}
+
+	// set common properties if not set
+	if indexComponent.ProjectType == "" {
+		indexComponent.ProjectType = devfile.Meta.ProjectType
+	}
+	if indexComponent.Language == "" {
+		indexComponent.Language = devfile.Meta.Language
+	}
+	if indexComponent.Provider == "" {
+		indexComponent.Provider = devfile.Meta.Provider
+	}
+	if indexComponent.SupportUrl == "" {
+		indexComponent.SupportUrl = devfile.Meta.SupportUrl
+	}
+
+	// for single version stack with only devfile.yaml, without stack.yaml
+	// set the top-level properties for this stack
+	if indexComponent.Name == "" {
+		indexComponent.Name = devfile.Meta.Name
+	}
+	if indexComponent.DisplayName == "" {
+		indexComponent.DisplayName = devfile.Meta.DisplayName
+	}
+	if indexComponent.Description == "" {
+		indexComponent.Description = devfile.Meta.Description
+	}
+	if indexComponent.Icon == "" {
+		indexComponent.Icon = devfile.Meta.Icon
+	}
+
+	versionProp.Default = versionComponent.Default
+	*versionComponent = versionProp
+	if versionComponent.Links == nil {
+		versionComponent.Links = make(map[string]string)
+	}
+	versionComponent.Links["self"] = fmt.Sprintf("%s/%s:%s", "devfile-catalog", stackName, versionComponent.Version)
+	versionComponent.SchemaVersion = devfile.SchemaVersion
+
+	if pipeline.opts.EmbedDevfiles {
+		maxBytes := pipeline.opts.EmbedDevfilesMaxBytes
+		if maxBytes <= 0 {
+			maxBytes = defaultEmbedDevfileMaxBytes
+		}
+		if int64(len(bytes)) <= maxBytes {
+			versionComponent.InlineDevfile = string(bytes)
+		} else {
+			versionComponent.InlineDevfileTruncated = true
+		}
+	}
+
+	for _, starterProject := range devfile.StarterProjects {
+		versionComponent.StarterProjects = append(versionComponent.StarterProjects, starterProject.Name)
+	}
+
+	for _, tag := range versionComponent.Tags {
+		if !inArray(indexComponent.Tags, tag) {
+			indexComponent.Tags = append(indexComponent.Tags, tag)
+		}
+	}
+
+	for _, arch := range versionComponent.Architectures {
+		if !inArray(indexComponent.Architectures, arch) {
+			indexComponent.Architectures = append(indexComponent.Architectures, arch)
+		}
+	}
+
+	// Get the files in the stack folder
+	stackFiles, err := ioutil.ReadDir(devfileDirPath)
+	if err != nil {
+		return err
+	}
+	for _, stackFile := range stackFiles {
+		// The registry build should have already packaged any folders and miscellaneous files into an archive.tar file
+		// But, add this check as a safeguard, as OCI doesn't support unarchived folders being pushed up.
+		if !stackFile.IsDir() {
+			versionComponent.Resources = append(versionComponent.Resources, stackFile.Name())
+		}
+	}
+
+	if !pipeline.opts.SkipDeepValidate {
+		var pathErrors []error
+		pipeline.time(StageDeepValidate, func() {
+			pathErrors = checkForCrossPlatformPathIssues(stackName, versionComponent.Resources)
+		})
+		if len(pathErrors) > 0 {
+			if pipeline.opts.StrictPathValidation {
+				return fmt.Errorf("%s has cross-platform resource path issues: %v", stackName, pathErrors)
+			}
+			for _, pathErr := range pathErrors {
+				fmt.Printf("%s\n", pathErr.Error())
+				pipeline.opts.Report.record(stackName, versionComponent.Version, SeverityWarning, pathErr)
+			}
+		}
+
+		var mediaTypeErrors []error
+		pipeline.time(StageDeepValidate, func() {
+			mediaTypeErrors = checkForUnsupportedMediaTypes(stackName, versionComponent.Resources)
+		})
+		if len(mediaTypeErrors) > 0 {
+			if pipeline.opts.StrictMediaTypeValidation {
+				return fmt.Errorf("%s has resources with unsupported media types: %v", stackName, mediaTypeErrors)
+			}
+			for _, mediaTypeErr := range mediaTypeErrors {
+				fmt.Printf("%s\n", mediaTypeErr.Error())
+				pipeline.opts.Report.record(stackName, versionComponent.Version, SeverityWarning, mediaTypeErr)
+			}
+		}
+
+		if devfileObjParsed {
+			var outerloopErrors []error
+			pipeline.time(StageDeepValidate, func() {
+				outerloopErrors = checkForMissingOuterloopResources(stackName, devfileObj, versionComponent.Resources)
+			})
+			if len(outerloopErrors) > 0 {
+				if pipeline.opts.StrictOuterloopValidation {
+					return fmt.Errorf("%s has components referencing missing outerloop resources: %v", stackName, outerloopErrors)
+				}
+				for _, outerloopErr := range outerloopErrors {
+					fmt.Printf("%s\n", outerloopErr.Error())
+					pipeline.opts.Report.record(stackName, versionComponent.Version, SeverityWarning, outerloopErr)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseExtraDevfileEntries(registryDirPath string, pipeline *stagePipeline) ([]schema.Schema, error) {
 	var index []schema.Schema
 	extraDevfileEntriesPath := path.Join(registryDirPath, extraDevfileEntries)
 	bytes, err := ioutil.ReadFile(extraDevfileEntriesPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %v", extraDevfileEntriesPath, err)
 	}
+	bytes = normalizeYAMLInput(bytes, extraDevfileEntriesPath)
 
 	// Only validate samples if they have been cached
 	samplesDir := filepath.Join(registryDirPath, "samples")
@@ -231,7 +1131,9 @@ func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Sche
 	}
 
 	var devfileEntries schema.ExtraDevfileEntries
-	err = yaml.Unmarshal(bytes, &devfileEntries)
+	pipeline.time(StageParse, func() {
+		err = yaml.Unmarshal(bytes, &devfileEntries)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal %s data: %v", extraDevfileEntriesPath, err)
 	}
@@ -246,31 +1148,51 @@ func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Sche
 		for _, devfileEntry := range devfileEntriesWithType {
 			indexComponent := devfileEntry
 			indexComponent.Type = devfileType
-			if !force {
-
+			if !pipeline.opts.SkipSchemaValidate {
 				// If sample, validate devfile associated with sample as well
 				// Can't handle during registry build since we don't have access to devfile library/parser
 				if indexComponent.Type == schema.SampleDevfileType && validateSamples {
-					devfilePath := filepath.Join(samplesDir, devfileEntry.Name, "devfile.yaml")
-					_, err := os.Stat(filepath.Join(devfilePath))
-					if err != nil {
-						// This error shouldn't occur since we check for the devfile's existence during registry build, but check for it regardless
-						return nil, fmt.Errorf("%s devfile sample does not have a devfile.yaml: %v", indexComponent.Name, err)
+					var sampleDevfileDirs []string
+					if indexComponent.Versions != nil && len(indexComponent.Versions) > 0 {
+						for _, version := range indexComponent.Versions {
+							sampleDevfileDirs = append(sampleDevfileDirs, filepath.Join(samplesDir, devfileEntry.Name, version.Version))
+						}
+					} else {
+						sampleDevfileDirs = append(sampleDevfileDirs, filepath.Join(samplesDir, devfileEntry.Name))
 					}
+					for _, sampleDevfileDir := range sampleDevfileDirs {
+						// Allow devfile.yaml or .devfile.yaml, matching the local stack behavior
+						devfilePath, err := resolveDevfilePath(sampleDevfileDir)
+						if err != nil {
+							return nil, fmt.Errorf("%s sample devfile is not valid: %v", devfileEntry.Name, err)
+						}
+						if _, err := os.Stat(devfilePath); err != nil {
+							// This error shouldn't occur since we check for the devfile's existence during registry build, but check for it regardless
+							return nil, fmt.Errorf("%s devfile sample does not have a devfile.yaml: %v", indexComponent.Name, err)
+						}
 
-					// Validate the sample devfile
-					_, err = devfileParser.ParseAndValidate(devfilePath)
-					if err != nil {
-						return nil, fmt.Errorf("%s sample devfile is not valid: %v", devfileEntry.Name, err)
+						// Validate the sample devfile
+						pipeline.time(StageSchemaValidate, func() {
+							_, _, err = devfileParser.ParseDevfileAndValidate(parser.ParserArgs{Path: devfilePath})
+						})
+						if err != nil {
+							return nil, fmt.Errorf("%s sample devfile is not valid: %v", devfileEntry.Name, err)
+						}
 					}
 				}
+			}
 
+			if !pipeline.opts.SkipDeepValidate {
 				// Index component validation
-				err := validateIndexComponent(indexComponent, devfileType)
+				var err error
+				pipeline.time(StageDeepValidate, func() {
+					err = validateIndexComponent(indexComponent, devfileType)
+				})
 				switch err.(type) {
-				case *MissingProviderError, *MissingSupportUrlError, *MissingArchError:
+				case *MissingProviderError, *MissingSupportUrlError, *MissingArchError, *InvalidArchError:
 					// log to the console as FYI if the devfile has no architectures/provider/supportUrl
 					fmt.Printf("%s", err.Error())
+					pipeline.opts.Report.record(indexComponent.Name, "", SeverityWarning, err)
 				default:
 					// only return error if we dont want to print
 					if err != nil {
@@ -278,6 +1200,7 @@ func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Sche
 					}
 				}
 			}
+			setVersionSummaryFields(&indexComponent)
 			index = append(index, indexComponent)
 		}
 	}
@@ -285,23 +1208,124 @@ func parseExtraDevfileEntries(registryDirPath string, force bool) ([]schema.Sche
 	return index, nil
 }
 
+func parseStackInfo(stackYamlPath string) (schema.Schema, error) {
+	var index schema.Schema
+	bytes, err := ioutil.ReadFile(stackYamlPath)
+	if err != nil {
+		return schema.Schema{}, fmt.Errorf("failed to read %s: %v", stackYamlPath, err)
+	}
+	bytes = normalizeYAMLInput(bytes, stackYamlPath)
+	err = yaml.Unmarshal(bytes, &index)
+	if err != nil {
+		return schema.Schema{}, fmt.Errorf("failed to unmarshal %s data: %v", stackYamlPath, err)
+	}
+	return index, nil
+}
+
 // checkForRequiredMetadata validates that a given devfile has the necessary metadata fields
 func checkForRequiredMetadata(devfileObj parser.DevfileObj) []error {
 	devfileMetadata := devfileObj.Data.GetMetadata()
 	var metadataErrors []error
 
 	if devfileMetadata.Name == "" {
-		metadataErrors = append(metadataErrors, fmt.Errorf("metadata.name is not set"))
+		metadataErrors = append(metadataErrors, &MetadataValidationError{field: "name", code: CodeMetadataNameNotSet})
 	}
 	if devfileMetadata.DisplayName == "" {
-		metadataErrors = append(metadataErrors, fmt.Errorf("metadata.displayName is not set"))
+		metadataErrors = append(metadataErrors, &MetadataValidationError{field: "displayName", code: CodeMetadataDisplayNameNotSet})
 	}
 	if devfileMetadata.Language == "" {
-		metadataErrors = append(metadataErrors, fmt.Errorf("metadata.language is not set"))
+		metadataErrors = append(metadataErrors, &MetadataValidationError{field: "language", code: CodeMetadataLanguageNotSet})
 	}
 	if devfileMetadata.ProjectType == "" {
-		metadataErrors = append(metadataErrors, fmt.Errorf("metadata.projectType is not set"))
+		metadataErrors = append(metadataErrors, &MetadataValidationError{field: "projectType", code: CodeMetadataProjectTypeNotSet})
 	}
 
 	return metadataErrors
 }
+
+func validateStackInfo(stackInfo schema.Schema, stackfolderDir string) []error {
+	var errors []error
+
+	if stackInfo.Name == "" {
+		errors = append(errors, fmt.Errorf("name is not set in stack.yaml"))
+	}
+	if stackInfo.DisplayName == "" {
+		errors = append(errors, fmt.Errorf("displayName is not set stack.yaml"))
+	}
+	if stackInfo.Icon == "" {
+		errors = append(errors, fmt.Errorf("icon is not set stack.yaml"))
+	}
+	if stackInfo.Versions == nil || len(stackInfo.Versions) == 0 {
+		errors = append(errors, fmt.Errorf("versions list is not set stack.yaml, or is empty"))
+	}
+	if stackInfo.Maturity != "" {
+		if _, err := schema.ParseMaturity(string(stackInfo.Maturity)); err != nil {
+			errors = append(errors, fmt.Errorf("stack.yaml has an invalid maturity: %v", err))
+		}
+	}
+	hasDefault := false
+	for _, version := range stackInfo.Versions {
+		if version.Default {
+			if !hasDefault {
+				hasDefault = true
+			} else {
+				errors = append(errors, fmt.Errorf("stack.yaml has multiple default versions"))
+			}
+		}
+
+		if version.Git == nil {
+			versionFolder := path.Join(stackfolderDir, version.Version)
+			err := dirExists(versionFolder)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("cannot find resorce folder for version %s defined in stack.yaml: %v", version.Version, err))
+			}
+		}
+
+		errors = append(errors, checkForInvalidToolVersions(stackInfo.Name, version.MinimumToolVersions)...)
+	}
+	if !hasDefault {
+		errors = append(errors, fmt.Errorf("stack.yaml does not contain a default version"))
+	}
+
+	for _, dir := range danglingVersionDirs(stackInfo, stackfolderDir) {
+		fmt.Printf("%s has a version directory %q that is not declared in stack.yaml\n", stackInfo.Name, dir)
+	}
+
+	return errors
+}
+
+// danglingVersionDirs returns the names of version directories under stackfolderDir that are not
+// declared as a version in stack.yaml. These are reported as warnings, not errors, since a stray
+// directory shouldn't fail the build the way a missing one does, but it likely indicates orphaned
+// content that will never be indexed or served.
+func danglingVersionDirs(stackInfo schema.Schema, stackfolderDir string) []string {
+	declaredVersions := map[string]bool{}
+	for _, version := range stackInfo.Versions {
+		declaredVersions[version.Version] = true
+	}
+
+	entries, err := ioutil.ReadDir(stackfolderDir)
+	if err != nil {
+		return nil
+	}
+
+	var dangling []string
+	for _, entry := range entries {
+		if !entry.IsDir() || declaredVersions[entry.Name()] {
+			continue
+		}
+		dangling = append(dangling, entry.Name())
+	}
+
+	return dangling
+}
+
+// In checks if the value is in the array
+func inArray(arr []string, value string) bool {
+	for _, item := range arr {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package library
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// MigrationSummary reports which stacks were converted from the legacy flat layout (a single
+// devfile.yaml directly under stacks/<name>/) to the versioned stack.yaml layout, and which
+// were left untouched because they didn't look like the legacy layout.
+type MigrationSummary struct {
+	Migrated []string
+	Skipped  []string
+}
+
+// MigrateLegacyRegistry converts every stack under registryDirPath/stacks that still uses the
+// legacy flat layout (a bare devfile.yaml in the stack directory, no version subdirectories) to
+// the versioned stack.yaml layout: the devfile is moved into a version subdirectory and a
+// stack.yaml is generated from its metadata, with that version marked as the default. Stacks that
+// already have a stack.yaml, or that have no devfile.yaml at all, are left untouched and reported
+// as skipped.
+func MigrateLegacyRegistry(registryDirPath string) (MigrationSummary, error) {
+	var summary MigrationSummary
+
+	stacksDir := filepath.Join(registryDirPath, "stacks")
+	entries, err := ioutil.ReadDir(stacksDir)
+	if err != nil {
+		return summary, fmt.Errorf("failed to read %s: %v", stacksDir, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		stackDir := filepath.Join(stacksDir, name)
+		devfilePath := filepath.Join(stackDir, "devfile.yaml")
+		stackYamlPath := filepath.Join(stackDir, "stack.yaml")
+
+		if fileExists(stackYamlPath) || !fileExists(devfilePath) {
+			summary.Skipped = append(summary.Skipped, name)
+			continue
+		}
+
+		if err := migrateStack(stackDir, name, devfilePath, stackYamlPath); err != nil {
+			return summary, fmt.Errorf("failed to migrate stack %s: %v", name, err)
+		}
+		summary.Migrated = append(summary.Migrated, name)
+	}
+
+	return summary, nil
+}
+
+// migrateStack moves a single legacy devfile.yaml into a version subdirectory named after the
+// devfile's own version (falling back to defaultScaffoldVersion if it doesn't declare one), and
+// writes a stack.yaml describing it as that version's sole, default version.
+func migrateStack(stackDir, name, devfilePath, stackYamlPath string) error {
+	devfileBytes, err := ioutil.ReadFile(devfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read devfile.yaml: %v", err)
+	}
+	devfileBytes = normalizeYAMLInput(devfileBytes, devfilePath)
+
+	var devfile indexSchema.Devfile
+	if err := yaml.Unmarshal(devfileBytes, &devfile); err != nil {
+		return fmt.Errorf("failed to parse devfile.yaml: %v", err)
+	}
+
+	version := devfile.Meta.Version
+	if version == "" {
+		version = defaultScaffoldVersion
+	}
+	versionDir := filepath.Join(stackDir, version)
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", versionDir, err)
+	}
+	if err := os.Rename(devfilePath, filepath.Join(versionDir, "devfile.yaml")); err != nil {
+		return fmt.Errorf("failed to move devfile.yaml: %v", err)
+	}
+
+	displayName := devfile.Meta.DisplayName
+	if displayName == "" {
+		displayName = name
+	}
+	stackInfo := indexSchema.StackInfo{
+		Name:        name,
+		DisplayName: displayName,
+		Description: devfile.Meta.Description,
+		Icon:        devfile.Meta.Icon,
+		Versions: []indexSchema.Version{
+			{
+				Version: version,
+				Default: true,
+			},
+		},
+	}
+	stackYamlBytes, err := yaml.Marshal(&stackInfo)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stack.yaml: %v", err)
+	}
+	if err := ioutil.WriteFile(stackYamlPath, stackYamlBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write stack.yaml: %v", err)
+	}
+
+	return nil
+}
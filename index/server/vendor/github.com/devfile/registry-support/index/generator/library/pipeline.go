@@ -0,0 +1,116 @@
+package library
+
+import "time"
+
+// ValidationStage identifies one phase of the index-generation pipeline: reading and
+// unmarshalling devfile content, validating it against the devfile schema, checking for
+// required index metadata, and the registry-specific "deep" checks (links, resources,
+// default version, provider/supportUrl/architectures).
+type ValidationStage string
+
+const (
+	StageParse            ValidationStage = "parse"
+	StageSchemaValidate   ValidationStage = "schemaValidate"
+	StageMetadataValidate ValidationStage = "metadataValidate"
+	StageDeepValidate     ValidationStage = "deepValidate"
+)
+
+// StageTiming reports how long one pipeline stage took, summed across every devfile
+// processed during a single GenerateIndexStructWithOptions call.
+type StageTiming struct {
+	Stage    ValidationStage
+	Duration time.Duration
+}
+
+// ValidationOptions configures which validation stages GenerateIndexStructWithOptions runs.
+// Skipping a stage speeds up local iteration at the cost of the checks it would have caught;
+// StageParse can't be skipped since every later stage, and the index itself, depends on it.
+type ValidationOptions struct {
+	SkipSchemaValidate   bool
+	SkipMetadataValidate bool
+	SkipDeepValidate     bool
+	// SkipSamples excludes sample devfile entries from the generated index, for registries
+	// that publish samples separately or not at all. Mutually exclusive with SamplesOnly.
+	SkipSamples bool
+	// SamplesOnly excludes stack devfile entries from the generated index, keeping only
+	// samples. Mutually exclusive with SkipSamples.
+	SamplesOnly bool
+	// StrictPathValidation turns cross-platform resource path issues (case-insensitive
+	// filename collisions, characters or reserved names invalid on Windows) into a build
+	// failure. When false, the same issues are only printed to the console as a warning,
+	// matching how a missing provider/supportUrl/architectures is handled.
+	StrictPathValidation bool
+	// StrictMediaTypeValidation turns a resource file with no OCI media type recognized by the
+	// index server's push step into a build failure. When false, the same issue is only printed
+	// to the console as a warning, matching StrictPathValidation.
+	StrictMediaTypeValidation bool
+	// StrictOuterloopValidation turns an Image or Kubernetes/Openshift component whose uri
+	// references a file missing from the stack version into a build failure. When false, the same
+	// issue is only printed to the console as a warning, matching StrictPathValidation.
+	StrictOuterloopValidation bool
+	// EmbedDevfiles includes each version's raw devfile.yaml content inline on its index entry
+	// (Version.InlineDevfile), so a client can render a whole catalog from a single index fetch
+	// instead of a second round trip per stack it's interested in.
+	EmbedDevfiles bool
+	// EmbedDevfilesMaxBytes caps the size of a devfile embedded via EmbedDevfiles; a devfile
+	// larger than this is left out and flagged via Version.InlineDevfileTruncated instead of
+	// bloating the index for every client. A non-positive value uses defaultEmbedDevfileMaxBytes.
+	// Only meaningful when EmbedDevfiles is set.
+	EmbedDevfilesMaxBytes int64
+	// Report, when non-nil, is appended with a ValidationIssue for every non-fatal validation
+	// finding (missing provider/supportUrl/architectures, cross-platform path issues, unsupported
+	// media types, missing outerloop resources) that generation would otherwise only print to the
+	// console. See ValidateRegistry for a convenience wrapper that always sets this.
+	Report *ValidationReport
+	// Incremental, when non-nil, enables incremental generation: a stack whose folder's
+	// ContentHash matches Incremental.Cache's entry for it reuses its entry from
+	// Incremental.PreviousIndex instead of being reparsed and revalidated. See
+	// GenerateIndexStructIncremental for a convenience wrapper that always sets this.
+	Incremental *IncrementalOptions
+	// Enrichers run, in order, against every successfully parsed and validated stack entry before
+	// it's added to the index, so a caller can attach computed fields (e.g. an internal cost
+	// center or security scan status) this package has no built-in knowledge of. An enricher
+	// error fails generation, the same as any other stage error.
+	Enrichers []Enricher
+	// Testers run, in order, against every successfully parsed and validated stack version, e.g.
+	// an odo-based build of its default starter project inside a container, so a broken stack is
+	// caught before publication. Every result is recorded in Report as a TestResult regardless of
+	// pass/fail; set StrictTestValidation to additionally fail generation on a failing test.
+	Testers []StackTester
+	// StrictTestValidation turns a failing Testers result into a build failure. When false, results
+	// are only recorded in Report, matching how StrictPathValidation controls whether path issues
+	// are fatal.
+	StrictTestValidation bool
+	// StackGitDownload configures how a stack.yaml version with a Git source is downloaded.
+	// Zero-valued fields fall back to defaultStackGitDownloadConcurrency parallel downloads and no
+	// per-download timeout.
+	StackGitDownload StackGitDownloadOptions
+}
+
+// stagePipeline accumulates per-stage timing across a single index generation run.
+type stagePipeline struct {
+	opts    ValidationOptions
+	timings map[ValidationStage]time.Duration
+}
+
+func newStagePipeline(opts ValidationOptions) *stagePipeline {
+	return &stagePipeline{opts: opts, timings: map[ValidationStage]time.Duration{}}
+}
+
+// time runs f, attributing its wall-clock duration to the given stage.
+func (p *stagePipeline) time(stage ValidationStage, f func()) {
+	start := time.Now()
+	f()
+	p.timings[stage] += time.Since(start)
+}
+
+// report returns the accumulated timing for every stage, in pipeline order, regardless of
+// whether a stage ran or was skipped (a skipped stage simply reports a zero duration).
+func (p *stagePipeline) report() []StageTiming {
+	stages := []ValidationStage{StageParse, StageSchemaValidate, StageMetadataValidate, StageDeepValidate}
+	timings := make([]StageTiming, 0, len(stages))
+	for _, stage := range stages {
+		timings = append(timings, StageTiming{Stage: stage, Duration: p.timings[stage]})
+	}
+	return timings
+}
@@ -0,0 +1,63 @@
+package library
+
+import (
+	"html/template"
+	"os"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// catalogReportTemplate renders a static, self-contained HTML page listing every stack and
+// sample in the generated index, along with each of its versions and whether validation passed.
+// It has no external CSS/JS dependencies so it can be reviewed offline before a registry is pushed.
+const catalogReportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Devfile Registry Catalog Report</title>
+<style>
+  body { font-family: sans-serif; margin: 2rem; color: #222; }
+  h1 { margin-bottom: 0.25rem; }
+  .summary { color: #555; margin-bottom: 1.5rem; }
+  .card { border: 1px solid #ddd; border-radius: 6px; padding: 1rem; margin-bottom: 1rem; }
+  .card h2 { margin: 0 0 0.25rem 0; }
+  .type { display: inline-block; font-size: 0.75rem; text-transform: uppercase; color: #fff; background: #555; border-radius: 3px; padding: 0.1rem 0.4rem; margin-left: 0.5rem; }
+  .versions { margin-top: 0.5rem; }
+  .version { font-family: monospace; margin-right: 0.75rem; }
+  .default { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Devfile Registry Catalog Report</h1>
+<p class="summary">{{len .}} entries</p>
+{{range .}}
+<div class="card">
+  <h2>{{.DisplayName}}<span class="type">{{.Type}}</span></h2>
+  <p>{{.Description}}</p>
+  <div class="versions">
+  {{range .Versions}}
+    <span class="version{{if .Default}} default{{end}}">{{.Version}}{{if .Default}} (default){{end}}</span>
+  {{end}}
+  </div>
+</div>
+{{end}}
+</body>
+</html>
+`
+
+// CreateHTMLCatalogReport renders a static HTML catalog report of the given index to reportFilePath,
+// useful for quickly reviewing what a registry build will publish before pushing it.
+func CreateHTMLCatalogReport(index []schema.Schema, reportFilePath string) error {
+	tmpl, err := template.New("catalog").Parse(catalogReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(reportFilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return tmpl.Execute(file, index)
+}
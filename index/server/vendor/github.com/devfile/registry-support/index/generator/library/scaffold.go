@@ -0,0 +1,130 @@
+package library
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultScaffoldVersion is used when ScaffoldOptions.Version is left empty.
+const defaultScaffoldVersion = "1.0.0"
+
+// ScaffoldOptions customizes the stack created by ScaffoldStack. Any field left empty falls back
+// to a placeholder value that a contributor is expected to replace before publishing the stack.
+type ScaffoldOptions struct {
+	Version     string
+	DisplayName string
+	Description string
+	Provider    string
+	SupportUrl  string
+	Owners      []string
+}
+
+// ScaffoldStack creates a new stack directory under registryDirPath/stacks/name: a stack.yaml, a
+// version folder containing a devfile.yaml with every field GenerateIndexStructWithOptions
+// requires (provider, supportUrl, architectures, displayName, language, projectType), and an
+// OWNERS file, so a new contributor's first stack passes validation instead of failing CI on
+// fields nothing pointed out were required.
+func ScaffoldStack(registryDirPath string, name string, opts ScaffoldOptions) error {
+	if name == "" {
+		return fmt.Errorf("stack name must not be empty")
+	}
+
+	version := opts.Version
+	if version == "" {
+		version = defaultScaffoldVersion
+	}
+	displayName := opts.DisplayName
+	if displayName == "" {
+		displayName = name
+	}
+	description := opts.Description
+	if description == "" {
+		description = fmt.Sprintf("%s stack", displayName)
+	}
+	provider := opts.Provider
+	if provider == "" {
+		provider = "TODO_SET_PROVIDER"
+	}
+	supportUrl := opts.SupportUrl
+	if supportUrl == "" {
+		supportUrl = "TODO_SET_SUPPORT_URL"
+	}
+
+	stackDir := filepath.Join(registryDirPath, "stacks", name)
+	versionDir := filepath.Join(stackDir, version)
+	if fileExists(stackDir) {
+		return fmt.Errorf("%s already exists", stackDir)
+	}
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", versionDir, err)
+	}
+
+	stackYaml := fmt.Sprintf(`name: %s
+description: %s
+displayName: %s
+icon: TODO_SET_ICON_URL
+versions:
+  - version: %s
+    default: true # should have one and only one default version
+`, name, description, displayName, version)
+	if err := ioutil.WriteFile(filepath.Join(stackDir, "stack.yaml"), []byte(stackYaml), 0644); err != nil {
+		return fmt.Errorf("failed to write stack.yaml: %v", err)
+	}
+
+	devfileYaml := fmt.Sprintf(`schemaVersion: 2.2.0
+metadata:
+  name: %s
+  version: %s
+  displayName: %s
+  description: %s
+  provider: %s
+  supportUrl: %s
+  language: TODO_SET_LANGUAGE
+  projectType: TODO_SET_PROJECT_TYPE
+  tags: []
+  architectures:
+    - amd64
+components:
+  - name: runtime
+    container:
+      image: TODO_SET_CONTAINER_IMAGE
+      mountSources: true
+      memoryLimit: 1024Mi
+commands:
+  - id: build
+    exec:
+      commandLine: TODO_SET_BUILD_COMMAND
+      component: runtime
+      group:
+        kind: build
+        isDefault: true
+  - id: run
+    exec:
+      commandLine: TODO_SET_RUN_COMMAND
+      component: runtime
+      group:
+        kind: run
+        isDefault: true
+`, name, version, displayName, description, provider, supportUrl)
+	if err := ioutil.WriteFile(filepath.Join(versionDir, "devfile.yaml"), []byte(devfileYaml), 0644); err != nil {
+		return fmt.Errorf("failed to write devfile.yaml: %v", err)
+	}
+
+	owners := opts.Owners
+	if len(owners) == 0 {
+		owners = []string{"TODO_SET_GITHUB_USERNAME"}
+	}
+	var ownersYaml strings.Builder
+	ownersYaml.WriteString("approvers:\n")
+	for _, owner := range owners {
+		ownersYaml.WriteString(fmt.Sprintf("  - %s\n", owner))
+	}
+	if err := ioutil.WriteFile(filepath.Join(stackDir, "OWNERS"), []byte(ownersYaml.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write OWNERS: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devfile/registry-support/index/generator/gitutil"
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// defaultStackGitDownloadConcurrency is used when StackGitDownloadOptions.Concurrency is
+// non-positive.
+const defaultStackGitDownloadConcurrency = 4
+
+// StackGitDownloadOptions bounds how downloadStackVersionsGit fetches a stack.yaml version's
+// Git-sourced content.
+type StackGitDownloadOptions struct {
+	// Concurrency caps how many versions are downloaded at once. Non-positive defaults to
+	// defaultStackGitDownloadConcurrency.
+	Concurrency int
+	// PerDownloadTimeout caps how long a single version's clone may take before it's abandoned
+	// and reported as failed. Zero (the default) means no per-download limit.
+	PerDownloadTimeout time.Duration
+}
+
+// stackGitDownloadJob is one stack.yaml version whose content needs to be cloned from git.Url
+// into destDir before it can be parsed like a normal locally-vendored version.
+type stackGitDownloadJob struct {
+	label   string
+	git     *schema.Git
+	destDir string
+}
+
+// downloadStackVersionsGit clones every job's Git source into its destDir with bounded
+// concurrency, so a stack.yaml declaring many git-sourced versions doesn't download them one at
+// a time. ctx bounds every download; opts.PerDownloadTimeout additionally bounds each one
+// individually. Every job is attempted regardless of another job's failure; failures are
+// aggregated into a single *StackGitDownloadError instead of aborting after the first one.
+func downloadStackVersionsGit(ctx context.Context, jobs []stackGitDownloadJob, opts StackGitDownloadOptions) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultStackGitDownloadConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failures := map[string]error{}
+
+	for _, job := range jobs {
+		job := job
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			jobCtx := ctx
+			if opts.PerDownloadTimeout > 0 {
+				var cancel context.CancelFunc
+				jobCtx, cancel = context.WithTimeout(ctx, opts.PerDownloadTimeout)
+				defer cancel()
+			}
+
+			if err := downloadStackVersionGit(jobCtx, job.git, job.destDir); err != nil {
+				mu.Lock()
+				failures[job.label] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) == 0 {
+		return nil
+	}
+	return &StackGitDownloadError{Failures: failures}
+}
+
+// downloadStackVersionGit clones git.Url at git.Revision into a temp directory and copies
+// git.SubDir (or the whole clone, if unset) into destDir, mirroring how snapshotSample resolves
+// a sample's git source.
+func downloadStackVersionGit(ctx context.Context, git *schema.Git, destDir string) error {
+	cloneDir, err := newTempDir("stack-git-download")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	if err := gitutil.CloneRevision(ctx, git.Url, git.Revision, cloneDir, gitutil.ResolveAuth(git.Url)); err != nil {
+		return err
+	}
+
+	sourceDir := cloneDir
+	if git.SubDir != "" {
+		sourceDir = filepath.Join(cloneDir, git.SubDir)
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+	return gitutil.CopyDir(sourceDir, destDir)
+}
+
+// StackGitDownloadError reports every job that failed in a downloadStackVersionsGit call, keyed
+// by its label (typically "<stack>@<version>"), so a caller can report every broken remote in one
+// pass instead of stopping at the first.
+type StackGitDownloadError struct {
+	Failures map[string]error
+}
+
+func (e *StackGitDownloadError) Error() string {
+	labels := make([]string, 0, len(e.Failures))
+	for label := range e.Failures {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	msg := fmt.Sprintf("failed to download %d stack version(s) from git:", len(labels))
+	for _, label := range labels {
+		msg += fmt.Sprintf("\n  %s: %v", label, e.Failures[label])
+	}
+	return msg
+}
@@ -0,0 +1,27 @@
+package library
+
+import "github.com/devfile/registry-support/index/generator/schema"
+
+// StackTester runs an external verification of a single stack version's content, e.g. an
+// odo-based build of its default starter project inside a container, so a broken stack is caught
+// before publication instead of only being noticed once client tooling starts failing on it.
+// Test is invoked once per successfully parsed and validated stack version, with the version's
+// on-disk directory.
+type StackTester interface {
+	Test(stackVersionDirPath string, entry schema.Schema, version schema.Version) error
+}
+
+// runTesters runs every tester against a stack version, recording a TestResult in report for
+// each one regardless of pass/fail, so a run's results are visible in one place instead of only
+// as console output. A failing test only aborts generation when strict is set, matching how
+// StrictPathValidation controls whether path issues are fatal.
+func runTesters(testers []StackTester, report *ValidationReport, strict bool, stackVersionDirPath string, entry schema.Schema, version schema.Version) error {
+	for _, tester := range testers {
+		err := tester.Test(stackVersionDirPath, entry, version)
+		report.recordTest(entry.Name, version.Version, err)
+		if err != nil && strict {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package library
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/devfile/registry-support/index/generator/schema"
+)
+
+// Validator lets a downstream registry add its own validation rules for index components (e.g.
+// mandatory icon, naming conventions) without forking this library. Validate is called once per
+// index component, in addition to this library's own provider/supportUrl/architecture checks, and
+// should return one error per violation found (nil or an empty slice if the component is valid).
+type Validator interface {
+	Validate(indexComponent schema.Schema, componentType schema.DevfileType) []error
+}
+
+var (
+	validatorsMu sync.Mutex
+	validators   []Validator
+)
+
+// RegisterValidator adds v to the set of validators validateIndexComponent runs against every
+// index component. It's meant to be called once at program startup (e.g. from an init function or
+// main), before GenerateIndexStruct is invoked, not concurrently with index generation.
+func RegisterValidator(v Validator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators = append(validators, v)
+}
+
+// registeredValidators returns the validators RegisterValidator has accumulated so far.
+func registeredValidators() []Validator {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	return append([]Validator(nil), validators...)
+}
+
+// runRegisteredValidators runs every registered validator against indexComponent, returning a
+// single combined error naming every violation found across all of them, or nil if none found any.
+func runRegisteredValidators(indexComponent schema.Schema, componentType schema.DevfileType) error {
+	var errs []error
+	for _, v := range registeredValidators() {
+		errs = append(errs, v.Validate(indexComponent, componentType)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s failed custom validation: %v", indexComponent.Name, errs)
+}
@@ -0,0 +1,93 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// workspaceDirPrefix names every workspace directory this package creates under os.TempDir(), so
+// a crashed run's leftovers can be told apart from unrelated temp files and swept up by a later
+// run.
+const workspaceDirPrefix = "devfile-registry-build-"
+
+// orphanWorkspaceMaxAge is how old an unrelated run's workspace directory must be before it's
+// treated as an orphan left behind by a process that was killed before it could clean up, rather
+// than one that's still in use.
+const orphanWorkspaceMaxAge = 24 * time.Hour
+
+var (
+	workspaceOnce sync.Once
+	workspaceDir  string
+	workspaceErr  error
+)
+
+// newTempDir creates a unique temporary directory, named with prefix, inside this run's managed
+// workspace. It replaces scattered ioutil.TempDir("", prefix) calls for scratch directories (like
+// snapshotSample's clone directory) so they're all cleaned up together by CleanupWorkspace, rather
+// than depending on every call site's own defer os.RemoveAll surviving a kill signal.
+func newTempDir(prefix string) (string, error) {
+	root, err := ensureWorkspace()
+	if err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(root, prefix)
+}
+
+// ensureWorkspace lazily creates this run's workspace directory the first time a temp directory is
+// requested, sweeping orphaned workspace directories left behind by prior crashed runs first, and
+// registers a signal handler so the workspace is still removed if the process is interrupted.
+func ensureWorkspace() (string, error) {
+	workspaceOnce.Do(func() {
+		sweepOrphanWorkspaces()
+
+		dir, err := ioutil.TempDir("", workspaceDirPrefix)
+		if err != nil {
+			workspaceErr = err
+			return
+		}
+		workspaceDir = dir
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			CleanupWorkspace()
+			os.Exit(1)
+		}()
+	})
+	return workspaceDir, workspaceErr
+}
+
+// CleanupWorkspace removes this run's workspace directory, if one was ever created. It's safe to
+// call even if no temp directory was requested. Callers should defer this from the command's entry
+// point so the workspace is removed on normal exit; ensureWorkspace's signal handler covers the
+// interrupted case.
+func CleanupWorkspace() {
+	if workspaceDir != "" {
+		os.RemoveAll(workspaceDir)
+	}
+}
+
+// sweepOrphanWorkspaces removes workspace directories under os.TempDir() left behind by prior runs
+// that were killed before they could call CleanupWorkspace or handle a termination signal.
+func sweepOrphanWorkspaces() {
+	entries, err := ioutil.ReadDir(os.TempDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), workspaceDirPrefix) {
+			continue
+		}
+		if time.Since(entry.ModTime()) < orphanWorkspaceMaxAge {
+			continue
+		}
+		os.RemoveAll(filepath.Join(os.TempDir(), entry.Name()))
+	}
+}
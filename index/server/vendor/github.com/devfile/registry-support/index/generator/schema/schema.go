@@ -1,6 +1,8 @@
 package schema
 
 import (
+	"fmt"
+
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
@@ -134,7 +136,19 @@ type Schema struct {
 	Git               *Git                   `yaml:"git,omitempty" json:"git,omitempty"`
 	Provider          string                 `yaml:"provider,omitempty" json:"provider,omitempty"`
 	SupportUrl        string                 `yaml:"supportUrl,omitempty" json:"supportUrl,omitempty"`
-	Versions          []Version              `yaml:"versions,omitempty" json:"versions,omitempty"`
+	// Maturity communicates how much support the stack's maintainers commit to (incubating,
+	// stable, or deprecated), so catalogs can set expectations before a version-level deprecation
+	// is warranted. Unset for stacks that haven't declared a maturity level.
+	Maturity Maturity  `yaml:"maturity,omitempty" json:"maturity,omitempty"`
+	Versions []Version `yaml:"versions,omitempty" json:"versions,omitempty"`
+	// DefaultVersion is the Version string of whichever entry in Versions has Default set, so a
+	// client can find the version it should pull without scanning Versions itself. Computed
+	// during index generation; empty if no version is flagged as the default.
+	DefaultVersion string `yaml:"defaultVersion,omitempty" json:"defaultVersion,omitempty"`
+	// LatestVersion is the Version string of the numerically highest entry in Versions,
+	// independent of which one (if any) is flagged as the default. Computed during index
+	// generation; empty if Versions is empty.
+	LatestVersion string `yaml:"latestVersion,omitempty" json:"latestVersion,omitempty"`
 }
 
 // DevfileType describes the type of devfile
@@ -148,6 +162,96 @@ const (
 	StackDevfileType DevfileType = "stack"
 )
 
+// devfileTypes is the set of DevfileType values accepted by ParseDevfileType
+var devfileTypes = map[DevfileType]bool{
+	SampleDevfileType: true,
+	StackDevfileType:  true,
+}
+
+// ParseDevfileType validates that devfileType is a known DevfileType and returns it typed.
+func ParseDevfileType(devfileType string) (DevfileType, error) {
+	parsed := DevfileType(devfileType)
+	if !devfileTypes[parsed] {
+		return "", fmt.Errorf("%q is not a supported devfile type", devfileType)
+	}
+	return parsed, nil
+}
+
+// Architecture describes a CPU architecture that a stack or sample devfile supports
+type Architecture string
+
+const (
+	// AMD64 represents the amd64 architecture
+	AMD64 Architecture = "amd64"
+
+	// ARM64 represents the arm64 architecture
+	ARM64 Architecture = "arm64"
+
+	// PPC64LE represents the ppc64le architecture
+	PPC64LE Architecture = "ppc64le"
+
+	// S390X represents the s390x architecture
+	S390X Architecture = "s390x"
+)
+
+// architectures is the set of Architecture values accepted by ParseArchitecture
+var architectures = map[Architecture]bool{
+	AMD64:   true,
+	ARM64:   true,
+	PPC64LE: true,
+	S390X:   true,
+}
+
+// ParseArchitecture validates that architecture is a known Architecture and returns it typed.
+func ParseArchitecture(architecture string) (Architecture, error) {
+	parsed := Architecture(architecture)
+	if !architectures[parsed] {
+		return "", fmt.Errorf("%q is not a supported architecture", architecture)
+	}
+	return parsed, nil
+}
+
+// ValidateArchitectures validates that every entry in archs is a known Architecture.
+func ValidateArchitectures(archs []string) error {
+	for _, arch := range archs {
+		if _, err := ParseArchitecture(arch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Maturity describes how much support a stack's maintainers commit to.
+type Maturity string
+
+const (
+	// IncubatingMaturity marks a stack still stabilizing; breaking changes are more likely.
+	IncubatingMaturity Maturity = "incubating"
+
+	// StableMaturity marks a stack with a settled devfile and supported upgrade path.
+	StableMaturity Maturity = "stable"
+
+	// DeprecatedMaturity marks a stack no longer recommended for new projects. Prefer setting
+	// Deprecated on the individual Version instead when only some versions are affected.
+	DeprecatedMaturity Maturity = "deprecated"
+)
+
+// maturityLevels is the set of Maturity values accepted by ParseMaturity
+var maturityLevels = map[Maturity]bool{
+	IncubatingMaturity: true,
+	StableMaturity:     true,
+	DeprecatedMaturity: true,
+}
+
+// ParseMaturity validates that maturity is a known Maturity and returns it typed.
+func ParseMaturity(maturity string) (Maturity, error) {
+	parsed := Maturity(maturity)
+	if !maturityLevels[parsed] {
+		return "", fmt.Errorf("%q is not a supported maturity level", maturity)
+	}
+	return parsed, nil
+}
+
 // StarterProject is the devfile starter project
 type StarterProject struct {
 	Name string `yaml:"name,omitempty" json:"name,omitempty"`
@@ -157,16 +261,16 @@ type StarterProject struct {
 type Devfile struct {
 	Meta            Schema           `yaml:"metadata,omitempty" json:"metadata,omitempty"`
 	StarterProjects []StarterProject `yaml:"starterProjects,omitempty" json:"starterProjects,omitempty"`
-	SchemaVersion   string			  `yaml:"schemaVersion,omitempty" json:"schemaVersion,omitempty"`
+	SchemaVersion   string           `yaml:"schemaVersion,omitempty" json:"schemaVersion,omitempty"`
 }
 
 // Git stores the information of remote repositories
 type Git struct {
-	Remotes 	map[string]string 		`yaml:"remotes,omitempty" json:"remotes,omitempty"`
-	Url			string                 	`yaml:"url,omitempty" json:"url,omitempty"`
-	RemoteName  string 					`yaml:"remoteName,omitempty" json:"remoteName,omitempty"`
-	SubDir		string                 	`yaml:"subDir,omitempty" json:"subDir,omitempty"`
-	Revision	string                 	`yaml:"revision,omitempty" json:"revision,omitempty"`
+	Remotes    map[string]string `yaml:"remotes,omitempty" json:"remotes,omitempty"`
+	Url        string            `yaml:"url,omitempty" json:"url,omitempty"`
+	RemoteName string            `yaml:"remoteName,omitempty" json:"remoteName,omitempty"`
+	SubDir     string            `yaml:"subDir,omitempty" json:"subDir,omitempty"`
+	Revision   string            `yaml:"revision,omitempty" json:"revision,omitempty"`
 }
 
 // ExtraDevfileEntries is the extraDevfileEntries structure that is used by index component
@@ -177,24 +281,67 @@ type ExtraDevfileEntries struct {
 
 // Version stores the top-level stack information defined within stack.yaml
 type StackInfo struct {
-	Name              string                 `yaml:"name,omitempty" json:"name,omitempty"`
-	DisplayName       string                 `yaml:"displayName,omitempty" json:"displayName,omitempty"`
-	Description       string                 `yaml:"description,omitempty" json:"description,omitempty"`
-	Icon              string                 `yaml:"icon,omitempty" json:"icon,omitempty"`
-	Versions          []Version              `yaml:"versions,omitempty" json:"versions,omitempty"`
+	Name        string    `yaml:"name,omitempty" json:"name,omitempty"`
+	DisplayName string    `yaml:"displayName,omitempty" json:"displayName,omitempty"`
+	Description string    `yaml:"description,omitempty" json:"description,omitempty"`
+	Icon        string    `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Versions    []Version `yaml:"versions,omitempty" json:"versions,omitempty"`
 }
 
 // Version stores the information for each stack version
 type Version struct {
-	Version			  string				 `yaml:"version,omitempty" json:"version,omitempty"`
-	SchemaVersion	  string				 `yaml:"schemaVersion,omitempty" json:"schemaVersion,omitempty"`
-	Default			  bool					 `yaml:"default,omitempty" json:"default,omitempty"`
-	Git				  *Git                	 `yaml:"git,omitempty" json:"git,omitempty"`
-	Description       string                 `yaml:"description,omitempty" json:"description,omitempty"`
-	Tags              []string               `yaml:"tags,omitempty" json:"tags,omitempty"`
-	Architectures     []string               `yaml:"architectures,omitempty" json:"architectures,omitempty"`
-	Icon              string                 `yaml:"icon,omitempty" json:"icon,omitempty"`
-	Links             map[string]string      `yaml:"links,omitempty" json:"links,omitempty"`
-	Resources         []string               `yaml:"resources,omitempty" json:"resources,omitempty"`
-	StarterProjects   []string               `yaml:"starterProjects,omitempty" json:"starterProjects,omitempty"`
-}
\ No newline at end of file
+	Version         string            `yaml:"version,omitempty" json:"version,omitempty"`
+	SchemaVersion   string            `yaml:"schemaVersion,omitempty" json:"schemaVersion,omitempty"`
+	Default         bool              `yaml:"default,omitempty" json:"default,omitempty"`
+	Git             *Git              `yaml:"git,omitempty" json:"git,omitempty"`
+	Description     string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Tags            []string          `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Architectures   []string          `yaml:"architectures,omitempty" json:"architectures,omitempty"`
+	Icon            string            `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Links           map[string]string `yaml:"links,omitempty" json:"links,omitempty"`
+	Resources       []string          `yaml:"resources,omitempty" json:"resources,omitempty"`
+	StarterProjects []string          `yaml:"starterProjects,omitempty" json:"starterProjects,omitempty"`
+	Deprecated      bool              `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	SunsetDate      string            `yaml:"sunsetDate,omitempty" json:"sunsetDate,omitempty"`
+	Successor       string            `yaml:"successor,omitempty" json:"successor,omitempty"`
+	// Digest is the sha256 digest of this version's snapshot archive (stacks: the OCI manifest;
+	// samples: the packaged source snapshot, when snapshotting is enabled), so clients can verify
+	// the content they pulled instead of trusting a mutable version tag.
+	Digest string `yaml:"digest,omitempty" json:"digest,omitempty"`
+	// ChangelogUrl points at this version's packaged CHANGELOG.md resource, when the registry was
+	// built with --generate-changelogs, for display in the viewer.
+	ChangelogUrl string `yaml:"changelogUrl,omitempty" json:"changelogUrl,omitempty"`
+	// ArchiveContentEncoding is the compression applied to this version's snapshot archive
+	// (currently "gzip" or "identity" for samples, see library.CompressionAlgorithm), so a
+	// puller knows how to decode the archive it downloaded. Empty means the archive predates
+	// this field and should be treated as "gzip", matching the format always used before it.
+	ArchiveContentEncoding string `yaml:"archiveContentEncoding,omitempty" json:"archiveContentEncoding,omitempty"`
+	// ArchiveSize is the snapshot archive's size on the wire, in bytes, after
+	// ArchiveContentEncoding is applied.
+	ArchiveSize int64 `yaml:"archiveSize,omitempty" json:"archiveSize,omitempty"`
+	// ArchiveUncompressedSize is the snapshot archive's size in bytes before compression, so a
+	// client can estimate disk space needed after extraction without downloading first.
+	ArchiveUncompressedSize int64 `yaml:"archiveUncompressedSize,omitempty" json:"archiveUncompressedSize,omitempty"`
+	// MinimumToolVersions declares the oldest version of each consuming tool that this version of
+	// the stack is known to work with, so a client can warn a user running an older odo, Che, or
+	// devfile CLI instead of letting them hit a confusing failure partway through. Unset for
+	// stacks that haven't declared a minimum.
+	MinimumToolVersions *ToolVersions `yaml:"minimumToolVersions,omitempty" json:"minimumToolVersions,omitempty"`
+	// InlineDevfile holds this version's devfile.yaml content verbatim, when the index was built
+	// with --embed-devfiles, so a client can render a stack without a second round trip to fetch
+	// its devfile separately. Unset unless embedding was requested at build time.
+	InlineDevfile string `yaml:"inlineDevfile,omitempty" json:"inlineDevfile,omitempty"`
+	// InlineDevfileTruncated is set when --embed-devfiles was requested but this version's
+	// devfile exceeded the configured size limit and was left out, so the omission is
+	// distinguishable from a devfile that was simply never embedded.
+	InlineDevfileTruncated bool `yaml:"inlineDevfileTruncated,omitempty" json:"inlineDevfileTruncated,omitempty"`
+}
+
+// ToolVersions names minimum versions of the tools known to consume devfile stacks. Every field
+// is an optional, plain semver-like string (e.g. "3.2.0"); a tool absent from this struct is
+// simply not asserted to have a minimum.
+type ToolVersions struct {
+	Odo        string `yaml:"odo,omitempty" json:"odo,omitempty"`
+	Che        string `yaml:"che,omitempty" json:"che,omitempty"`
+	DevfileCLI string `yaml:"devfileCLI,omitempty" json:"devfileCLI,omitempty"`
+}
@@ -12,6 +12,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -30,14 +31,21 @@ const (
 )
 
 var (
-	registryList  = os.Getenv("REGISTRY_LIST")
-	cfgFile       string
-	allResources  bool
-	destDir       string
-	devfileType   string
-	skipTLSVerify bool
-	user          string
-	architectures []string
+	registryList              = os.Getenv("REGISTRY_LIST")
+	cfgFile                   string
+	allResources              bool
+	destDir                   string
+	devfileType               string
+	skipTLSVerify             bool
+	pinnedSPKISHA256          string
+	useDockerCreds            bool
+	digest                    string
+	showDigest                bool
+	user                      string
+	architectures             []string
+	autoArch                  bool
+	maxDownloadBytesPerSecond int64
+	outputFormat              string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -82,22 +90,49 @@ func init() {
 			stack := args[1]
 			var err error
 
+			format, err := library.ParseOutputFormat(outputFormat)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
 			options := library.RegistryOptions{
 				Telemetry: library.TelemetryData{
 					User: "user",
 				},
-				SkipTLSVerify: skipTLSVerify,
+				SkipTLSVerify:        skipTLSVerify,
+				PinnedSPKISHA256:     pinnedSPKISHA256,
+				UseDockerCredentials: useDockerCreds,
 			}
 
 			if len(architectures) > 0 {
 				options.Filter.Architectures = architectures
 			}
+			options.Filter.AutoDetectPlatform = autoArch
+			options.MaxDownloadBytesPerSecond = maxDownloadBytesPerSecond
 
-			if allResources {
+			if digest != "" {
+				err = library.PullStackByDigest(registry, stack, digest, destDir, options)
+			} else if allResources {
 				err = library.PullStackFromRegistry(registry, stack, destDir, options)
 			} else {
 				err = library.PullStackByMediaTypesFromRegistry(registry, stack, library.DevfileMediaTypeList, destDir, options)
 			}
+
+			if format == library.JSONOutputFormat {
+				result := library.PullOutput{
+					SchemaVersion: library.PullSchemaVersion,
+					Registry:      registry,
+					Stack:         stack,
+					Destination:   destDir,
+				}
+				if err != nil {
+					result.Error = err.Error()
+				}
+				json.NewEncoder(os.Stdout).Encode(result)
+				return
+			}
+
 			if err != nil {
 				fmt.Printf("Failed to pull %s from registry %s: %v\n", stack, registry, err)
 			}
@@ -105,9 +140,15 @@ func init() {
 	}
 	pullCmd.Flags().BoolVarP(&allResources, "all", "a", false, "pull all resources of the given stack")
 	pullCmd.Flags().StringArrayVar(&architectures, "arch", []string{}, "architecture filter; example: --arch amd64 --arch arm64")
+	pullCmd.Flags().BoolVar(&autoArch, "auto-arch", false, "when --arch isn't set, filter to the architecture this client is running on instead of returning stacks for every architecture")
+	pullCmd.Flags().Int64Var(&maxDownloadBytesPerSecond, "max-download-bytes-per-sec", 0, "cap download speed in bytes/sec; 0 means unlimited")
 	pullCmd.Flags().StringVar(&destDir, "context", ".", "destination directory that stores stack resources")
 	pullCmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", false, "skip TLS verification")
+	pullCmd.Flags().StringVar(&pinnedSPKISHA256, "pinned-spki-sha256", "", "require the registry's TLS certificate to carry this base64-encoded SHA-256 hash of its Subject Public Key Info")
+	pullCmd.Flags().BoolVar(&useDockerCreds, "use-docker-credentials", false, "resolve registry credentials from the docker CLI config (credential helpers, credential store, or stored auth)")
+	pullCmd.Flags().StringVar(&digest, "digest", "", "pull the stack by this immutable manifest digest instead of its version tag")
 	pullCmd.Flags().StringVar(&user, "user", "", "consumer name")
+	pullCmd.Flags().StringVar(&outputFormat, "output", "table", "output format: table or json")
 
 	var listCmd = &cobra.Command{
 		Use:   "list",
@@ -122,23 +163,34 @@ func init() {
 				Telemetry: library.TelemetryData{
 					User: "user",
 				},
-				SkipTLSVerify: skipTLSVerify,
+				SkipTLSVerify:    skipTLSVerify,
+				PinnedSPKISHA256: pinnedSPKISHA256,
 			}
 
 			if len(architectures) > 0 {
 				options.Filter.Architectures = architectures
 			}
+			options.Filter.AutoDetectPlatform = autoArch
 
-			err := library.PrintRegistry(registryList, devfileType, options)
+			format, err := library.ParseOutputFormat(outputFormat)
 			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			if err := library.PrintRegistryWithFormat(registryList, devfileType, options, showDigest, format); err != nil {
 				fmt.Printf("Failed to list stacks of registry %s: %v\n", registryList, err)
 			}
 		},
 	}
 	listCmd.Flags().StringVar(&devfileType, "type", "", "specify devfile type")
+	listCmd.Flags().BoolVar(&showDigest, "show-digest", false, "resolve and print each stack's manifest digest, so it can be pinned with pull --digest")
 	listCmd.Flags().StringArrayVar(&architectures, "arch", []string{}, "architecture filter; example: --arch amd64 --arch arm64")
+	listCmd.Flags().BoolVar(&autoArch, "auto-arch", false, "when --arch isn't set, filter to the architecture this client is running on instead of listing stacks for every architecture")
 	listCmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", false, "skip TLS verification")
+	listCmd.Flags().StringVar(&pinnedSPKISHA256, "pinned-spki-sha256", "", "require the registry's TLS certificate to carry this base64-encoded SHA-256 hash of its Subject Public Key Info")
 	listCmd.Flags().StringVar(&user, "user", "", "consumer name")
+	listCmd.Flags().StringVar(&outputFormat, "output", "table", "output format: table or json")
 
 	rootCmd.AddCommand(pullCmd, listCmd)
 }
@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// RegistryCapabilities reports which optional index endpoints a registry serves, so a consumer
+// can adjust its UI (e.g. hide a per-version picker) instead of erroring out against an older
+// registry deployment that only serves the legacy index.
+type RegistryCapabilities struct {
+	// SupportsVersionIndex is true if the registry serves /v2index, letting callers resolve or
+	// pull a non-default stack version directly. When false, GetRegistryIndex and InitProject
+	// still work, but only ever operate on each stack's default version.
+	SupportsVersionIndex bool
+}
+
+// GetRegistryCapabilities probes registry for the optional index endpoints it supports.
+func GetRegistryCapabilities(registry string, options RegistryOptions) RegistryCapabilities {
+	return RegistryCapabilities{
+		SupportsVersionIndex: probeEndpoint(registry, "v2index/popular", options),
+	}
+}
+
+// probeEndpoint reports whether registry responds to a GET of endpoint with 200 OK.
+func probeEndpoint(registry string, endpoint string, options RegistryOptions) bool {
+	urlObj, err := url.Parse(registry)
+	if err != nil {
+		return false
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	urlObj = urlObj.ResolveReference(endpointURL)
+
+	req, err := http.NewRequest("GET", urlObj.String(), nil)
+	if err != nil {
+		return false
+	}
+	setHeaders(&req.Header, options)
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			ResponseHeaderTimeout: responseHeaderTimeout,
+			TLSClientConfig:       buildTLSConfig(options),
+		},
+		Timeout: httpRequestTimeout,
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
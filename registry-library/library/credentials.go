@@ -0,0 +1,128 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// dockerConfig mirrors the subset of ~/.docker/config.json used to resolve credentials for
+// OCI registry pulls: per-registry credential helpers, a default credential store, and
+// inline basic-auth entries written by `docker login`.
+type dockerConfig struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`
+	CredsStore  string                      `json:"credsStore"`
+	CredHelpers map[string]string           `json:"credHelpers"`
+}
+
+type dockerConfigAuth struct {
+	Auth string `json:"auth"`
+}
+
+// credentialHelperOutput is the JSON contract implemented by every docker-credential-* helper
+// (ecr-login, gcr, acr, pass, osxkeychain, ...): https://github.com/docker/docker-credential-helpers
+type credentialHelperOutput struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// loadDockerConfig reads the user's docker CLI config file, if one exists. A missing file is
+// not an error: it just means no credential helper or stored auth is configured.
+func loadDockerConfig() (*dockerConfig, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(home, ".docker", "config.json")
+	contents, err := ioutil.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return &dockerConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config dockerConfig
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", configPath, err)
+	}
+	return &config, nil
+}
+
+// resolveCredentialFromHelper invokes the docker-credential-<helper> binary on PATH using the
+// standard credential helper protocol: the registry host is written to stdin, and a JSON
+// document with the resolved username/secret is read back from stdout.
+func resolveCredentialFromHelper(helper, host string) (string, string, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(host)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", "", fmt.Errorf("credential helper %s failed for %s: %v", helper, host, err)
+	}
+
+	var output credentialHelperOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return "", "", fmt.Errorf("failed to parse credential helper %s output for %s: %v", helper, host, err)
+	}
+	return output.Username, output.Secret, nil
+}
+
+// dockerCredentialsFunc returns a docker.ResolverOptions Credentials callback that resolves
+// registry credentials the same way the docker CLI does: a per-registry credential helper
+// (credHelpers), falling back to the default credential store (credsStore), falling back to an
+// inline base64 auth entry written by `docker login`. Any resolution failure is treated as "no
+// credentials available" rather than a hard error, so an anonymous pull can still be attempted.
+func dockerCredentialsFunc() func(string) (string, string, error) {
+	config, configErr := loadDockerConfig()
+
+	return func(host string) (string, string, error) {
+		if configErr != nil || config == nil {
+			return "", "", nil
+		}
+
+		if helper, ok := config.CredHelpers[host]; ok {
+			if username, secret, err := resolveCredentialFromHelper(helper, host); err == nil {
+				return username, secret, nil
+			}
+		}
+
+		if config.CredsStore != "" {
+			if username, secret, err := resolveCredentialFromHelper(config.CredsStore, host); err == nil {
+				return username, secret, nil
+			}
+		}
+
+		if auth, ok := config.Auths[host]; ok && auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err == nil {
+				if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+					return parts[0], parts[1], nil
+				}
+			}
+		}
+
+		return "", "", nil
+	}
+}
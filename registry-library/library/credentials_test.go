@@ -0,0 +1,75 @@
+package library
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDockerCredentialsFuncInlineAuth(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper lookup relies on a unix-style home directory in this test")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cr3t"))
+	config := map[string]interface{}{
+		"auths": map[string]interface{}{
+			"registry.example.com": map[string]string{"auth": auth},
+		},
+	}
+	writeDockerConfig(t, home, config)
+
+	credentials := dockerCredentialsFunc()
+
+	username, secret, err := credentials("registry.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "alice" || secret != "s3cr3t" {
+		t.Errorf("got (%q, %q), want (%q, %q)", username, secret, "alice", "s3cr3t")
+	}
+
+	username, secret, err = credentials("unknown.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "" || secret != "" {
+		t.Errorf("expected no credentials for an unconfigured host, got (%q, %q)", username, secret)
+	}
+}
+
+func TestDockerCredentialsFuncNoConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	credentials := dockerCredentialsFunc()
+	username, secret, err := credentials("registry.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "" || secret != "" {
+		t.Errorf("expected no credentials when no docker config exists, got (%q, %q)", username, secret)
+	}
+}
+
+func writeDockerConfig(t *testing.T, home string, config map[string]interface{}) {
+	t.Helper()
+	dockerDir := filepath.Join(home, ".docker")
+	if err := os.MkdirAll(dockerDir, 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", dockerDir, err)
+	}
+	bytes, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal docker config: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dockerDir, "config.json"), bytes, 0644); err != nil {
+		t.Fatalf("failed to write docker config: %v", err)
+	}
+}
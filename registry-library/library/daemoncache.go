@@ -0,0 +1,371 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheDaemon serves pulled stack content out of cacheDir to any number of local processes over a
+// Unix domain socket, so that an IDE and a CLI running on the same machine don't each pay for the
+// same multi-gigabyte download. Beyond simple disk caching, it also deduplicates concurrent
+// first-time downloads of the same content: a second requester arriving while the first is still
+// pulling blocks until the first either populates the cache or gives up, instead of both hitting
+// the network at once.
+type CacheDaemon struct {
+	cacheDir string
+
+	mu      sync.Mutex
+	pending map[string]chan struct{}
+}
+
+// NewCacheDaemon returns a CacheDaemon that stores cached content under cacheDir, creating it if
+// it doesn't already exist.
+func NewCacheDaemon(cacheDir string) *CacheDaemon {
+	return &CacheDaemon{
+		cacheDir: cacheDir,
+		pending:  make(map[string]chan struct{}),
+	}
+}
+
+// Serve listens on socketPath and handles requests until ctx is done, at which point it stops
+// accepting new connections and returns. A stale socket file left behind by a previous, killed
+// instance is removed first so restarting the daemon doesn't require the caller to clean up.
+func (d *CacheDaemon) Serve(ctx context.Context, socketPath string) error {
+	if _, err := os.Stat(socketPath); err == nil {
+		if err := os.Remove(socketPath); err != nil {
+			return fmt.Errorf("failed to remove stale cache daemon socket %s: %v", socketPath, err)
+		}
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on cache daemon socket %s: %v", socketPath, err)
+	}
+	defer listener.Close()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept cache daemon connection: %v", err)
+		}
+		go d.handleConn(conn)
+	}
+}
+
+// handleConn services one client connection for its lifetime: a GET, followed either by nothing
+// (on a hit) or by a PUT populating the cache for the next requester (on a miss). If the
+// connection is closed after a miss without a matching PUT, the claim on that key is released so
+// it doesn't wedge every other waiter forever.
+func (d *CacheDaemon) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 2 || fields[0] != "GET" {
+		fmt.Fprintf(conn, "ERROR malformed request\n")
+		return
+	}
+	key := fields[1]
+
+	claimed, err := d.handleGet(conn, key)
+	if err != nil {
+		fmt.Fprintf(conn, "ERROR %v\n", err)
+		return
+	}
+	if !claimed {
+		// Cache hit: handleGet already wrote the response.
+		return
+	}
+	defer d.releaseClaim(key)
+
+	line, err = reader.ReadString('\n')
+	if err != nil {
+		// Connection closed (or errored) before a PUT arrived: releaseClaim, above, lets the next
+		// waiter take over rather than waiting on a claim nobody will ever fulfill.
+		return
+	}
+	fields = strings.Fields(line)
+	if len(fields) != 3 || fields[0] != "PUT" || fields[1] != key {
+		fmt.Fprintf(conn, "ERROR malformed request\n")
+		return
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		fmt.Fprintf(conn, "ERROR malformed size\n")
+		return
+	}
+
+	if err := d.handlePut(reader, key, size); err != nil {
+		fmt.Fprintf(conn, "ERROR %v\n", err)
+		return
+	}
+	fmt.Fprintf(conn, "OK\n")
+}
+
+// handleGet writes a HIT response with the cached bytes for key if present, or a MISS response and
+// claims key for the caller's connection otherwise. The returned bool reports whether the caller
+// now owns the claim (true on a miss it just claimed, false on a hit or on a miss that was already
+// claimed and has since been satisfied).
+func (d *CacheDaemon) handleGet(conn net.Conn, key string) (bool, error) {
+	for {
+		data, err := ioutil.ReadFile(d.path(key))
+		if err == nil {
+			fmt.Fprintf(conn, "HIT %d\n", len(data))
+			_, err := conn.Write(data)
+			return false, err
+		}
+
+		d.mu.Lock()
+		wait, isPending := d.pending[key]
+		if !isPending {
+			d.pending[key] = make(chan struct{})
+			d.mu.Unlock()
+			_, err := fmt.Fprintf(conn, "MISS\n")
+			return true, err
+		}
+		d.mu.Unlock()
+
+		// Someone else is already fetching this key: wait for them to finish (successfully or not)
+		// and recheck, rather than both of us hitting the network for the same content.
+		<-wait
+	}
+}
+
+// releaseClaim frees key so the next waiter (if any) retries the cache instead of blocking
+// forever, and unblocks any waiters already parked in handleGet.
+func (d *CacheDaemon) releaseClaim(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if wait, ok := d.pending[key]; ok {
+		close(wait)
+		delete(d.pending, key)
+	}
+}
+
+// handlePut streams size bytes from reader into the cache under key, writing to a temporary file
+// first and renaming it into place so a concurrent handleGet never observes a partially written
+// entry.
+func (d *CacheDaemon) handlePut(reader io.Reader, key string, size int64) error {
+	if err := os.MkdirAll(d.cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %v", d.cacheDir, err)
+	}
+
+	tmp, err := ioutil.TempFile(d.cacheDir, "put-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary cache file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.CopyN(tmp, reader, size); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry %s: %v", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry %s: %v", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		return fmt.Errorf("failed to store cache entry %s: %v", key, err)
+	}
+	return nil
+}
+
+// path returns the on-disk location of the cache entry for key.
+func (d *CacheDaemon) path(key string) string {
+	return filepath.Join(d.cacheDir, key)
+}
+
+// dialCacheDaemon connects to the cache daemon listening on socketPath.
+func dialCacheDaemon(socketPath string) (net.Conn, error) {
+	return net.DialTimeout("unix", socketPath, 5*time.Second)
+}
+
+// cacheDaemonGet asks the cache daemon at socketPath for key. On a hit, it returns the cached
+// bytes and a nil conn. On a miss, it returns a nil error with the connection left open and hit
+// false: the caller now holds the claim on key and must either populate it with cacheDaemonPut or
+// close conn to abandon the claim. Any error dialing or speaking the protocol is returned as-is,
+// so the caller can fall back to pulling directly from the registry.
+func cacheDaemonGet(socketPath string, key string) ([]byte, net.Conn, bool, error) {
+	conn, err := dialCacheDaemon(socketPath)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to connect to cache daemon: %v", err)
+	}
+
+	if _, err := fmt.Fprintf(conn, "GET %s\n", key); err != nil {
+		conn.Close()
+		return nil, nil, false, fmt.Errorf("failed to send request to cache daemon: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, nil, false, fmt.Errorf("failed to read response from cache daemon: %v", err)
+	}
+	fields := strings.Fields(line)
+
+	switch {
+	case len(fields) == 2 && fields[0] == "HIT":
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			conn.Close()
+			return nil, nil, false, fmt.Errorf("cache daemon sent malformed HIT size: %v", err)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			conn.Close()
+			return nil, nil, false, fmt.Errorf("failed to read cache daemon HIT payload: %v", err)
+		}
+		conn.Close()
+		return data, nil, true, nil
+	case len(fields) == 1 && fields[0] == "MISS":
+		return nil, conn, false, nil
+	default:
+		conn.Close()
+		return nil, nil, false, fmt.Errorf("cache daemon sent unexpected response %q", line)
+	}
+}
+
+// cacheDaemonPut sends data as the PUT completing the claim held by conn (as returned by a miss
+// from cacheDaemonGet), and always closes conn.
+func cacheDaemonPut(conn net.Conn, key string, data []byte) error {
+	defer conn.Close()
+
+	if _, err := fmt.Fprintf(conn, "PUT %s %d\n", key, len(data)); err != nil {
+		return fmt.Errorf("failed to send PUT to cache daemon: %v", err)
+	}
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send PUT payload to cache daemon: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read PUT response from cache daemon: %v", err)
+	}
+	if !strings.HasPrefix(line, "OK") {
+		return fmt.Errorf("cache daemon rejected PUT: %s", strings.TrimSpace(line))
+	}
+	return nil
+}
+
+// cacheKeyForPull returns the cache key for a pull of ref restricted to allowedMediaTypes. It is
+// keyed on the exact request rather than a resolved content digest, since the goal is deduplicating
+// repeated or concurrent identical pulls by local consumers, not the digest-addressed caching
+// WarmCache already provides.
+func cacheKeyForPull(ref string, allowedMediaTypes []string) string {
+	sum := sha256.Sum256([]byte(ref + "\x00" + strings.Join(allowedMediaTypes, ",")))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// archiveDirToBytes tars and gzips the contents of dir into memory, so a pulled stack directory can
+// be handed to the cache daemon as a single PUT payload.
+func archiveDirToBytes(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = relPath
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// extractBytesToDir writes data (as produced by archiveDirToBytes) into destDir, reusing decompress
+// for the actual untar/gunzip so this doesn't duplicate that logic.
+func extractBytesToDir(data []byte, destDir string, umask os.FileMode) error {
+	tmp, err := ioutil.TempFile("", "cache-extract-*.tar.gz")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for cache extraction: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file for cache extraction: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file for cache extraction: %v", err)
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", destDir, err)
+	}
+	return decompress(destDir, tmp.Name(), nil, umask)
+}
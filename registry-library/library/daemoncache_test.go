@@ -0,0 +1,173 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func startTestCacheDaemon(t *testing.T) (socketPath string, stop func()) {
+	t.Helper()
+
+	cacheDir, err := ioutil.TempDir("", "cache-daemon-dir")
+	if err != nil {
+		t.Fatalf("failed to create cache dir: %v", err)
+	}
+	socketDir, err := ioutil.TempDir("", "cache-daemon-socket")
+	if err != nil {
+		t.Fatalf("failed to create socket dir: %v", err)
+	}
+	socketPath = filepath.Join(socketDir, "cache.sock")
+
+	daemon := NewCacheDaemon(cacheDir)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- daemon.Serve(ctx, socketPath) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(socketPath); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return socketPath, func() {
+		cancel()
+		os.RemoveAll(cacheDir)
+		os.RemoveAll(socketDir)
+	}
+}
+
+func TestCacheDaemonGetMissThenPutThenHit(t *testing.T) {
+	socketPath, stop := startTestCacheDaemon(t)
+	defer stop()
+
+	key := "some-key"
+	data := []byte("hello cache")
+
+	_, conn, hit, err := cacheDaemonGet(socketPath, key)
+	if err != nil {
+		t.Fatalf("unexpected error on first GET: %v", err)
+	}
+	if hit {
+		t.Fatalf("expected a miss for an unpopulated key")
+	}
+
+	if err := cacheDaemonPut(conn, key, data); err != nil {
+		t.Fatalf("unexpected error on PUT: %v", err)
+	}
+
+	got, _, hit, err := cacheDaemonGet(socketPath, key)
+	if err != nil {
+		t.Fatalf("unexpected error on second GET: %v", err)
+	}
+	if !hit {
+		t.Fatalf("expected a hit after PUT")
+	}
+	if string(got) != string(data) {
+		t.Errorf("expected %q, got %q", data, got)
+	}
+}
+
+func TestCacheDaemonAbandonedClaimIsReleased(t *testing.T) {
+	socketPath, stop := startTestCacheDaemon(t)
+	defer stop()
+
+	key := "abandoned-key"
+
+	_, conn, hit, err := cacheDaemonGet(socketPath, key)
+	if err != nil {
+		t.Fatalf("unexpected error on first GET: %v", err)
+	}
+	if hit {
+		t.Fatalf("expected a miss for an unpopulated key")
+	}
+	conn.Close() // abandon the claim without a PUT
+
+	waitErr := make(chan error, 1)
+	go func() {
+		_, waitConn, hit, err := cacheDaemonGet(socketPath, key)
+		if err == nil {
+			if hit {
+				waitErr <- nil
+				return
+			}
+			waitConn.Close()
+		}
+		waitErr <- err
+	}()
+
+	select {
+	case err := <-waitErr:
+		if err != nil {
+			t.Fatalf("expected the released claim to be retryable, got error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for abandoned claim to be released")
+	}
+}
+
+func TestArchiveDirToBytesRoundTrip(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "archive-roundtrip-source")
+	if err != nil {
+		t.Fatalf("failed to create source dir: %v", err)
+	}
+	defer os.RemoveAll(sourceDir)
+
+	if err := os.MkdirAll(filepath.Join(sourceDir, "nested"), 0755); err != nil {
+		t.Fatalf("failed to create nested dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(sourceDir, "devfile.yaml"), []byte("schemaVersion: 2.0.0"), 0644); err != nil {
+		t.Fatalf("failed to write devfile: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(sourceDir, "nested", "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write nested file: %v", err)
+	}
+
+	archived, err := archiveDirToBytes(sourceDir)
+	if err != nil {
+		t.Fatalf("unexpected error archiving: %v", err)
+	}
+
+	destDir, err := ioutil.TempDir("", "archive-roundtrip-dest")
+	if err != nil {
+		t.Fatalf("failed to create dest dir: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+
+	if err := extractBytesToDir(archived, destDir, 0); err != nil {
+		t.Fatalf("unexpected error extracting: %v", err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(destDir, "devfile.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read extracted devfile: %v", err)
+	}
+	if string(got) != "schemaVersion: 2.0.0" {
+		t.Errorf("unexpected devfile content: %q", got)
+	}
+
+	got, err = ioutil.ReadFile(filepath.Join(destDir, "nested", "file.txt"))
+	if err != nil {
+		t.Fatalf("failed to read extracted nested file: %v", err)
+	}
+	if string(got) != "content" {
+		t.Errorf("unexpected nested file content: %q", got)
+	}
+}
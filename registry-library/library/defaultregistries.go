@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+// globalOdoConfigEnv overrides the path to odo's preference.yaml, mirroring odo's own
+// GLOBALODOCONFIG environment variable so this library resolves the same file odo would.
+const globalOdoConfigEnv = "GLOBALODOCONFIG"
+
+// odoPreference mirrors the subset of odo's preference.yaml that this library needs: the list
+// of devfile registries a developer has configured with `odo registry add`.
+type odoPreference struct {
+	RegistryList []odoRegistryListItem `yaml:"registryList"`
+}
+
+type odoRegistryListItem struct {
+	Name   string `yaml:"name"`
+	URL    string `yaml:"url"`
+	Secure bool   `yaml:"secure"`
+}
+
+// LoadDefaultRegistries reads the registry list from odo's preference.yaml, so tools built on
+// this library present the same configured registries a developer already sees in odo instead
+// of maintaining a separate config schema. The file location is resolved the same way odo
+// resolves it: $GLOBALODOCONFIG if set, otherwise ~/.config/odo/preference.yaml. A missing file
+// is not an error: it just means no registry has been configured yet.
+func LoadDefaultRegistries() ([]string, error) {
+	configPath, err := odoPreferencePath()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var preference odoPreference
+	if err := yaml.Unmarshal(contents, &preference); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", configPath, err)
+	}
+
+	registries := make([]string, 0, len(preference.RegistryList))
+	for _, entry := range preference.RegistryList {
+		if entry.URL != "" {
+			registries = append(registries, entry.URL)
+		}
+	}
+	return registries, nil
+}
+
+// odoPreferencePath resolves the path to odo's preference.yaml.
+func odoPreferencePath() (string, error) {
+	if path := os.Getenv(globalOdoConfigEnv); path != "" {
+		return path, nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "odo", "preference.yaml"), nil
+}
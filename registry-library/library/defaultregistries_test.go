@@ -0,0 +1,70 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+func TestLoadDefaultRegistriesFromConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "preference.yaml")
+	preference := `registryList:
+  - name: DefaultDevfileRegistry
+    url: https://registry.stage.devfile.io
+    secure: false
+  - name: MyRegistry
+    url: https://my.registry.example.com
+    secure: true
+`
+	if err := ioutil.WriteFile(configPath, []byte(preference), 0644); err != nil {
+		t.Fatalf("failed to write preference.yaml: %v", err)
+	}
+	t.Setenv(globalOdoConfigEnv, configPath)
+
+	registries, err := LoadDefaultRegistries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"https://registry.stage.devfile.io", "https://my.registry.example.com"}
+	if len(registries) != len(want) {
+		t.Fatalf("got %v, want %v", registries, want)
+	}
+	for i := range want {
+		if registries[i] != want[i] {
+			t.Errorf("got %v, want %v", registries, want)
+		}
+	}
+}
+
+func TestLoadDefaultRegistriesNoConfig(t *testing.T) {
+	t.Setenv(globalOdoConfigEnv, filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+
+	registries, err := LoadDefaultRegistries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(registries) != 0 {
+		t.Errorf("expected no registries, got %v", registries)
+	}
+}
+
+func TestOdoPreferencePathDefault(t *testing.T) {
+	os.Unsetenv(globalOdoConfigEnv)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	homedir.Reset()
+	t.Cleanup(homedir.Reset)
+
+	path, err := odoPreferencePath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := filepath.Join(home, ".config", "odo", "preference.yaml")
+	if path != want {
+		t.Errorf("got %q, want %q", path, want)
+	}
+}
@@ -0,0 +1,321 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/devfile/registry-support/index/generator/gitutil"
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// starterProjectDevfile is the subset of a devfile.yaml needed to resolve a starter project's
+// download location. The full devfile schema (devfile/api) isn't vendored here, so this only
+// captures the starterProjects section rather than parsing the devfile in full.
+type starterProjectDevfile struct {
+	StarterProjects []starterProjectSource `yaml:"starterProjects"`
+}
+
+type starterProjectSource struct {
+	Name   string     `yaml:"name"`
+	SubDir string     `yaml:"subDir"`
+	Git    *gitSource `yaml:"git"`
+	Zip    *zipSource `yaml:"zip"`
+}
+
+type gitSource struct {
+	Remotes      map[string]string `yaml:"remotes"`
+	CheckoutFrom *checkoutFrom     `yaml:"checkoutFrom"`
+}
+
+type checkoutFrom struct {
+	Remote   string `yaml:"remote"`
+	Revision string `yaml:"revision"`
+}
+
+type zipSource struct {
+	Location string `yaml:"location"`
+}
+
+// InitProject scaffolds a new project directory from a stack's starter project: it pulls the
+// stack's devfile.yaml, resolves the named starterProject's source, downloads it, and lays it out
+// in destDir the same way odo does, so every consumer of this library behaves identically instead
+// of reimplementing the flow. version may be empty to use the stack's default version.
+func InitProject(registry string, stack string, version string, starterProject string, destDir string, options RegistryOptions) error {
+	stackIndex, err := findStackVersionInRegistry(registry, stack, version, options)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, name := range stackIndex.StarterProjects {
+		if name == starterProject {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("starter project %s does not exist in stack %s", starterProject, stack)
+	}
+
+	devfileDir, err := ioutil.TempDir("", "devfile-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(devfileDir)
+
+	resolver, urlObj, err := newResolver(registry, options)
+	if err != nil {
+		return err
+	}
+	ref := path.Join(urlObj.Host, stackIndex.Links["self"])
+	if err := pullRef(resolver, ref, registry, stack, devfileDir, DevfileMediaTypeList, options); err != nil {
+		return err
+	}
+
+	devfileBytes, err := ioutil.ReadFile(filepath.Join(devfileDir, "devfile.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to read devfile.yaml for stack %s: %v", stack, err)
+	}
+	var devfile starterProjectDevfile
+	if err := yaml.Unmarshal(devfileBytes, &devfile); err != nil {
+		return fmt.Errorf("failed to parse devfile.yaml for stack %s: %v", stack, err)
+	}
+
+	var project *starterProjectSource
+	for i := range devfile.StarterProjects {
+		if devfile.StarterProjects[i].Name == starterProject {
+			project = &devfile.StarterProjects[i]
+			break
+		}
+	}
+	if project == nil {
+		return fmt.Errorf("starter project %s is listed in the index but missing from stack %s's devfile.yaml", starterProject, stack)
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	switch {
+	case project.Git != nil:
+		return downloadGitStarterProject(project.Git, project.SubDir, destDir)
+	case project.Zip != nil:
+		return downloadZipStarterProject(project.Zip, project.SubDir, destDir)
+	default:
+		return fmt.Errorf("starter project %s has neither a git nor a zip source", starterProject)
+	}
+}
+
+// findStackVersionInRegistry returns the index metadata for a specific version of a stack. An
+// empty version falls back to the existing default-version lookup; a non-empty version is fetched
+// directly from the /v2index/:stack/:version endpoint, since GetRegistryIndex's /index endpoint
+// only ever carries a stack's default version.
+func findStackVersionInRegistry(registry string, stack string, version string, options RegistryOptions) (indexSchema.Schema, error) {
+	if version == "" {
+		return findStackInRegistry(registry, stack, options)
+	}
+
+	urlObj, err := url.Parse(registry)
+	if err != nil {
+		return indexSchema.Schema{}, err
+	}
+	endpointURL, err := url.Parse(path.Join("v2index", stack, version))
+	if err != nil {
+		return indexSchema.Schema{}, err
+	}
+	urlObj = urlObj.ResolveReference(endpointURL)
+
+	req, err := http.NewRequest("GET", urlObj.String(), nil)
+	if err != nil {
+		return indexSchema.Schema{}, err
+	}
+	setHeaders(&req.Header, options)
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			ResponseHeaderTimeout: responseHeaderTimeout,
+			TLSClientConfig:       buildTLSConfig(options),
+		},
+		Timeout: httpRequestTimeout,
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return indexSchema.Schema{}, err
+	}
+	defer resp.Body.Close()
+
+	bytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return indexSchema.Schema{}, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		// Older registries don't serve /v2index at all; fall back to the legacy /index lookup,
+		// which only ever returns a stack's default version, instead of erroring out.
+		return findStackInRegistry(registry, stack, options)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return indexSchema.Schema{}, fmt.Errorf("failed to get version %s of stack %s from %s: %s", version, stack, registry, string(bytes))
+	}
+
+	var entry indexSchema.Schema
+	if err := json.Unmarshal(bytes, &entry); err != nil {
+		return indexSchema.Schema{}, err
+	}
+	if len(entry.Versions) == 1 {
+		entry.Links = entry.Versions[0].Links
+		entry.Resources = entry.Versions[0].Resources
+		entry.StarterProjects = entry.Versions[0].StarterProjects
+		entry.Version = entry.Versions[0].Version
+	}
+	return entry, nil
+}
+
+// downloadGitStarterProject clones a starter project's git source directly into destDir, checks
+// out the requested revision if any, and strips .git so the result isn't mistaken for a checkout
+// of the starter project's own upstream repository.
+func downloadGitStarterProject(git *gitSource, subDir string, destDir string) error {
+	remoteURL := ""
+	if git.CheckoutFrom != nil && git.CheckoutFrom.Remote != "" {
+		remoteURL = git.Remotes[git.CheckoutFrom.Remote]
+	}
+	if remoteURL == "" {
+		for _, candidate := range git.Remotes {
+			remoteURL = candidate
+			break
+		}
+	}
+	if remoteURL == "" {
+		return fmt.Errorf("starter project git source has no remotes defined")
+	}
+
+	cloneDir := destDir
+	if subDir != "" {
+		tmpDir, err := ioutil.TempDir("", "starter-project-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpDir)
+		cloneDir = tmpDir
+	}
+
+	revision := ""
+	if git.CheckoutFrom != nil {
+		revision = git.CheckoutFrom.Revision
+	}
+	if err := gitutil.CloneRevision(context.Background(), remoteURL, revision, cloneDir, gitutil.ResolveAuth(remoteURL)); err != nil {
+		return fmt.Errorf("failed to download starter project: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(cloneDir, ".git")); err != nil {
+		return err
+	}
+
+	if subDir != "" {
+		return gitutil.CopyDir(filepath.Join(cloneDir, subDir), destDir)
+	}
+	return nil
+}
+
+// downloadZipStarterProject downloads a starter project's zip source and extracts it into destDir.
+func downloadZipStarterProject(zipSrc *zipSource, subDir string, destDir string) error {
+	resp, err := http.Get(zipSrc.Location)
+	if err != nil {
+		return fmt.Errorf("failed to download starter project zip from %s: %v", zipSrc.Location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download starter project zip from %s: %s", zipSrc.Location, resp.Status)
+	}
+
+	zipFile, err := ioutil.TempFile("", "starter-project-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(zipFile.Name())
+	if _, err := io.Copy(zipFile, resp.Body); err != nil {
+		zipFile.Close()
+		return err
+	}
+	zipFile.Close()
+
+	extractDir := destDir
+	if subDir != "" {
+		tmpDir, err := ioutil.TempDir("", "starter-project-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpDir)
+		extractDir = tmpDir
+	}
+
+	if err := unzip(zipFile.Name(), extractDir); err != nil {
+		return err
+	}
+	if subDir != "" {
+		return gitutil.CopyDir(filepath.Join(extractDir, subDir), destDir)
+	}
+	return nil
+}
+
+// unzip extracts the zip archive at zipPath into destDir.
+func unzip(zipPath string, destDir string) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		target := filepath.Join(destDir, f.Name)
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, f.Mode()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := extractZipFile(f, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, target string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode())
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,154 @@
+package library
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestFindStackVersionInRegistry(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2index/nodejs/2.0.0" {
+			t.Errorf("Expected path /v2index/nodejs/2.0.0, got %s", r.URL.Path)
+		}
+		entry := indexSchema.Schema{
+			Name: "nodejs",
+			Versions: []indexSchema.Version{
+				{
+					Version:         "2.0.0",
+					Links:           map[string]string{"self": "devfile-catalog/nodejs:2.0.0"},
+					StarterProjects: []string{"nodejs-starter"},
+				},
+			},
+		}
+		bytes, err := json.Marshal(&entry)
+		if err != nil {
+			t.Fatalf("Unexpected error while doing json marshal: %v", err)
+		}
+		w.Write(bytes)
+	}))
+	defer testServer.Close()
+
+	stackIndex, err := findStackVersionInRegistry(testServer.URL, "nodejs", "2.0.0", RegistryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stackIndex.Version != "2.0.0" {
+		t.Errorf("Expected version 2.0.0, got %s", stackIndex.Version)
+	}
+	if stackIndex.Links["self"] != "devfile-catalog/nodejs:2.0.0" {
+		t.Errorf("Expected links[self] devfile-catalog/nodejs:2.0.0, got %s", stackIndex.Links["self"])
+	}
+	if len(stackIndex.StarterProjects) != 1 || stackIndex.StarterProjects[0] != "nodejs-starter" {
+		t.Errorf("Expected starterProjects [nodejs-starter], got %v", stackIndex.StarterProjects)
+	}
+}
+
+func TestFindStackVersionInRegistryFallsBackWhenV2IndexMissing(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2index/nodejs/2.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path != "/index" {
+			t.Errorf("Expected fallback path /index, got %s", r.URL.Path)
+		}
+		entries := []indexSchema.Schema{
+			{
+				Name:    "nodejs",
+				Version: "1.0.0",
+				Links:   map[string]string{"self": "devfile-catalog/nodejs:1.0.0"},
+			},
+		}
+		bytes, err := json.Marshal(&entries)
+		if err != nil {
+			t.Fatalf("Unexpected error while doing json marshal: %v", err)
+		}
+		w.Write(bytes)
+	}))
+	defer testServer.Close()
+
+	stackIndex, err := findStackVersionInRegistry(testServer.URL, "nodejs", "2.0.0", RegistryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stackIndex.Version != "1.0.0" {
+		t.Errorf("Expected fallback to the default version 1.0.0, got %s", stackIndex.Version)
+	}
+}
+
+func TestGetRegistryCapabilities(t *testing.T) {
+	tests := []struct {
+		name                     string
+		v2IndexStatus            int
+		wantSupportsVersionIndex bool
+	}{
+		{"v2index supported", http.StatusOK, true},
+		{"v2index not supported", http.StatusNotFound, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.v2IndexStatus)
+			}))
+			defer testServer.Close()
+
+			got := GetRegistryCapabilities(testServer.URL, RegistryOptions{})
+			if got.SupportsVersionIndex != test.wantSupportsVersionIndex {
+				t.Errorf("got SupportsVersionIndex %v, want %v", got.SupportsVersionIndex, test.wantSupportsVersionIndex)
+			}
+		})
+	}
+}
+
+func TestDownloadGitStarterProject(t *testing.T) {
+	remoteDir, err := ioutil.TempDir("", "starter-project-remote")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(remoteDir)
+
+	if err := ioutil.WriteFile(filepath.Join(remoteDir, "index.js"), []byte("console.log('hi')"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	runGit(t, remoteDir, "init", "--quiet")
+	runGit(t, remoteDir, "config", "user.email", "test@example.com")
+	runGit(t, remoteDir, "config", "user.name", "test")
+	runGit(t, remoteDir, "add", "index.js")
+	runGit(t, remoteDir, "commit", "--quiet", "-m", "initial commit")
+
+	destDir, err := ioutil.TempDir("", "starter-project-dest")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+
+	git := &gitSource{Remotes: map[string]string{"origin": remoteDir}}
+	if err := downloadGitStarterProject(git, "", destDir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(destDir, "index.js")); err != nil {
+		t.Errorf("Expected index.js to be checked out into destDir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, ".git")); !os.IsNotExist(err) {
+		t.Errorf("Expected .git to be stripped from destDir, got err: %v", err)
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, out)
+	}
+}
@@ -14,7 +14,10 @@ package library
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -26,14 +29,17 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	orasctx "oras.land/oras-go/pkg/context"
 
+	"github.com/containerd/containerd/remotes"
 	"github.com/containerd/containerd/remotes/docker"
 	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/pkg/content"
 	"oras.land/oras-go/pkg/oras"
 )
@@ -48,6 +54,10 @@ const (
 
 	httpRequestTimeout    = 30 * time.Second // httpRequestTimeout configures timeout of all HTTP requests
 	responseHeaderTimeout = 30 * time.Second // responseHeaderTimeout is the timeout to retrieve the server's response headers
+
+	// libraryVersion is this package's own version, composed into the default User-Agent sent
+	// with every request when a caller does not set RegistryOptions.ProductName.
+	libraryVersion = "1.0.0"
 )
 
 var (
@@ -61,7 +71,7 @@ type Registry struct {
 	err              error
 }
 
-//TelemetryData structure to pass in client telemetry information
+// TelemetryData structure to pass in client telemetry information
 // The User and Locale fields should be passed in by clients if telemetry opt-in is enabled
 // the generic Client name will be passed in regardless of opt-in/out choice.  The value
 // will be assigned to the UserId field for opt-outs
@@ -78,18 +88,211 @@ type RegistryOptions struct {
 	// SkipTLSVerify is false by default which is the recommended setting for a devfile registry deployed in production.  SkipTLSVerify should only be set to true
 	// if you are testing a devfile registry that is set up with self-signed certificates in a pre-production environment.
 	SkipTLSVerify bool
+	// UseDockerCredentials enables resolving registry credentials the same way the docker CLI
+	// does: via the docker config's per-registry credential helpers (e.g. docker-credential-ecr-login,
+	// docker-credential-gcr, docker-credential-acr-*), falling back to its default credential
+	// store, falling back to the inline auth entries `docker login` writes to config.json. This
+	// lets users already authenticated with their container tooling pull from private registries
+	// without configuring separate credentials for this library.
+	UseDockerCredentials bool
 	// Telemetry allows clients to send telemetry data to the community Devfile Registry
 	Telemetry TelemetryData
 	// Filter allows clients to specify which architectures they want to filter their devfiles on
 	Filter RegistryFilter
+	// MaxPullRetries is the number of additional attempts made to pull a stack if the initial
+	// attempt is interrupted (e.g. a dropped connection). The destination directory is left
+	// intact between attempts so that content already written to disk does not need to be
+	// re-fetched. A value of 0 (the default) disables retries.
+	MaxPullRetries int
+	// MaxDownloadBytesPerSecond caps how fast stack resources are downloaded, so a background
+	// catalog sync doesn't saturate a developer's connection. A value of 0 (the default) leaves
+	// downloads unthrottled.
+	MaxDownloadBytesPerSecond int64
+	// Metrics allows clients to observe pull latency, bytes downloaded, cache hits, and retries so
+	// they can surface registry performance in their own dashboards. Every field is optional; unset
+	// hooks are simply not called.
+	Metrics MetricsHooks
+	// PinnedSPKISHA256, if set, requires the registry's TLS certificate to carry this exact
+	// base64-encoded SHA-256 hash of its Subject Public Key Info. This is checked in addition to,
+	// not instead of, normal certificate chain verification, so it defends highly regulated
+	// consumers against a compromised corporate middlebox presenting an otherwise-trusted
+	// certificate for the registry's hostname.
+	PinnedSPKISHA256 string
+	// ResourceFilter, if non-empty, limits archive.tar extraction to only the listed paths (e.g.
+	// []string{"devfile.yaml", "kubernetes/deploy.yaml"}), so a consumer that only needs a few
+	// files out of a stack doesn't pay to write the rest to disk. Filtering happens while
+	// streaming the tar entries, before any non-matching entry is written. An empty filter (the
+	// default) extracts every entry, matching the prior unconditional behavior.
+	ResourceFilter []string
+	// Transport, if set, is used as the base http.RoundTripper for every index and OCI call this
+	// library makes, instead of the *http.Transport it builds and hides behind its own clients.
+	// This lets a consumer inject a custom RoundTripper, or a middleware chain of them, for
+	// corporate auth injection, request logging, or chaos testing. When Transport is set,
+	// SkipTLSVerify and PinnedSPKISHA256 are not applied on this library's behalf; the supplied
+	// RoundTripper is responsible for its own TLS configuration. MaxDownloadBytesPerSecond
+	// throttling is still layered on top of Transport during OCI pulls.
+	Transport http.RoundTripper
+	// ProductName and ProductVersion, if set, are composed into the User-Agent header sent with
+	// every index and OCI call this library makes, as "<ProductName>/<ProductVersion>" (or just
+	// "<ProductName>" if ProductVersion is empty), so server-side analytics can attribute traffic
+	// to the consuming product instead of lumping it in with every other user of this library.
+	// When ProductName is empty, the User-Agent defaults to "registry-library/<libraryVersion>".
+	ProductName    string
+	ProductVersion string
+	// CacheDaemonSocket, if set, is the path to a CacheDaemon's Unix socket. When set, pulls are
+	// served through the daemon instead of going directly to the registry: a cache hit skips the
+	// network entirely, and a miss is fetched normally and then handed to the daemon so other local
+	// consumers (e.g. an IDE and a CLI on the same machine) don't repeat the same download. If the
+	// daemon is unreachable, pulls fall back to going directly to the registry rather than failing.
+	CacheDaemonSocket string
+	// MaxSchemaVersion, if set, rejects a pulled stack whose devfile.yaml declares a newer
+	// schemaVersion than this (e.g. "2.1.0"), returning an *ErrSchemaVersionTooNew instead of
+	// leaving the stack's files in destDir. This library has no knowledge of which devfile fields
+	// changed between schema versions, so it can't downgrade a document itself; it only protects a
+	// caller pinned to an older devfile spec from silently receiving a document its own tooling
+	// can't parse.
+	MaxSchemaVersion string
+	// ExtractionUmask, if non-zero, is applied (mode &^ umask) to every file and directory mode
+	// recorded in an extracted archive.tar entry, since archives built on different CI systems
+	// land with inconsistent permissions (and no uid/gid, which this library already never
+	// applies from the tar header) that can break a container build COPYing the extracted stack.
+	// Left unset (0), extraction preserves each entry's mode exactly as archived, matching prior
+	// behavior.
+	ExtractionUmask os.FileMode
+}
+
+// userAgent returns the User-Agent header value this library sends, composed from options'
+// ProductName/ProductVersion when set, and falling back to identifying this library itself
+// otherwise.
+func userAgent(options RegistryOptions) string {
+	if options.ProductName == "" {
+		return "registry-library/" + libraryVersion
+	}
+	if options.ProductVersion == "" {
+		return options.ProductName
+	}
+	return options.ProductName + "/" + options.ProductVersion
+}
+
+// baseTransport returns the http.RoundTripper that every HTTP client in this package builds on:
+// options.Transport, if the caller supplied one, so it is used for every index and OCI call
+// instead of a transport this library builds and hides behind its own clients; otherwise a
+// *http.Transport configured from options' TLS settings. Proxy is always set to
+// http.ProxyFromEnvironment so both index fetches (NewClient) and OCI pulls (newResolver) honor
+// HTTP_PROXY/HTTPS_PROXY/NO_PROXY the same way a bare http.Transport{} would not: an explicitly
+// constructed *http.Transport defaults Proxy to nil, unlike http.DefaultTransport, so without this
+// every request made by this library would silently bypass a configured proxy. http.ProxyFromEnvironment
+// already handles NO_PROXY exact hosts and domain suffixes; it does not support CIDR ranges in
+// NO_PROXY, and this module doesn't vendor a proxy-config library that does, so CIDR-based NO_PROXY
+// entries are not honored here.
+func baseTransport(options RegistryOptions, responseHeaderTimeout time.Duration) http.RoundTripper {
+	if options.Transport != nil {
+		return options.Transport
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		TLSClientConfig:       buildTLSConfig(options),
+	}
+}
+
+// buildTLSConfig builds the *tls.Config every HTTP client in this package should use, so
+// SkipTLSVerify and PinnedSPKISHA256 are honored consistently everywhere a registry is contacted.
+func buildTLSConfig(options RegistryOptions) *tls.Config {
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.SkipTLSVerify}
+	if options.PinnedSPKISHA256 != "" {
+		tlsConfig.VerifyPeerCertificate = verifySPKIPin(options.PinnedSPKISHA256)
+	}
+	return tlsConfig
+}
+
+// verifySPKIPin returns a tls.Config.VerifyPeerCertificate callback that fails the handshake
+// unless one of the presented certificates' Subject Public Key Info hashes to pin.
+func verifySPKIPin(pin string) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		for _, rawCert := range rawCerts {
+			cert, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				continue
+			}
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			if base64.StdEncoding.EncodeToString(sum[:]) == pin {
+				return nil
+			}
+		}
+		return fmt.Errorf("registry TLS certificate does not match pinned SPKI hash %s", pin)
+	}
+}
+
+// MetricsHooks are optional callbacks invoked by the registry-library client as it performs
+// registry operations. Consuming products can wire these into their own metrics backend
+// (e.g. an OpenTelemetry meter or Prometheus client) without the library taking a hard
+// dependency on any particular metrics stack.
+type MetricsHooks struct {
+	// OnPullLatency is called with the time it took to pull a stack from a registry
+	OnPullLatency func(registry, stack string, latency time.Duration)
+	// OnBytesDownloaded is called with the number of bytes downloaded while pulling a stack
+	OnBytesDownloaded func(registry, stack string, bytes int64)
+	// OnCacheHit is called when a request to a registry is served from a local cache instead of the network
+	OnCacheHit func(registry string)
+	// OnRetry is called each time an operation is retried after a failed attempt
+	OnRetry func(registry string, attempt int)
 }
 
 type RegistryFilter struct {
+	// Architectures restricts results to stacks/versions that declare support for at least one
+	// of these architectures. Takes precedence over AutoDetectPlatform.
 	Architectures []string
+	// AutoDetectPlatform, when true and Architectures is empty, filters to the architecture this
+	// client is running on (runtime.GOARCH), so e.g. arm64 users don't get amd64-only stacks by
+	// default. Set Architectures explicitly to override auto-detection.
+	AutoDetectPlatform bool
+}
+
+// effectiveArchitectures resolves the architecture filter a request should actually use: an
+// explicit filter always wins, otherwise it falls back to the running platform's architecture
+// when AutoDetectPlatform is set, otherwise no filtering is applied.
+func (f RegistryFilter) effectiveArchitectures() []string {
+	if len(f.Architectures) > 0 {
+		return f.Architectures
+	}
+	if f.AutoDetectPlatform {
+		return []string{runtime.GOARCH}
+	}
+	return nil
+}
+
+// Client fetches devfile registry indexes over HTTP. A *Client is safe for concurrent use by
+// multiple goroutines: it holds no mutable per-call state, and its underlying *http.Client (which
+// is itself documented safe for concurrent use) is built once in NewClient and reused across
+// calls, so concurrent callers share connections instead of each paying a fresh TLS handshake.
+// Construct one Client per RegistryOptions and reuse it, rather than building an ad hoc client
+// for every call.
+type Client struct {
+	httpClient *http.Client
+	options    RegistryOptions
+}
+
+// NewClient builds a Client configured with options. The returned Client is safe for concurrent
+// use; construct it once and share it across goroutines that need the same RegistryOptions.
+func NewClient(options RegistryOptions) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: baseTransport(options, responseHeaderTimeout),
+			Timeout:   httpRequestTimeout,
+		},
+		options: options,
+	}
 }
 
 // GetRegistryIndex returns the list of index schema structured stacks and/or samples from a specified devfile registry.
 func GetRegistryIndex(registryURL string, options RegistryOptions, devfileTypes ...indexSchema.DevfileType) ([]indexSchema.Schema, error) {
+	return NewClient(options).GetRegistryIndex(registryURL, devfileTypes...)
+}
+
+// GetRegistryIndex returns the list of index schema structured stacks and/or samples from a specified devfile registry.
+func (c *Client) GetRegistryIndex(registryURL string, devfileTypes ...indexSchema.DevfileType) ([]indexSchema.Schema, error) {
+	options := c.options
 	var registryIndex []indexSchema.Schema
 
 	// Call index server REST API to get the index
@@ -118,12 +321,17 @@ func GetRegistryIndex(registryURL string, options RegistryOptions, devfileTypes
 		return registryIndex, nil
 	}
 
+	archs := options.Filter.effectiveArchitectures()
+
 	if !reflect.DeepEqual(options.Filter, RegistryFilter{}) {
 		endpoint = endpoint + "?"
 	}
 
-	if len(options.Filter.Architectures) > 0 {
-		for _, arch := range options.Filter.Architectures {
+	if len(archs) > 0 {
+		if err := indexSchema.ValidateArchitectures(archs); err != nil {
+			return nil, err
+		}
+		for _, arch := range archs {
 			endpoint = endpoint + "arch=" + arch + "&"
 		}
 		endpoint = strings.TrimSuffix(endpoint, "&")
@@ -144,14 +352,7 @@ func GetRegistryIndex(registryURL string, options RegistryOptions, devfileTypes
 
 	setHeaders(&req.Header, options)
 
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			ResponseHeaderTimeout: responseHeaderTimeout,
-			TLSClientConfig:       &tls.Config{InsecureSkipVerify: options.SkipTLSVerify},
-		},
-		Timeout: httpRequestTimeout,
-	}
-	resp, err := httpClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -166,6 +367,65 @@ func GetRegistryIndex(registryURL string, options RegistryOptions, devfileTypes
 	return registryIndex, nil
 }
 
+// GetIndexFromOCI returns the list of index schema structured stacks and/or samples by pulling the
+// index artifact directly from the OCI registry, at the same "index:latest" ref that
+// index/generator's PushIndexedArtifacts pushes it to. This lets a client reach a headless
+// registry consisting of only an OCI registry, with no HTTP index server in front of it.
+func GetIndexFromOCI(registry string, options RegistryOptions) ([]indexSchema.Schema, error) {
+	resolver, urlObj, err := newResolver(registry, options)
+	if err != nil {
+		return nil, err
+	}
+	ref := path.Join(urlObj.Host, "index") + ":latest"
+
+	ctx := orasctx.Background()
+	_, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve index artifact from %s: %v", ref, err)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index artifact from %s: %v", ref, err)
+	}
+
+	manifestReader, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index artifact manifest from %s: %v", ref, err)
+	}
+	defer manifestReader.Close()
+
+	manifestBytes, err := ioutil.ReadAll(manifestReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index artifact manifest from %s: %v", ref, err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index artifact manifest from %s: %v", ref, err)
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, fmt.Errorf("index artifact at %s has no layers", ref)
+	}
+
+	layerReader, err := fetcher.Fetch(ctx, manifest.Layers[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index artifact content from %s: %v", ref, err)
+	}
+	defer layerReader.Close()
+
+	indexBytes, err := ioutil.ReadAll(layerReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index artifact content from %s: %v", ref, err)
+	}
+
+	var registryIndex []indexSchema.Schema
+	if err := json.Unmarshal(indexBytes, &registryIndex); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index artifact content from %s: %v", ref, err)
+	}
+	return registryIndex, nil
+}
+
 // GetMultipleRegistryIndices returns the list of stacks and/or samples from multiple registries
 func GetMultipleRegistryIndices(registryURLs []string, options RegistryOptions, devfileTypes ...indexSchema.DevfileType) []Registry {
 	registryList := make([]Registry, len(registryURLs))
@@ -185,8 +445,17 @@ func GetMultipleRegistryIndices(registryURLs []string, options RegistryOptions,
 	return registryList
 }
 
-// PrintRegistry prints the registry with devfile type
-func PrintRegistry(registryURLs string, devfileType string, options RegistryOptions) error {
+// PrintRegistry prints the registry with devfile type. If showDigests is true, an additional
+// Digest column is printed for stacks, resolved from the registry; samples have no OCI digest
+// and print "NONE" in that column.
+func PrintRegistry(registryURLs string, devfileType string, options RegistryOptions, showDigests ...bool) error {
+	showDigest := len(showDigests) > 0 && showDigests[0]
+	return PrintRegistryWithFormat(registryURLs, devfileType, options, showDigest, TableOutputFormat)
+}
+
+// PrintRegistryWithFormat is PrintRegistry with an explicit output format, so callers (the CLI's
+// --output flag) can request a StackListOutput JSON document instead of a table.
+func PrintRegistryWithFormat(registryURLs string, devfileType string, options RegistryOptions, showDigest bool, format OutputFormat) error {
 	// Get the registry index
 	registryURLArray := strings.Split(registryURLs, ",")
 	var registryList []Registry
@@ -199,15 +468,53 @@ func PrintRegistry(registryURLs string, devfileType string, options RegistryOpti
 		registryList = GetMultipleRegistryIndices(registryURLArray, options, indexSchema.StackDevfileType, indexSchema.SampleDevfileType)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
-	fmt.Fprintln(w, "Name", "\t", "Description", "\t", "Registry", "\t", "Error", "\t")
+	var entries []StackListEntry
 	for _, devfileRegistry := range registryList {
 		if devfileRegistry.err != nil {
-			fmt.Fprintln(w, "NONE", "\t", "NONE", "\t", devfileRegistry.registryURL, devfileRegistry.err.Error(), "\t")
-		} else {
-			for _, devfileEntry := range devfileRegistry.registryContents {
-				fmt.Fprintln(w, devfileEntry.Name, "\t", devfileEntry.Description, "\t", devfileRegistry.registryURL, "\t", "NONE", "\t")
+			entries = append(entries, StackListEntry{
+				Name:        "NONE",
+				Description: "NONE",
+				Registry:    devfileRegistry.registryURL,
+				Digest:      "NONE",
+				Error:       devfileRegistry.err.Error(),
+			})
+			continue
+		}
+		for _, devfileEntry := range devfileRegistry.registryContents {
+			digest := "NONE"
+			if showDigest && devfileEntry.Type == indexSchema.StackDevfileType {
+				if resolved, err := ResolveStackDigest(devfileRegistry.registryURL, devfileEntry.Name, options); err == nil {
+					digest = resolved
+				}
 			}
+			entries = append(entries, StackListEntry{
+				Name:        devfileEntry.Name,
+				Description: devfileEntry.Description,
+				Registry:    devfileRegistry.registryURL,
+				Digest:      digest,
+				Error:       "NONE",
+			})
+		}
+	}
+
+	if format == JSONOutputFormat {
+		return json.NewEncoder(os.Stdout).Encode(StackListOutput{
+			SchemaVersion: StackListSchemaVersion,
+			Stacks:        entries,
+		})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 2, 3, ' ', tabwriter.TabIndent)
+	if showDigest {
+		fmt.Fprintln(w, "Name", "\t", "Description", "\t", "Registry", "\t", "Digest", "\t", "Error", "\t")
+	} else {
+		fmt.Fprintln(w, "Name", "\t", "Description", "\t", "Registry", "\t", "Error", "\t")
+	}
+	for _, entry := range entries {
+		if showDigest {
+			fmt.Fprintln(w, entry.Name, "\t", entry.Description, "\t", entry.Registry, "\t", entry.Digest, "\t", entry.Error, "\t")
+		} else {
+			fmt.Fprintln(w, entry.Name, "\t", entry.Description, "\t", entry.Registry, "\t", entry.Error, "\t")
 		}
 	}
 	w.Flush()
@@ -216,51 +523,402 @@ func PrintRegistry(registryURLs string, devfileType string, options RegistryOpti
 
 // PullStackByMediaTypesFromRegistry pulls a specified stack with allowed media types from a given registry URL to the destination directory
 func PullStackByMediaTypesFromRegistry(registry string, stack string, allowedMediaTypes []string, destDir string, options RegistryOptions) error {
-	// Get the registry index
-	registryIndex, err := GetRegistryIndex(registry, options, indexSchema.StackDevfileType)
+	// Parse the index to get the specified stack's metadata in the index
+	stackIndex, err := findStackInRegistry(registry, stack, options)
 	if err != nil {
 		return err
 	}
 
-	// Parse the index to get the specified stack's metadata in the index
-	var stackIndex indexSchema.Schema
-	exist := false
-	for _, item := range registryIndex {
-		if item.Name == stack {
-			stackIndex = item
-			exist = true
+	resolver, urlObj, err := newResolver(registry, options)
+	if err != nil {
+		return err
+	}
+	ref := path.Join(urlObj.Host, stackIndex.Links["self"])
+
+	return pullRef(resolver, ref, registry, stack, destDir, allowedMediaTypes, options)
+}
+
+// PullStackFromRegistry pulls a specified stack with all devfile supported media types from a registry URL to the destination directory
+func PullStackFromRegistry(registry string, stack string, destDir string, options RegistryOptions) error {
+	return PullStackByMediaTypesFromRegistry(registry, stack, DevfileAllMediaTypesList, destDir, options)
+}
+
+// PullStackByDigest pulls a specified stack by its immutable manifest digest, rather than by its
+// (mutable) version tag, so CI pipelines can pin exactly the content they tested against. The
+// stack's repository is still resolved from the registry index; only the tag is replaced with digest.
+func PullStackByDigest(registry string, stack string, digest string, destDir string, options RegistryOptions) error {
+	stackIndex, err := findStackInRegistry(registry, stack, options)
+	if err != nil {
+		return err
+	}
+
+	resolver, urlObj, err := newResolver(registry, options)
+	if err != nil {
+		return err
+	}
+
+	repository := stackIndex.Links["self"]
+	if idx := strings.LastIndex(repository, ":"); idx != -1 {
+		repository = repository[:idx]
+	}
+	ref := path.Join(urlObj.Host, repository) + "@" + digest
+
+	return pullRef(resolver, ref, registry, stack, destDir, DevfileAllMediaTypesList, options)
+}
+
+// ResolveStackDigest resolves the manifest digest of a stack's default version, e.g. so a client
+// can record which exact digest it pulled, or pass it to PullStackByDigest later to pin the pull.
+func ResolveStackDigest(registry string, stack string, options RegistryOptions) (string, error) {
+	stackIndex, err := findStackInRegistry(registry, stack, options)
+	if err != nil {
+		return "", err
+	}
+
+	resolver, urlObj, err := newResolver(registry, options)
+	if err != nil {
+		return "", err
+	}
+	ref := path.Join(urlObj.Host, stackIndex.Links["self"])
+
+	_, desc, err := resolver.Resolve(orasctx.Background(), ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of stack %s from %s: %v", stack, ref, err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// DeleteStackVersion deletes a single version of stack from registry via the OCI distribution
+// spec's manifest delete endpoint (DELETE /v2/<name>/manifests/<digest>), so administrative
+// tooling can prune a stack version without manipulating the registry's backing storage
+// directly. Many registries either refuse manifest deletion outright, or require a separate
+// garbage-collection pass afterward to actually reclaim the underlying blobs; a non-2xx response
+// from the delete request is surfaced as an error rather than assumed to mean success.
+//
+// Unlike a pull, this does not perform the full docker-style bearer token auth challenge: when
+// options.UseDockerCredentials is set, the resolved credentials are sent as HTTP Basic auth,
+// which works against registries that accept Basic auth directly on the v2 API (e.g. Harbor) but
+// not ones that require the OAuth2 token exchange PullStackFromRegistry's resolver performs.
+// options.Transport, if set, is still honored, so a caller needing that exchange can supply a
+// RoundTripper that performs it.
+func DeleteStackVersion(registry string, stack string, version string, options RegistryOptions) error {
+	stackIndex, err := findStackInRegistry(registry, stack, options)
+	if err != nil {
+		return err
+	}
+
+	var versionEntry *indexSchema.Version
+	for i := range stackIndex.Versions {
+		if stackIndex.Versions[i].Version == version {
+			versionEntry = &stackIndex.Versions[i]
 			break
 		}
 	}
-	if !exist {
-		return fmt.Errorf("stack %s does not exist in the registry %s", stack, registry)
+	if versionEntry == nil {
+		return fmt.Errorf("stack %s has no version %s in the registry %s", stack, version, registry)
 	}
 
-	// Pull stack initialization
+	resolver, urlObj, err := newResolver(registry, options)
+	if err != nil {
+		return err
+	}
+
+	repository := versionEntry.Links["self"]
+	if idx := strings.LastIndex(repository, ":"); idx != -1 {
+		repository = repository[:idx]
+	}
+	ref := path.Join(urlObj.Host, repository) + ":" + version
+
+	_, desc, err := resolver.Resolve(orasctx.Background(), ref)
+	if err != nil {
+		return fmt.Errorf("failed to resolve digest of stack %s version %s: %v", stack, version, err)
+	}
+
+	scheme := "https"
+	if urlObj.Scheme != "https" {
+		scheme = "http"
+	}
+	deleteURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, urlObj.Host, repository, desc.Digest.String())
+	req, err := http.NewRequest(http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent(options))
+	if options.UseDockerCredentials {
+		if username, secret, credErr := dockerCredentialsFunc()(urlObj.Host); credErr == nil && username != "" {
+			req.SetBasicAuth(username, secret)
+		}
+	}
+
+	httpClient := &http.Client{Transport: baseTransport(options, 0)}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete stack %s version %s: %v", stack, version, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	case http.StatusMethodNotAllowed:
+		return fmt.Errorf("registry %s does not allow manifest deletion (deletion is often disabled by default)", registry)
+	case http.StatusNotFound:
+		return fmt.Errorf("stack %s version %s was not found on the registry (already deleted?)", stack, version)
+	default:
+		return fmt.Errorf("failed to delete stack %s version %s: registry returned %s", stack, version, resp.Status)
+	}
+}
+
+// CacheWarmOptions configures WarmCache.
+type CacheWarmOptions struct {
+	// Filter restricts which stacks and samples are pre-downloaded, mirroring
+	// RegistryOptions.Filter's architecture-based selection.
+	Filter RegistryFilter
+	// IncludeStackContent, when true, also pulls each matching stack's devfile and resources into
+	// cacheDir, not just the index metadata. Samples have no packaged OCI content to pull here.
+	IncludeStackContent bool
+}
+
+// CacheWarmSummary reports what WarmCache downloaded, distinguishing stacks whose content failed
+// to pull from those that succeeded, so a caller can log or fail CI on incomplete coverage.
+type CacheWarmSummary struct {
+	IndexedStacks  int
+	IndexedSamples int
+	PulledStacks   []string
+	FailedStacks   []string
+}
+
+// WarmCache pre-downloads registryURL's index, and optionally every matching stack's content,
+// into cacheDir, so a later run pointed at the same cacheDir (e.g. an IDE's offline mode, or a CI
+// base image built with no network access) can be served entirely from disk. The index is written
+// to cacheDir/index.json; stack content, if requested, is written under cacheDir/stacks/<name>.
+func WarmCache(registryURL string, cacheDir string, opts CacheWarmOptions, options RegistryOptions) (CacheWarmSummary, error) {
+	var summary CacheWarmSummary
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return summary, err
+	}
+
+	options.Filter = opts.Filter
+	index, err := GetRegistryIndex(registryURL, options, indexSchema.StackDevfileType, indexSchema.SampleDevfileType)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch index from %s: %v", registryURL, err)
+	}
+
+	indexBytes, err := json.MarshalIndent(&index, "", "  ")
+	if err != nil {
+		return summary, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(cacheDir, "index.json"), indexBytes, 0644); err != nil {
+		return summary, err
+	}
+
+	for _, entry := range index {
+		if entry.Type == indexSchema.SampleDevfileType {
+			summary.IndexedSamples++
+			continue
+		}
+		summary.IndexedStacks++
+
+		if !opts.IncludeStackContent {
+			continue
+		}
+
+		stackDir := filepath.Join(cacheDir, "stacks", entry.Name)
+		if err := PullStackFromRegistry(registryURL, entry.Name, stackDir, options); err != nil {
+			summary.FailedStacks = append(summary.FailedStacks, entry.Name)
+			continue
+		}
+		summary.PulledStacks = append(summary.PulledStacks, entry.Name)
+	}
+
+	return summary, nil
+}
+
+// ArtifactLayer describes one layer of a stack's OCI artifact manifest.
+type ArtifactLayer struct {
+	MediaType string
+	Digest    string
+	Size      int64
+}
+
+// GetStackManifest resolves and fetches a stack's OCI artifact manifest and returns its layers
+// (media type, digest, and size), so advanced consumers like scanners and mirroring tools can
+// selectively fetch only the layers they need instead of pulling the whole artifact.
+func GetStackManifest(registry string, stack string, options RegistryOptions) ([]ArtifactLayer, error) {
+	stackIndex, err := findStackInRegistry(registry, stack, options)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, urlObj, err := newResolver(registry, options)
+	if err != nil {
+		return nil, err
+	}
+	ref := path.Join(urlObj.Host, stackIndex.Links["self"])
+
 	ctx := orasctx.Background()
+	_, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve manifest of stack %s from %s: %v", stack, ref, err)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest of stack %s from %s: %v", stack, ref, err)
+	}
+	reader, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest of stack %s from %s: %v", stack, ref, err)
+	}
+	defer reader.Close()
+
+	manifestBytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest of stack %s from %s: %v", stack, ref, err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest of stack %s from %s: %v", stack, ref, err)
+	}
+
+	layers := make([]ArtifactLayer, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		layers = append(layers, ArtifactLayer{
+			MediaType: layer.MediaType,
+			Digest:    layer.Digest.String(),
+			Size:      layer.Size,
+		})
+	}
+	return layers, nil
+}
+
+// findStackInRegistry looks up a stack's index entry by name in the given registry.
+func findStackInRegistry(registry string, stack string, options RegistryOptions) (indexSchema.Schema, error) {
+	registryIndex, err := GetRegistryIndex(registry, options, indexSchema.StackDevfileType)
+	if err != nil {
+		return indexSchema.Schema{}, err
+	}
+
+	for _, item := range registryIndex {
+		if item.Name == stack {
+			return item, nil
+		}
+	}
+	return indexSchema.Schema{}, fmt.Errorf("stack %s does not exist in the registry %s", stack, registry)
+}
+
+// newResolver builds a remotes.Resolver for the given registry URL, honoring TLS and credential options.
+func newResolver(registry string, options RegistryOptions) (remotes.Resolver, *url.URL, error) {
 	urlObj, err := url.Parse(registry)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	plainHTTP := true
 	if urlObj.Scheme == "https" {
 		plainHTTP = false
 	}
+	transport := baseTransport(options, 0)
+	if options.MaxDownloadBytesPerSecond > 0 {
+		transport = &throttledTransport{base: transport, bytesPerSecond: options.MaxDownloadBytesPerSecond}
+	}
 	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: options.SkipTLSVerify},
-		},
+		Transport: transport,
 	}
 	headers := make(http.Header)
 	setHeaders(&headers, options)
 
-	resolver := docker.NewResolver(docker.ResolverOptions{Headers: headers, PlainHTTP: plainHTTP, Client: httpClient})
-	ref := path.Join(urlObj.Host, stackIndex.Links["self"])
-	fileStore := content.NewFileStore(destDir)
-	defer fileStore.Close()
+	resolverOptions := docker.ResolverOptions{Headers: headers, PlainHTTP: plainHTTP, Client: httpClient}
+	if options.UseDockerCredentials {
+		resolverOptions.Credentials = dockerCredentialsFunc()
+	}
+	return docker.NewResolver(resolverOptions), urlObj, nil
+}
+
+// pullRef pulls the OCI artifact at ref to destDir, through options.CacheDaemonSocket if one is
+// configured, or directly otherwise.
+func pullRef(resolver remotes.Resolver, ref string, registry string, stack string, destDir string, allowedMediaTypes []string, options RegistryOptions) error {
+	var err error
+	if options.CacheDaemonSocket == "" {
+		err = doPullRef(resolver, ref, registry, stack, destDir, allowedMediaTypes, options)
+	} else {
+		err = pullRefThroughDaemonCache(resolver, ref, registry, stack, destDir, allowedMediaTypes, options)
+	}
+	if err != nil {
+		return err
+	}
+	return checkMaxSchemaVersion(destDir, options.MaxSchemaVersion)
+}
+
+// pullRefThroughDaemonCache serves a pull out of the local cache daemon at
+// options.CacheDaemonSocket when possible, falling back to a direct doPullRef if the daemon is
+// unreachable, so a misbehaving or absent daemon never turns a pure optimization into a failure.
+// On a miss, the pull is performed directly and then the daemon is populated for the next local
+// consumer requesting the same content.
+func pullRefThroughDaemonCache(resolver remotes.Resolver, ref string, registry string, stack string, destDir string, allowedMediaTypes []string, options RegistryOptions) error {
+	key := cacheKeyForPull(ref, allowedMediaTypes)
+	data, conn, hit, err := cacheDaemonGet(options.CacheDaemonSocket, key)
+	if err != nil {
+		return doPullRef(resolver, ref, registry, stack, destDir, allowedMediaTypes, options)
+	}
+	if hit {
+		if options.Metrics.OnCacheHit != nil {
+			options.Metrics.OnCacheHit(registry)
+		}
+		return extractBytesToDir(data, destDir, options.ExtractionUmask)
+	}
 
-	// Pull stack from registry and save it to disk
-	_, _, err = oras.Pull(ctx, resolver, ref, fileStore, oras.WithAllowedMediaTypes(allowedMediaTypes))
+	// Miss: conn holds our claim on key until we PUT or close it.
+	if err := doPullRef(resolver, ref, registry, stack, destDir, allowedMediaTypes, options); err != nil {
+		conn.Close()
+		return err
+	}
+
+	archived, err := archiveDirToBytes(destDir)
+	if err != nil {
+		// The pull itself succeeded; failing to populate the shared cache isn't fatal to the caller.
+		conn.Close()
+		return nil
+	}
+	if err := cacheDaemonPut(conn, key, archived); err != nil {
+		log.Printf("failed to populate local cache daemon: %v", err)
+	}
+	return nil
+}
+
+// doPullRef pulls the OCI artifact at ref to destDir, retrying up to options.MaxPullRetries times.
+// destDir is reused across retries so that resources already written by a prior, interrupted
+// attempt don't need to be re-downloaded.
+func doPullRef(resolver remotes.Resolver, ref string, registry string, stack string, destDir string, allowedMediaTypes []string, options RegistryOptions) error {
+	ctx := orasctx.Background()
+
+	pullStart := time.Now()
+	var totalBytes int64
+	var err error
+	for attempt := 0; ; attempt++ {
+		fileStore := content.NewFileStore(destDir)
+		_, pulledDescs, pullErr := oras.Pull(ctx, resolver, ref, fileStore, oras.WithAllowedMediaTypes(allowedMediaTypes))
+		fileStore.Close()
+		err = pullErr
+		if err == nil {
+			totalBytes = 0
+			for _, desc := range pulledDescs {
+				totalBytes += desc.Size
+			}
+		}
+		if err == nil || attempt >= options.MaxPullRetries {
+			break
+		}
+		if options.Metrics.OnRetry != nil {
+			options.Metrics.OnRetry(registry, attempt+1)
+		}
+	}
+	if options.Metrics.OnPullLatency != nil {
+		options.Metrics.OnPullLatency(registry, stack, time.Since(pullStart))
+	}
+	if options.Metrics.OnBytesDownloaded != nil {
+		options.Metrics.OnBytesDownloaded(registry, stack, totalBytes)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to pull stack %s from %s with allowed media types %v: %v", stack, ref, allowedMediaTypes, err)
 	}
@@ -268,7 +926,7 @@ func PullStackByMediaTypesFromRegistry(registry string, stack string, allowedMed
 	// Decompress archive.tar
 	archivePath := filepath.Join(destDir, "archive.tar")
 	if _, err := os.Stat(archivePath); err == nil {
-		err := decompress(destDir, archivePath)
+		err := decompress(destDir, archivePath, options.ResourceFilter, options.ExtractionUmask)
 		if err != nil {
 			return err
 		}
@@ -282,13 +940,13 @@ func PullStackByMediaTypesFromRegistry(registry string, stack string, allowedMed
 	return nil
 }
 
-// PullStackFromRegistry pulls a specified stack with all devfile supported media types from a registry URL to the destination directory
-func PullStackFromRegistry(registry string, stack string, destDir string, options RegistryOptions) error {
-	return PullStackByMediaTypesFromRegistry(registry, stack, DevfileAllMediaTypesList, destDir, options)
-}
-
-// decompress extracts the archive file
-func decompress(targetDir string, tarFile string) error {
+// decompress extracts the archive file. If resourceFilter is non-empty, only entries whose name
+// exactly matches one of its paths are written to disk; every other entry is skipped without
+// being read into memory, so extracting a subset of a large archive doesn't pay to materialize
+// the rest. umask, if non-zero, is applied to every extracted entry's mode; uid/gid from the tar
+// header are never applied regardless, since os.MkdirAll/os.OpenFile create files owned by this
+// process.
+func decompress(targetDir string, tarFile string, resourceFilter []string, umask os.FileMode) error {
 	reader, err := os.Open(tarFile)
 	if err != nil {
 		return err
@@ -310,15 +968,20 @@ func decompress(targetDir string, tarFile string) error {
 			return err
 		}
 
+		if header.Typeflag == tar.TypeReg && !wantsResource(header.Name, resourceFilter) {
+			continue
+		}
+
 		target := path.Join(targetDir, header.Name)
+		mode := os.FileMode(header.Mode) &^ umask
 		switch header.Typeflag {
 		case tar.TypeDir:
-			err = os.MkdirAll(target, os.FileMode(header.Mode))
+			err = os.MkdirAll(target, mode)
 			if err != nil {
 				return err
 			}
 		case tar.TypeReg:
-			w, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+			w, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, mode)
 			if err != nil {
 				return err
 			}
@@ -335,8 +998,24 @@ func decompress(targetDir string, tarFile string) error {
 	return nil
 }
 
-//setHeaders sets the request headers
+// wantsResource reports whether name should be extracted, given resourceFilter. An empty filter
+// wants everything, preserving the behavior of extracting the whole archive.
+func wantsResource(name string, resourceFilter []string) bool {
+	if len(resourceFilter) == 0 {
+		return true
+	}
+	for _, resource := range resourceFilter {
+		if name == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// setHeaders sets the request headers
 func setHeaders(headers *http.Header, options RegistryOptions) {
+	headers.Set("User-Agent", userAgent(options))
+
 	t := options.Telemetry
 	if t.User != "" {
 		headers.Add("User", t.User)
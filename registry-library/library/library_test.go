@@ -1,11 +1,21 @@
 package library
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -150,3 +160,390 @@ func TestGetRegistryIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestWarmCacheWritesIndexWithoutStackContent(t *testing.T) {
+	index := []indexSchema.Schema{
+		{Name: "stack1", Type: indexSchema.StackDevfileType},
+		{Name: "sample1", Type: indexSchema.SampleDevfileType},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, err := json.MarshalIndent(&index, "", "  ")
+		if err != nil {
+			t.Errorf("Unexpected error while doing json marshal: %v", err)
+			return
+		}
+		if _, err := w.Write(bytes); err != nil {
+			t.Errorf("Unexpected error while writing data: %v", err)
+		}
+	}))
+	defer testServer.Close()
+
+	cacheDir, err := ioutil.TempDir("", "warm-cache")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	summary, err := WarmCache(testServer.URL, cacheDir, CacheWarmOptions{}, RegistryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if summary.IndexedStacks != 1 || summary.IndexedSamples != 1 {
+		t.Errorf("Expected 1 indexed stack and 1 indexed sample, got %+v", summary)
+	}
+	if len(summary.PulledStacks) != 0 {
+		t.Errorf("Expected no stacks pulled when IncludeStackContent is false, got %+v", summary.PulledStacks)
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, "index.json")); err != nil {
+		t.Errorf("Expected index.json to be written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "stacks", "stack1")); !os.IsNotExist(err) {
+		t.Errorf("Expected no stack content to be pulled, got err: %v", err)
+	}
+}
+
+// TestClientConcurrentGetRegistryIndex exercises a single shared Client from many goroutines at
+// once. Run with `go test -race` to verify no data races; the assertions here only catch
+// functional regressions, since the race detector reports its own failures independently.
+func TestClientConcurrentGetRegistryIndex(t *testing.T) {
+	index := []indexSchema.Schema{
+		{Name: "stack1", Type: indexSchema.StackDevfileType},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, err := json.MarshalIndent(&index, "", "  ")
+		if err != nil {
+			t.Errorf("Unexpected error while doing json marshal: %v", err)
+			return
+		}
+		if _, err := w.Write(bytes); err != nil {
+			t.Errorf("Unexpected error while writing data: %v", err)
+		}
+	}))
+	defer testServer.Close()
+
+	client := NewClient(RegistryOptions{})
+
+	const concurrentCalls = 20
+	errs := make(chan error, concurrentCalls)
+	for i := 0; i < concurrentCalls; i++ {
+		go func() {
+			result, err := client.GetRegistryIndex(testServer.URL, indexSchema.StackDevfileType)
+			if err == nil && (len(result) != 1 || result[0].Name != "stack1") {
+				err = fmt.Errorf("unexpected result: %+v", result)
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < concurrentCalls; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Unexpected error from concurrent call: %v", err)
+		}
+	}
+}
+
+// countingTransport wraps a base http.RoundTripper and counts how many requests pass through it,
+// standing in for a corporate auth injector, logging middleware, or chaos-testing transport.
+type countingTransport struct {
+	base     http.RoundTripper
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return t.base.RoundTrip(req)
+}
+
+func TestClientUsesCustomTransport(t *testing.T) {
+	index := []indexSchema.Schema{
+		{Name: "stack1", Type: indexSchema.StackDevfileType},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, err := json.MarshalIndent(&index, "", "  ")
+		if err != nil {
+			t.Errorf("Unexpected error while doing json marshal: %v", err)
+			return
+		}
+		if _, err := w.Write(bytes); err != nil {
+			t.Errorf("Unexpected error while writing data: %v", err)
+		}
+	}))
+	defer testServer.Close()
+
+	transport := &countingTransport{base: http.DefaultTransport}
+	client := NewClient(RegistryOptions{Transport: transport})
+
+	_, err := client.GetRegistryIndex(testServer.URL, indexSchema.StackDevfileType)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("Expected the custom transport to see 1 request, saw %d", transport.requests)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name    string
+		options RegistryOptions
+		want    string
+	}{
+		{
+			name: "Default",
+			want: "registry-library/" + libraryVersion,
+		},
+		{
+			name:    "Product name only",
+			options: RegistryOptions{ProductName: "odo"},
+			want:    "odo",
+		},
+		{
+			name:    "Product name and version",
+			options: RegistryOptions{ProductName: "odo", ProductVersion: "3.0.0"},
+			want:    "odo/3.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := userAgent(tt.options); got != tt.want {
+				t.Errorf("userAgent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRegistryIndexSendsProductUserAgent(t *testing.T) {
+	var gotUserAgent string
+	index := []indexSchema.Schema{{Name: "stack1", Type: indexSchema.StackDevfileType}}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		bytes, err := json.MarshalIndent(&index, "", "  ")
+		if err != nil {
+			t.Errorf("Unexpected error while doing json marshal: %v", err)
+			return
+		}
+		if _, err := w.Write(bytes); err != nil {
+			t.Errorf("Unexpected error while writing data: %v", err)
+		}
+	}))
+	defer testServer.Close()
+
+	client := NewClient(RegistryOptions{ProductName: "odo", ProductVersion: "3.0.0"})
+	if _, err := client.GetRegistryIndex(testServer.URL, indexSchema.StackDevfileType); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotUserAgent != "odo/3.0.0" {
+		t.Errorf("Expected User-Agent %q, got %q", "odo/3.0.0", gotUserAgent)
+	}
+}
+
+func TestBaseTransportUsesProxyFromEnvironment(t *testing.T) {
+	// http.ProxyFromEnvironment caches the proxy environment variables the first time it's
+	// called in a process, so this only checks that baseTransport wires up the same function
+	// net/http itself uses (rather than leaving Proxy nil, as a bare &http.Transport{} would),
+	// not that a given HTTP_PROXY value resolves correctly end to end.
+	transport, ok := baseTransport(RegistryOptions{}, 0).(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected baseTransport to return a *http.Transport, got %T", baseTransport(RegistryOptions{}, 0))
+	}
+	if reflect.ValueOf(transport.Proxy).Pointer() != reflect.ValueOf(http.ProxyFromEnvironment).Pointer() {
+		t.Error("Expected baseTransport's transport to use http.ProxyFromEnvironment")
+	}
+}
+
+func TestEffectiveArchitectures(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter RegistryFilter
+		want   []string
+	}{
+		{
+			name:   "Explicit architectures take precedence",
+			filter: RegistryFilter{Architectures: []string{"amd64", "arm64"}, AutoDetectPlatform: true},
+			want:   []string{"amd64", "arm64"},
+		},
+		{
+			name:   "Auto-detect falls back to runtime.GOARCH",
+			filter: RegistryFilter{AutoDetectPlatform: true},
+			want:   []string{runtime.GOARCH},
+		},
+		{
+			name:   "No filtering when neither is set",
+			filter: RegistryFilter{},
+			want:   nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.filter.effectiveArchitectures()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected: %+v, \nGot: %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestVerifySPKIPin(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer testServer.Close()
+
+	cert, err := x509.ParseCertificate(testServer.Certificate().Raw)
+	if err != nil {
+		t.Fatalf("failed to parse test server certificate: %v", err)
+	}
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	validPin := base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		name    string
+		pin     string
+		wantErr bool
+	}{
+		{
+			name: "Matching pin is accepted",
+			pin:  validPin,
+		},
+		{
+			name:    "Mismatched pin is rejected",
+			pin:     "not-the-right-pin",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifySPKIPin(test.pin)([][]byte{cert.Raw}, nil)
+			if test.wantErr && err == nil {
+				t.Errorf("Expected error but got nil")
+			} else if !test.wantErr && err != nil {
+				t.Errorf("Unexpected err: %+v", err)
+			}
+		})
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	tlsConfig := buildTLSConfig(RegistryOptions{})
+	if tlsConfig.VerifyPeerCertificate != nil {
+		t.Errorf("Expected no VerifyPeerCertificate callback when PinnedSPKISHA256 is unset")
+	}
+
+	tlsConfig = buildTLSConfig(RegistryOptions{PinnedSPKISHA256: "some-pin"})
+	if tlsConfig.VerifyPeerCertificate == nil {
+		t.Errorf("Expected a VerifyPeerCertificate callback when PinnedSPKISHA256 is set")
+	}
+}
+
+func TestDecompressWithResourceFilter(t *testing.T) {
+	targetDir, err := ioutil.TempDir("", "decompress-filter")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(targetDir)
+
+	tarPath := filepath.Join(targetDir, "archive.tar")
+	writeTestArchive(t, tarPath, map[string]string{
+		"devfile.yaml":           "schemaVersion: 2.0.0",
+		"kubernetes/deploy.yaml": "kind: Deployment",
+		"logo.svg":               "<svg></svg>",
+	})
+
+	if err := decompress(targetDir, tarPath, []string{"devfile.yaml", "kubernetes/deploy.yaml"}, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(targetDir, "devfile.yaml")); err != nil {
+		t.Errorf("Expected devfile.yaml to be extracted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "kubernetes/deploy.yaml")); err != nil {
+		t.Errorf("Expected kubernetes/deploy.yaml to be extracted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "logo.svg")); !os.IsNotExist(err) {
+		t.Errorf("Expected logo.svg to be filtered out, got err: %v", err)
+	}
+}
+
+func TestDecompressWithoutResourceFilterExtractsEverything(t *testing.T) {
+	targetDir, err := ioutil.TempDir("", "decompress-nofilter")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(targetDir)
+
+	tarPath := filepath.Join(targetDir, "archive.tar")
+	writeTestArchive(t, tarPath, map[string]string{
+		"devfile.yaml": "schemaVersion: 2.0.0",
+		"logo.svg":     "<svg></svg>",
+	})
+
+	if err := decompress(targetDir, tarPath, nil, 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"devfile.yaml", "logo.svg"} {
+		if _, err := os.Stat(filepath.Join(targetDir, name)); err != nil {
+			t.Errorf("Expected %s to be extracted: %v", name, err)
+		}
+	}
+}
+
+func TestDecompressAppliesExtractionUmask(t *testing.T) {
+	targetDir, err := ioutil.TempDir("", "decompress-umask")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(targetDir)
+
+	tarPath := filepath.Join(targetDir, "archive.tar")
+	writeTestArchive(t, tarPath, map[string]string{"devfile.yaml": "schemaVersion: 2.0.0"})
+
+	if err := decompress(targetDir, tarPath, nil, 0022); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(targetDir, "devfile.yaml"))
+	if err != nil {
+		t.Fatalf("Expected devfile.yaml to be extracted: %v", err)
+	}
+	if info.Mode().Perm() != 0644&^0022 {
+		t.Errorf("Expected mode %o after applying umask, got %o", 0644&^0022, info.Mode().Perm())
+	}
+}
+
+// writeTestArchive writes a gzipped tar containing files at tarPath.
+func writeTestArchive(t *testing.T, tarPath string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	gzWriter := gzip.NewWriter(f)
+	defer gzWriter.Close()
+
+	tarWriter := tar.NewWriter(gzWriter)
+	defer tarWriter.Close()
+
+	for name, content := range files {
+		if dir := filepath.Dir(name); dir != "." {
+			if err := tarWriter.WriteHeader(&tar.Header{Name: dir, Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		header := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
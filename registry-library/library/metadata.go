@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// DevfileMetadata mirrors the subset of devfile/api's v1alpha2.DevfileMetadata that a registry
+// index entry can populate. The full devfile/api package isn't a dependency of this module, so
+// this is a lightweight structural copy rather than a type alias; keep the field set in sync with
+// devfile/api's DevfileMetadata if that type changes.
+type DevfileMetadata struct {
+	Name              string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Version           string   `yaml:"version,omitempty" json:"version,omitempty"`
+	DisplayName       string   `yaml:"displayName,omitempty" json:"displayName,omitempty"`
+	Description       string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Tags              []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Architectures     []string `yaml:"architectures,omitempty" json:"architectures,omitempty"`
+	Icon              string   `yaml:"icon,omitempty" json:"icon,omitempty"`
+	GlobalMemoryLimit string   `yaml:"globalMemoryLimit,omitempty" json:"globalMemoryLimit,omitempty"`
+	ProjectType       string   `yaml:"projectType,omitempty" json:"projectType,omitempty"`
+	Language          string   `yaml:"language,omitempty" json:"language,omitempty"`
+	Provider          string   `yaml:"provider,omitempty" json:"provider,omitempty"`
+	SupportUrl        string   `yaml:"supportUrl,omitempty" json:"supportUrl,omitempty"`
+}
+
+// SchemaToDevfileMetadata converts an index Schema entry, such as one returned by
+// GetRegistryIndex, into a DevfileMetadata, so consumers like odo and Che don't each need their
+// own copy of this field mapping.
+func SchemaToDevfileMetadata(schema indexSchema.Schema) DevfileMetadata {
+	return DevfileMetadata{
+		Name:              schema.Name,
+		Version:           schema.Version,
+		DisplayName:       schema.DisplayName,
+		Description:       schema.Description,
+		Tags:              schema.Tags,
+		Architectures:     schema.Architectures,
+		Icon:              schema.Icon,
+		GlobalMemoryLimit: schema.GlobalMemoryLimit,
+		ProjectType:       schema.ProjectType,
+		Language:          schema.Language,
+		Provider:          schema.Provider,
+		SupportUrl:        schema.SupportUrl,
+	}
+}
+
+// VersionToDevfileMetadata converts a specific Version of a stack, such as one returned by
+// findStackVersionInRegistry, into a DevfileMetadata. parent supplies the stack-level fields
+// (DisplayName, GlobalMemoryLimit, ProjectType, Language, Provider, SupportUrl) that Version
+// itself does not carry.
+func VersionToDevfileMetadata(parent indexSchema.Schema, version indexSchema.Version) DevfileMetadata {
+	return DevfileMetadata{
+		Name:              parent.Name,
+		Version:           version.Version,
+		DisplayName:       parent.DisplayName,
+		Description:       version.Description,
+		Tags:              version.Tags,
+		Architectures:     version.Architectures,
+		Icon:              version.Icon,
+		GlobalMemoryLimit: parent.GlobalMemoryLimit,
+		ProjectType:       parent.ProjectType,
+		Language:          parent.Language,
+		Provider:          parent.Provider,
+		SupportUrl:        parent.SupportUrl,
+	}
+}
+
+// DevfileMetadataToSchema converts a DevfileMetadata back into an index Schema entry, the inverse
+// of SchemaToDevfileMetadata, for callers assembling an index entry from devfile metadata they
+// already have in hand.
+func DevfileMetadataToSchema(metadata DevfileMetadata) indexSchema.Schema {
+	return indexSchema.Schema{
+		Name:              metadata.Name,
+		Version:           metadata.Version,
+		DisplayName:       metadata.DisplayName,
+		Description:       metadata.Description,
+		Tags:              metadata.Tags,
+		Architectures:     metadata.Architectures,
+		Icon:              metadata.Icon,
+		GlobalMemoryLimit: metadata.GlobalMemoryLimit,
+		ProjectType:       metadata.ProjectType,
+		Language:          metadata.Language,
+		Provider:          metadata.Provider,
+		SupportUrl:        metadata.SupportUrl,
+	}
+}
@@ -0,0 +1,120 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"reflect"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestSchemaToDevfileMetadata(t *testing.T) {
+	schema := indexSchema.Schema{
+		Name:        "nodejs",
+		Version:     "2.0.0",
+		DisplayName: "Node.js Runtime",
+		Description: "Stack for Node.js apps",
+		Tags:        []string{"NodeJS", "Express"},
+		Icon:        "icon.png",
+		ProjectType: "nodejs",
+		Language:    "javascript",
+		Provider:    "Red Hat",
+		SupportUrl:  "https://example.com/support",
+	}
+
+	want := DevfileMetadata{
+		Name:        "nodejs",
+		Version:     "2.0.0",
+		DisplayName: "Node.js Runtime",
+		Description: "Stack for Node.js apps",
+		Tags:        []string{"NodeJS", "Express"},
+		Icon:        "icon.png",
+		ProjectType: "nodejs",
+		Language:    "javascript",
+		Provider:    "Red Hat",
+		SupportUrl:  "https://example.com/support",
+	}
+
+	got := SchemaToDevfileMetadata(schema)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestVersionToDevfileMetadata(t *testing.T) {
+	parent := indexSchema.Schema{
+		Name:        "nodejs",
+		DisplayName: "Node.js Runtime",
+		ProjectType: "nodejs",
+		Language:    "javascript",
+		Provider:    "Red Hat",
+		SupportUrl:  "https://example.com/support",
+	}
+	version := indexSchema.Version{
+		Version:     "1.0.0",
+		Description: "Older Node.js stack",
+		Tags:        []string{"NodeJS"},
+		Icon:        "icon-v1.png",
+	}
+
+	want := DevfileMetadata{
+		Name:        "nodejs",
+		Version:     "1.0.0",
+		DisplayName: "Node.js Runtime",
+		Description: "Older Node.js stack",
+		Tags:        []string{"NodeJS"},
+		Icon:        "icon-v1.png",
+		ProjectType: "nodejs",
+		Language:    "javascript",
+		Provider:    "Red Hat",
+		SupportUrl:  "https://example.com/support",
+	}
+
+	got := VersionToDevfileMetadata(parent, version)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDevfileMetadataToSchema(t *testing.T) {
+	metadata := DevfileMetadata{
+		Name:        "nodejs",
+		Version:     "2.0.0",
+		DisplayName: "Node.js Runtime",
+		Description: "Stack for Node.js apps",
+		Tags:        []string{"NodeJS", "Express"},
+		Icon:        "icon.png",
+		ProjectType: "nodejs",
+		Language:    "javascript",
+		Provider:    "Red Hat",
+		SupportUrl:  "https://example.com/support",
+	}
+
+	want := indexSchema.Schema{
+		Name:        "nodejs",
+		Version:     "2.0.0",
+		DisplayName: "Node.js Runtime",
+		Description: "Stack for Node.js apps",
+		Tags:        []string{"NodeJS", "Express"},
+		Icon:        "icon.png",
+		ProjectType: "nodejs",
+		Language:    "javascript",
+		Provider:    "Red Hat",
+		SupportUrl:  "https://example.com/support",
+	}
+
+	got := DevfileMetadataToSchema(metadata)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+// Stack wraps a single index Schema entry with typed convenience queries, so consumers stop
+// re-implementing the same "find the default version" / "find versions built against a given
+// devfile schema version" lookups against the raw Versions slice.
+type Stack struct {
+	indexSchema.Schema
+}
+
+// NewStack wraps schema as a Stack.
+func NewStack(schema indexSchema.Schema) Stack {
+	return Stack{Schema: schema}
+}
+
+// LatestVersion returns the stack's default version (the one flagged Default in the index), which
+// is the version a client should show or pull unless the user asked for a specific one. Returns
+// false if the stack has no versions at all, which shouldn't happen for a stack produced by a
+// well-formed index (validateIndexComponent requires exactly one default version at build time)
+// but can occur for a hand-built Schema or a sample using the legacy unversioned layout.
+func (s Stack) LatestVersion() (indexSchema.Version, bool) {
+	for _, version := range s.Versions {
+		if version.Default {
+			return version, true
+		}
+	}
+	return indexSchema.Version{}, false
+}
+
+// VersionsMatching returns this stack's versions whose SchemaVersion equals schemaVersion, so a
+// consumer that only supports a specific devfile spec version (e.g. an older IDE plugin bundling
+// an older devfile parser) can filter down to the versions it can actually parse instead of
+// walking Versions itself.
+func (s Stack) VersionsMatching(schemaVersion string) []indexSchema.Version {
+	var matches []indexSchema.Version
+	for _, version := range s.Versions {
+		if version.SchemaVersion == schemaVersion {
+			matches = append(matches, version)
+		}
+	}
+	return matches
+}
+
+// Index wraps a registry index (a slice of Schema entries, as returned by GetRegistryIndex) with
+// typed convenience queries.
+type Index []indexSchema.Schema
+
+// Stacks returns idx's entries typed as Stack.
+func (idx Index) Stacks() []Stack {
+	stacks := make([]Stack, 0, len(idx))
+	for _, entry := range idx {
+		stacks = append(stacks, NewStack(entry))
+	}
+	return stacks
+}
+
+// FilterByLanguage returns idx's entries whose Language field equals language, so a consumer
+// building a language-scoped stack picker doesn't need to re-implement this filter over the raw
+// index slice. The comparison is case-sensitive, matching how the index itself stores the value.
+func (idx Index) FilterByLanguage(language string) Index {
+	var filtered Index
+	for _, entry := range idx {
+		if entry.Language == language {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"reflect"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestStackLatestVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		stack  Stack
+		want   indexSchema.Version
+		wantOk bool
+	}{
+		{
+			name: "Default version present",
+			stack: NewStack(indexSchema.Schema{
+				Versions: []indexSchema.Version{
+					{Version: "1.0.0", SchemaVersion: "2.0.0"},
+					{Version: "1.1.0", SchemaVersion: "2.1.0", Default: true},
+				},
+			}),
+			want:   indexSchema.Version{Version: "1.1.0", SchemaVersion: "2.1.0", Default: true},
+			wantOk: true,
+		},
+		{
+			name:   "No versions",
+			stack:  NewStack(indexSchema.Schema{}),
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.stack.LatestVersion()
+			if ok != tt.wantOk {
+				t.Fatalf("LatestVersion() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LatestVersion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStackVersionsMatching(t *testing.T) {
+	stack := NewStack(indexSchema.Schema{
+		Versions: []indexSchema.Version{
+			{Version: "1.0.0", SchemaVersion: "2.0.0"},
+			{Version: "1.1.0", SchemaVersion: "2.1.0"},
+			{Version: "1.2.0", SchemaVersion: "2.1.0"},
+		},
+	})
+
+	got := stack.VersionsMatching("2.1.0")
+	want := []indexSchema.Version{
+		{Version: "1.1.0", SchemaVersion: "2.1.0"},
+		{Version: "1.2.0", SchemaVersion: "2.1.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VersionsMatching(%q) = %+v, want %+v", "2.1.0", got, want)
+	}
+
+	if got := stack.VersionsMatching("9.9.9"); len(got) != 0 {
+		t.Errorf("VersionsMatching(%q) = %+v, want empty", "9.9.9", got)
+	}
+}
+
+func TestIndexFilterByLanguage(t *testing.T) {
+	idx := Index{
+		{Name: "nodejs", Language: "nodejs"},
+		{Name: "java-maven", Language: "java"},
+		{Name: "java-quarkus", Language: "java"},
+	}
+
+	got := idx.FilterByLanguage("java")
+	want := Index{
+		{Name: "java-maven", Language: "java"},
+		{Name: "java-quarkus", Language: "java"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterByLanguage(%q) = %+v, want %+v", "java", got, want)
+	}
+
+	if got := idx.FilterByLanguage("go"); len(got) != 0 {
+		t.Errorf("FilterByLanguage(%q) = %+v, want empty", "go", got)
+	}
+}
+
+func TestIndexStacks(t *testing.T) {
+	idx := Index{{Name: "nodejs"}, {Name: "go"}}
+	stacks := idx.Stacks()
+	if len(stacks) != 2 {
+		t.Fatalf("Stacks() returned %d entries, want 2", len(stacks))
+	}
+	if stacks[0].Name != "nodejs" || stacks[1].Name != "go" {
+		t.Errorf("Stacks() = %+v, want entries in the same order as idx", stacks)
+	}
+}
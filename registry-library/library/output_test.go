@@ -0,0 +1,46 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import "testing"
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    OutputFormat
+		wantErr bool
+	}{
+		{name: "empty defaults to table", input: "", want: TableOutputFormat},
+		{name: "table", input: "table", want: TableOutputFormat},
+		{name: "json", input: "json", want: JSONOutputFormat},
+		{name: "unknown", input: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOutputFormat(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseOutputFormat(%q) expected an error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOutputFormat(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseOutputFormat(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
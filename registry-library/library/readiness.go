@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2023 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// readinessInitialPollInterval is the delay before the second readiness check in WaitForRegistry.
+// Doubles after each subsequent failure, capped at readinessMaxPollInterval.
+const readinessInitialPollInterval = 250 * time.Millisecond
+
+// readinessMaxPollInterval caps the delay between readiness checks in WaitForRegistry.
+const readinessMaxPollInterval = 5 * time.Second
+
+// WaitForRegistry polls registryURL until it responds as ready or timeout elapses, so an
+// integration test or an init container ahead of a registry-dependent workload has a standard
+// way to wait for a devfile registry to finish starting instead of hardcoding a sleep. It checks
+// registryURL's "/health" endpoint first, falling back to fetching the index itself (some
+// deployments front only an OCI registry with no index server /health endpoint in front of it).
+// A non-positive timeout means "wait forever" (bounded only by ctx).
+func WaitForRegistry(ctx context.Context, registryURL string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	httpClient := &http.Client{Transport: baseTransport(RegistryOptions{}, 0)}
+	registryURL = strings.TrimSuffix(registryURL, "/")
+
+	interval := readinessInitialPollInterval
+	for {
+		if registryIsReady(ctx, httpClient, registryURL) {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("timed out waiting for registry %s to become ready: %w", registryURL, err)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("timed out waiting for registry %s to become ready: %w", registryURL, ctx.Err())
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > readinessMaxPollInterval {
+			interval = readinessMaxPollInterval
+		}
+	}
+}
+
+// registryIsReady reports whether registryURL's "/health" endpoint, or failing that the index
+// itself, responds with a successful status.
+func registryIsReady(ctx context.Context, httpClient *http.Client, registryURL string) bool {
+	if httpGetOK(ctx, httpClient, registryURL+"/health") {
+		return true
+	}
+	return httpGetOK(ctx, httpClient, registryURL)
+}
+
+func httpGetOK(ctx context.Context, httpClient *http.Client, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
@@ -0,0 +1,123 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// devfileSchemaVersionDoc is the subset of a devfile.yaml this library needs in order to enforce
+// RegistryOptions.MaxSchemaVersion: just the top-level schemaVersion field, ignoring everything
+// else in the document.
+type devfileSchemaVersionDoc struct {
+	SchemaVersion string `yaml:"schemaVersion"`
+}
+
+// ErrSchemaVersionTooNew reports that a pulled devfile's schemaVersion is newer than the
+// RegistryOptions.MaxSchemaVersion a caller requested. This library doesn't carry the devfile
+// spec's own field-compatibility rules, so it can't rewrite a document down to an older schema
+// version; it can only detect and report the incompatibility, so a caller pinned to an older
+// devfile spec doesn't silently receive a document its own tooling can't parse.
+type ErrSchemaVersionTooNew struct {
+	DevfilePath      string
+	SchemaVersion    string
+	MaxSchemaVersion string
+}
+
+func (e *ErrSchemaVersionTooNew) Error() string {
+	return fmt.Sprintf("%s has schemaVersion %s, which is newer than the requested maximum schemaVersion %s", e.DevfilePath, e.SchemaVersion, e.MaxSchemaVersion)
+}
+
+// checkMaxSchemaVersion enforces options.MaxSchemaVersion, if set, against the devfile.yaml (or
+// .devfile.yaml) pulled into destDir, returning an *ErrSchemaVersionTooNew if the devfile's
+// schemaVersion is newer than requested. It's a no-op, returning nil, when MaxSchemaVersion is
+// unset or the pulled stack doesn't carry a devfile (e.g. a partial pull via ResourceFilter).
+func checkMaxSchemaVersion(destDir string, maxSchemaVersion string) error {
+	if maxSchemaVersion == "" {
+		return nil
+	}
+
+	devfilePath := filepath.Join(destDir, "devfile.yaml")
+	if _, err := os.Stat(devfilePath); os.IsNotExist(err) {
+		devfilePath = filepath.Join(destDir, ".devfile.yaml")
+		if _, err := os.Stat(devfilePath); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	data, err := ioutil.ReadFile(devfilePath)
+	if err != nil {
+		return err
+	}
+	var doc devfileSchemaVersionDoc
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to read schemaVersion from %s: %v", devfilePath, err)
+	}
+	if doc.SchemaVersion == "" {
+		return nil
+	}
+
+	newer, err := isSchemaVersionNewer(doc.SchemaVersion, maxSchemaVersion)
+	if err != nil {
+		return err
+	}
+	if newer {
+		return &ErrSchemaVersionTooNew{DevfilePath: devfilePath, SchemaVersion: doc.SchemaVersion, MaxSchemaVersion: maxSchemaVersion}
+	}
+	return nil
+}
+
+// isSchemaVersionNewer reports whether version is a later devfile schemaVersion than max,
+// comparing dot-separated numeric components (e.g. "2.2.0" is newer than "2.0.0"). Devfile
+// schemaVersions are always plain major.minor.patch, not full semver, so pre-release and build
+// metadata suffixes don't need to be handled.
+func isSchemaVersionNewer(version, max string) (bool, error) {
+	v, err := parseSchemaVersion(version)
+	if err != nil {
+		return false, err
+	}
+	m, err := parseSchemaVersion(max)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < 3; i++ {
+		if v[i] != m[i] {
+			return v[i] > m[i], nil
+		}
+	}
+	return false, nil
+}
+
+// parseSchemaVersion parses a "major.minor.patch" devfile schemaVersion into its three numeric
+// components, padding missing trailing components with 0 (e.g. "2.2" becomes [2, 2, 0]).
+func parseSchemaVersion(version string) ([3]int, error) {
+	var out [3]int
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, fmt.Errorf("invalid schemaVersion %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return out, fmt.Errorf("invalid schemaVersion %q: %v", version, err)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSchemaVersionNewer(t *testing.T) {
+	tests := []struct {
+		version string
+		max     string
+		want    bool
+	}{
+		{"2.2.0", "2.0.0", true},
+		{"2.0.0", "2.2.0", false},
+		{"2.2.0", "2.2.0", false},
+		{"2.2", "2.2.0", false},
+		{"2.2.1", "2.2", true},
+	}
+	for _, test := range tests {
+		got, err := isSchemaVersionNewer(test.version, test.max)
+		if err != nil {
+			t.Fatalf("isSchemaVersionNewer(%q, %q) returned error: %v", test.version, test.max, err)
+		}
+		if got != test.want {
+			t.Errorf("isSchemaVersionNewer(%q, %q) = %v, want %v", test.version, test.max, got, test.want)
+		}
+	}
+}
+
+func TestCheckMaxSchemaVersion(t *testing.T) {
+	writeDevfile := func(t *testing.T, dir, schemaVersion string) {
+		t.Helper()
+		content := "schemaVersion: " + schemaVersion + "\nmetadata:\n  name: test\n"
+		if err := ioutil.WriteFile(filepath.Join(dir, "devfile.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write devfile.yaml: %v", err)
+		}
+	}
+
+	t.Run("no max set is a no-op", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "schemaversion")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		writeDevfile(t, dir, "2.2.0")
+
+		if err := checkMaxSchemaVersion(dir, ""); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("devfile within max passes", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "schemaversion")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		writeDevfile(t, dir, "2.0.0")
+
+		if err := checkMaxSchemaVersion(dir, "2.2.0"); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("devfile newer than max is rejected", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "schemaversion")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		writeDevfile(t, dir, "2.2.0")
+
+		err = checkMaxSchemaVersion(dir, "2.0.0")
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		if _, ok := err.(*ErrSchemaVersionTooNew); !ok {
+			t.Errorf("expected *ErrSchemaVersionTooNew, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("missing devfile is a no-op", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "schemaversion")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		if err := checkMaxSchemaVersion(dir, "2.0.0"); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// throttledTransport wraps an http.RoundTripper and caps how fast response bodies can be read,
+// so that pulling stack resources doesn't saturate the caller's connection.
+type throttledTransport struct {
+	base           http.RoundTripper
+	bytesPerSecond int64
+}
+
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	resp.Body = newThrottledReader(resp.Body, t.bytesPerSecond)
+	return resp, nil
+}
+
+// throttledReader is a simple token-bucket rate limiter over an io.ReadCloser: it hands out up to
+// bytesPerSecond bytes per one-second window, sleeping out the remainder of the window once the
+// bucket is drained.
+type throttledReader struct {
+	io.ReadCloser
+	bytesPerSecond int64
+	tokens         int64
+	windowStart    time.Time
+}
+
+func newThrottledReader(r io.ReadCloser, bytesPerSecond int64) *throttledReader {
+	return &throttledReader{
+		ReadCloser:     r,
+		bytesPerSecond: bytesPerSecond,
+		tokens:         bytesPerSecond,
+		windowStart:    time.Now(),
+	}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if t.tokens <= 0 {
+		if wait := time.Second - time.Since(t.windowStart); wait > 0 {
+			time.Sleep(wait)
+		}
+		t.tokens = t.bytesPerSecond
+		t.windowStart = time.Now()
+	}
+	if int64(len(p)) > t.tokens {
+		p = p[:t.tokens]
+	}
+	n, err := t.ReadCloser.Read(p)
+	t.tokens -= int64(n)
+	return n, err
+}
@@ -0,0 +1,41 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestThrottledReaderCapsThroughput(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 30)
+	reader := newThrottledReader(ioutil.NopCloser(bytes.NewReader(data)), 10)
+
+	start := time.Now()
+	got, err := io.ReadAll(reader)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Errorf("Unexpected err: %+v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Expected: %s, \nGot: %s", data, got)
+	}
+	// 30 bytes at 10 bytes/sec should take at least 2 seconds (the first 10 bytes are free from
+	// the initial full bucket, then two more one-second waits are needed for the rest).
+	if elapsed < 2*time.Second {
+		t.Errorf("Expected throttling to take at least 2s, took %v", elapsed)
+	}
+}
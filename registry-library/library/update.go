@@ -0,0 +1,103 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// localDevfileMetadata is the subset of a devfile.yaml's metadata needed to identify which stack
+// and version a project was scaffolded from.
+type localDevfileMetadata struct {
+	Metadata struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"metadata"`
+}
+
+// UpdateCheckResult reports whether a newer version of a project's stack has been published to the
+// registry it was scaffolded from.
+type UpdateCheckResult struct {
+	// Stack is the stack name read from the local devfile's metadata.
+	Stack string
+	// CurrentVersion is the stack version read from the local devfile's metadata.
+	CurrentVersion string
+	// LatestVersion is the highest of CurrentVersion and the stack versions found in the registry.
+	LatestVersion string
+	// UpdateAvailable is true if LatestVersion is newer than CurrentVersion.
+	UpdateAvailable bool
+}
+
+// CheckForUpdates reads the stack name and version out of localDevfilePath's metadata and reports
+// whether registry has published a newer version of that stack, so IDEs can surface a "stack
+// update available" notification without a user checking the registry by hand.
+func CheckForUpdates(localDevfilePath string, registry string, options RegistryOptions) (UpdateCheckResult, error) {
+	bytes, err := ioutil.ReadFile(localDevfilePath)
+	if err != nil {
+		return UpdateCheckResult{}, fmt.Errorf("failed to read %s: %v", localDevfilePath, err)
+	}
+	var devfile localDevfileMetadata
+	if err := yaml.Unmarshal(bytes, &devfile); err != nil {
+		return UpdateCheckResult{}, fmt.Errorf("failed to parse %s: %v", localDevfilePath, err)
+	}
+	if devfile.Metadata.Name == "" {
+		return UpdateCheckResult{}, fmt.Errorf("%s has no metadata.name", localDevfilePath)
+	}
+	if devfile.Metadata.Version == "" {
+		return UpdateCheckResult{}, fmt.Errorf("%s has no metadata.version", localDevfilePath)
+	}
+
+	stackEntry, err := findStackInRegistry(registry, devfile.Metadata.Name, options)
+	if err != nil {
+		return UpdateCheckResult{}, err
+	}
+
+	latest := devfile.Metadata.Version
+	for _, version := range stackEntry.Versions {
+		if compareVersions(version.Version, latest) > 0 {
+			latest = version.Version
+		}
+	}
+
+	return UpdateCheckResult{
+		Stack:           devfile.Metadata.Name,
+		CurrentVersion:  devfile.Metadata.Version,
+		LatestVersion:   latest,
+		UpdateAvailable: compareVersions(latest, devfile.Metadata.Version) > 0,
+	}, nil
+}
+
+// compareVersions compares two dotted-numeric version strings (e.g. "1.2.0"), returning a negative
+// number if a < b, 0 if they're equal, and a positive number if a > b. A missing or non-numeric
+// component is treated as 0, so an unparseable version never masks a well-formed one as the latest.
+func compareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+		if aNum != bNum {
+			return aNum - bNum
+		}
+	}
+	return 0
+}
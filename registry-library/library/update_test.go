@@ -0,0 +1,99 @@
+//
+// Copyright (c) 2022 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestCheckForUpdates(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entries := []indexSchema.Schema{
+			{
+				Name: "nodejs",
+				Versions: []indexSchema.Version{
+					{Version: "1.0.0"},
+					{Version: "2.1.0"},
+					{Version: "2.0.0"},
+				},
+			},
+		}
+		bytes, err := json.Marshal(&entries)
+		if err != nil {
+			t.Fatalf("Unexpected error while doing json marshal: %v", err)
+		}
+		w.Write(bytes)
+	}))
+	defer testServer.Close()
+
+	devfileDir, err := ioutil.TempDir("", "update-check")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(devfileDir)
+
+	devfilePath := filepath.Join(devfileDir, "devfile.yaml")
+	devfileContent := "schemaVersion: 2.0.0\nmetadata:\n  name: nodejs\n  version: 1.0.0\n"
+	if err := ioutil.WriteFile(devfilePath, []byte(devfileContent), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := CheckForUpdates(devfilePath, testServer.URL, RegistryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Stack != "nodejs" {
+		t.Errorf("Expected stack nodejs, got %s", result.Stack)
+	}
+	if result.CurrentVersion != "1.0.0" {
+		t.Errorf("Expected current version 1.0.0, got %s", result.CurrentVersion)
+	}
+	if result.LatestVersion != "2.1.0" {
+		t.Errorf("Expected latest version 2.1.0, got %s", result.LatestVersion)
+	}
+	if !result.UpdateAvailable {
+		t.Errorf("Expected an update to be available")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"2.1.0", "2.0.9", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.0", 1},
+	}
+
+	for _, test := range tests {
+		got := compareVersions(test.a, test.b)
+		switch {
+		case test.want == 0 && got != 0:
+			t.Errorf("compareVersions(%q, %q) = %d, want 0", test.a, test.b, got)
+		case test.want < 0 && got >= 0:
+			t.Errorf("compareVersions(%q, %q) = %d, want negative", test.a, test.b, got)
+		case test.want > 0 && got <= 0:
+			t.Errorf("compareVersions(%q, %q) = %d, want positive", test.a, test.b, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// VerifyOptions configures VerifyRegistry.
+type VerifyOptions struct {
+	// SampleSize caps how many stacks are pulled and checked, so verifying a large registry
+	// doesn't have to pull every stack's content. A value of 0 (the default) checks every stack.
+	SampleSize int
+}
+
+// StackVerifyResult reports the outcome of verifying a single stack.
+type StackVerifyResult struct {
+	Name  string
+	Error string
+}
+
+// VerifyReport summarizes the health of a registry as observed by VerifyRegistry.
+type VerifyReport struct {
+	StacksChecked int
+	Healthy       []string
+	Unhealthy     []StackVerifyResult
+}
+
+// VerifyRegistry fetches registryURL's index, then pulls a sample of its stacks (or all of them,
+// if opts.SampleSize is 0) and checks that each one's devfile.yaml parses and, when the index
+// records a digest for the stack's default version, that the registry still serves that same
+// digest. It's meant as a post-deployment smoke test: a healthy VerifyReport means the registry is
+// reachable and serving content consistent with its own index, not that every stack is
+// semantically correct.
+func VerifyRegistry(registryURL string, opts VerifyOptions, options RegistryOptions) (VerifyReport, error) {
+	var report VerifyReport
+
+	index, err := GetRegistryIndex(registryURL, options, indexSchema.StackDevfileType)
+	if err != nil {
+		return report, fmt.Errorf("failed to fetch index from %s: %v", registryURL, err)
+	}
+
+	stacks := index
+	if opts.SampleSize > 0 && opts.SampleSize < len(stacks) {
+		stacks = stacks[:opts.SampleSize]
+	}
+
+	for _, entry := range stacks {
+		report.StacksChecked++
+		if err := verifyStack(registryURL, entry, options); err != nil {
+			report.Unhealthy = append(report.Unhealthy, StackVerifyResult{Name: entry.Name, Error: err.Error()})
+			continue
+		}
+		report.Healthy = append(report.Healthy, entry.Name)
+	}
+
+	return report, nil
+}
+
+// verifyStack pulls a single stack into a scratch directory and checks its devfile.yaml parses
+// and, if the index recorded a digest for its default version, that the digest still matches.
+func verifyStack(registry string, entry indexSchema.Schema, options RegistryOptions) error {
+	destDir, err := ioutil.TempDir("", "verify-registry")
+	if err != nil {
+		return fmt.Errorf("failed to create a scratch directory: %v", err)
+	}
+	defer os.RemoveAll(destDir)
+
+	if err := PullStackFromRegistry(registry, entry.Name, destDir, options); err != nil {
+		return fmt.Errorf("failed to pull: %v", err)
+	}
+
+	devfileBytes, err := ioutil.ReadFile(filepath.Join(destDir, "devfile.yaml"))
+	if err != nil {
+		return fmt.Errorf("devfile.yaml missing after pull: %v", err)
+	}
+	var devfile indexSchema.Devfile
+	if err := yaml.Unmarshal(devfileBytes, &devfile); err != nil {
+		return fmt.Errorf("devfile.yaml is not parseable: %v", err)
+	}
+
+	for _, version := range entry.Versions {
+		if !version.Default || version.Digest == "" {
+			continue
+		}
+		digest, err := ResolveStackDigest(registry, entry.Name, options)
+		if err != nil {
+			return fmt.Errorf("failed to resolve digest: %v", err)
+		}
+		if digest != version.Digest {
+			return fmt.Errorf("digest mismatch: index has %s, registry serves %s", version.Digest, digest)
+		}
+	}
+
+	return nil
+}
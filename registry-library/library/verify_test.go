@@ -0,0 +1,85 @@
+//
+// Copyright (c) 2020 Red Hat, Inc.
+// This program and the accompanying materials are made
+// available under the terms of the Eclipse Public License 2.0
+// which is available at https://www.eclipse.org/legal/epl-2.0/
+//
+// SPDX-License-Identifier: EPL-2.0
+//
+// Contributors:
+//   Red Hat, Inc. - initial API and implementation
+
+package library
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	indexSchema "github.com/devfile/registry-support/index/generator/schema"
+)
+
+func TestVerifyRegistryReportsUnpullableStacksAsUnhealthy(t *testing.T) {
+	index := []indexSchema.Schema{
+		{Name: "stack1", Type: indexSchema.StackDevfileType},
+		{Name: "stack2", Type: indexSchema.StackDevfileType},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, err := json.MarshalIndent(&index, "", "  ")
+		if err != nil {
+			t.Errorf("Unexpected error while doing json marshal: %v", err)
+			return
+		}
+		if _, err := w.Write(bytes); err != nil {
+			t.Errorf("Unexpected error while writing data: %v", err)
+		}
+	}))
+	defer testServer.Close()
+
+	report, err := VerifyRegistry(testServer.URL, VerifyOptions{}, RegistryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.StacksChecked != 2 {
+		t.Errorf("Expected 2 stacks checked, got %d", report.StacksChecked)
+	}
+	if len(report.Healthy) != 0 || len(report.Unhealthy) != 2 {
+		t.Errorf("Expected both stacks to be reported unhealthy (no real OCI backend), got healthy=%v unhealthy=%v", report.Healthy, report.Unhealthy)
+	}
+}
+
+func TestVerifyRegistrySampleSizeLimitsStacksChecked(t *testing.T) {
+	index := []indexSchema.Schema{
+		{Name: "stack1", Type: indexSchema.StackDevfileType},
+		{Name: "stack2", Type: indexSchema.StackDevfileType},
+		{Name: "stack3", Type: indexSchema.StackDevfileType},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, err := json.MarshalIndent(&index, "", "  ")
+		if err != nil {
+			t.Errorf("Unexpected error while doing json marshal: %v", err)
+			return
+		}
+		if _, err := w.Write(bytes); err != nil {
+			t.Errorf("Unexpected error while writing data: %v", err)
+		}
+	}))
+	defer testServer.Close()
+
+	report, err := VerifyRegistry(testServer.URL, VerifyOptions{SampleSize: 1}, RegistryOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.StacksChecked != 1 {
+		t.Errorf("Expected SampleSize to limit checked stacks to 1, got %d", report.StacksChecked)
+	}
+}
+
+func TestVerifyRegistryFailsOnUnreachableIndex(t *testing.T) {
+	if _, err := VerifyRegistry("http://127.0.0.1:0", VerifyOptions{}, RegistryOptions{}); err == nil {
+		t.Error("Expected an error when the registry index can't be fetched")
+	}
+}
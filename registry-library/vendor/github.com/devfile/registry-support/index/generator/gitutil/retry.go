@@ -0,0 +1,96 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// RetryOptions bounds how CloneRevisionWithRetry retries a transient clone failure. A zero-value
+// RetryOptions disables retrying: CloneRevisionWithRetry makes a single CloneRevision attempt, and
+// a failure is still returned wrapped in a *RetriesExhaustedError with Attempts set to 1.
+type RetryOptions struct {
+	// MaxAttempts is the total number of clone attempts, including the first. Zero or one means
+	// no retrying.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt. Doubles after each subsequent
+	// failure, capped at MaxBackoff. Zero defaults to 1 second when MaxAttempts > 1.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Zero defaults to 30 seconds when
+	// MaxAttempts > 1.
+	MaxBackoff time.Duration
+}
+
+// RetriesExhaustedError reports that CloneRevisionWithRetry gave up after Attempts tries, so a
+// caller can distinguish "the remote is unreachable/broken" from an ordinary clone error and
+// report the attempt count instead of only the last failure.
+type RetriesExhaustedError struct {
+	RemoteURL string
+	Attempts  int
+	Err       error
+}
+
+func (e *RetriesExhaustedError) Error() string {
+	return fmt.Sprintf("failed to clone %s after %d attempts: %v", e.RemoteURL, e.Attempts, e.Err)
+}
+
+func (e *RetriesExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// CloneRevisionWithRetry behaves like CloneRevision, but retries a failed attempt up to
+// retry.MaxAttempts times, backing off exponentially (with jitter, to avoid every retrying
+// client hammering the remote at the same instant) between attempts. ctx bounds the whole
+// sequence of attempts, not just a single one; if ctx is done, retrying stops immediately and
+// returns ctx.Err(). Once retries are exhausted, the returned error is a *RetriesExhaustedError
+// wrapping the last attempt's error.
+func CloneRevisionWithRetry(ctx context.Context, remoteURL string, revision string, destDir string, auth GitAuth, retry RetryOptions) error {
+	maxAttempts := retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	initialBackoff := retry.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	maxBackoff := retry.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	backoff := initialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			// A failed attempt may have left a partial clone behind, which git clone refuses to
+			// clone into.
+			os.RemoveAll(destDir)
+		}
+		lastErr = CloneRevision(ctx, remoteURL, revision, destDir, auth)
+		if lastErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt == maxAttempts {
+			break
+		}
+
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2+1)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return &RetriesExhaustedError{RemoteURL: remoteURL, Attempts: maxAttempts, Err: lastErr}
+}